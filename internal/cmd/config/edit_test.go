@@ -80,7 +80,7 @@ func TestEditCmd(t *testing.T) {
 		// This goroutine interacts with vi.
 		go func() {
 			// Delete "local" repository
-			pty.Write([]byte("4jdd"))
+			pty.Write([]byte("5jdd"))
 			// ESC - enter normal mode
 			pty.Write([]byte{27})
 			// Write and quit - Enter
@@ -16,6 +16,11 @@ func NewProjectCmd(f *cmdutil.Factory) *cobra.Command {
 	}
 
 	cmd.AddCommand(project.NewCreateCmd(f))
+	cmd.AddCommand(project.NewCreateBatchCmd(f))
+	cmd.AddCommand(project.NewUpdateCmd(f))
+	cmd.AddCommand(project.NewPlanCmd(f))
+	cmd.AddCommand(project.NewApplyCmd(f))
+	cmd.AddCommand(project.NewPublishCmd(f))
 
 	return cmd
 }
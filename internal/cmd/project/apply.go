@@ -0,0 +1,117 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/martinohmann/kickoff/internal/cmdutil"
+	"github.com/martinohmann/kickoff/internal/project"
+	"github.com/spf13/cobra"
+)
+
+// NewApplyCmd creates a command that applies a plan that was created via
+// the plan command.
+func NewApplyCmd(f *cmdutil.Factory) *cobra.Command {
+	o := &ApplyOptions{
+		CreateOptions: CreateOptions{
+			IOStreams: f.IOStreams,
+			Config:    f.Config,
+			GitClient: f.GitClient,
+			Prompt:    f.Prompt,
+			InitGit:   true,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "apply <plan-file>",
+		Short: "Apply a project plan",
+		Long: cmdutil.LongDesc(`
+			Apply a project plan that was created via 'kickoff project plan'.
+
+			Before applying the plan, kickoff verifies that the files in the project
+			directory did not change since the plan was created. The plan is rejected
+			otherwise and needs to be recreated. Plans that would write files outside
+			of the project directory are rejected as well.`),
+		Example: cmdutil.Examples(`
+			# Apply a plan
+			kickoff project apply plan.json
+
+			# Apply a plan without confirmation
+			kickoff project apply plan.json --yes
+
+			# Apply a plan and create an initial commit on branch main
+			kickoff project apply plan.json --git-commit "Initial commit" --git-branch main`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.PlanFile = args[0]
+
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.AutoApprove, "yes", o.AutoApprove, "Auto-approve all prompts")
+	cmd.Flags().BoolVar(&o.InitGit, "init-git", o.InitGit, "Initialize git in the project directory")
+	cmd.Flags().StringVar(&o.GitCommit, "git-commit", o.GitCommit,
+		"Commit all project files with this message after git was initialized. The author is read from the git config")
+	cmd.Flags().StringVar(&o.GitRemote, "git-remote", o.GitRemote,
+		"Name of a remote, e.g. origin, that is added pointing to the project URL after git was initialized")
+	cmd.Flags().StringVar(&o.GitBranch, "git-branch", o.GitBranch, "Name of the initial branch of the git repository, e.g. main")
+	cmd.Flags().BoolVar(&o.NoHooks, "no-hooks", o.NoHooks, "Do not run the hooks contained in the plan")
+
+	return cmd
+}
+
+// ApplyOptions holds the options for the apply command.
+type ApplyOptions struct {
+	CreateOptions
+
+	PlanFile string
+}
+
+// Run loads the plan, verifies that it is up to date and applies it.
+func (o *ApplyOptions) Run() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	config, err := o.Config()
+	if err != nil {
+		return err
+	}
+
+	o.completeGitDefaults(config)
+
+	plan, err := project.LoadPlan(o.PlanFile)
+	if err != nil {
+		return err
+	}
+
+	if err := plan.Verify(); err != nil {
+		return err
+	}
+
+	if o.NoHooks {
+		plan.Hooks = nil
+	}
+
+	o.ProjectDir = plan.ProjectDir()
+	o.ProjectName = filepath.Base(o.ProjectDir)
+	o.projectURL = plan.URL()
+
+	if plan.RepoRoot() != "" {
+		// The project is part of a monorepo that is already under version
+		// control, do not create a nested git repository.
+		o.repoRoot = plan.RepoRoot()
+		o.InitGit = false
+	}
+
+	if o.InitGit && o.GitRemote != "" && o.projectURL == "" {
+		return fmt.Errorf("cannot add git remote %s: plan does not contain the project URL", o.GitRemote)
+	}
+
+	printPlan(o.Out, plan)
+
+	return o.applyPlan(ctx, plan)
+}
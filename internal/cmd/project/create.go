@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
 	"github.com/martinohmann/kickoff/internal/cli"
 	"github.com/martinohmann/kickoff/internal/cmdutil"
+	"github.com/martinohmann/kickoff/internal/editor"
 	"github.com/martinohmann/kickoff/internal/git"
 	"github.com/martinohmann/kickoff/internal/gitignore"
 	"github.com/martinohmann/kickoff/internal/homedir"
@@ -20,8 +23,10 @@ import (
 	"github.com/martinohmann/kickoff/internal/prompt"
 	"github.com/martinohmann/kickoff/internal/repository"
 	"github.com/martinohmann/kickoff/internal/template"
+	"github.com/martinohmann/kickoff/internal/version"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // NewCreateCmd creates a command that can create projects from project
@@ -34,6 +39,7 @@ func NewCreateCmd(f *cmdutil.Factory) *cobra.Command {
 		HTTPClient: f.HTTPClient,
 		Repository: f.Repository,
 		Prompt:     f.Prompt,
+		Editor:     editor.New(f.IOStreams),
 		InitGit:    true,
 	}
 
@@ -41,7 +47,12 @@ func NewCreateCmd(f *cmdutil.Factory) *cobra.Command {
 		Use:   "create <name> <skeleton-name> [<skeleton-name>...]",
 		Short: "Create a project from one or more skeletons",
 		Long: cmdutil.LongDesc(`
-			Create a project from one or more skeletons.`),
+			Create a project from one or more skeletons.
+
+			A ` + kickoff.LockFileName + ` file is written into the project directory which records
+			the exact skeleton commits, values, license and gitignore templates the
+			project was created from. Pass it via --from-lock to create the same
+			project again.`),
 		Example: cmdutil.Examples(`
 			# Create project
 			kickoff project create myproject myskeleton
@@ -59,7 +70,28 @@ func NewCreateCmd(f *cmdutil.Factory) *cobra.Command {
 			kickoff project create myproject myskeleton --set some.val=theval,mykey=mynewvalue --values values.yaml
 
 			# Selectively skip creation of certain files or dirs
-			kickoff project create myproject myskeleton --skip-file README.md --skip-file some/dir`),
+			kickoff project create myproject myskeleton --skip-file README.md --skip-file some/dir
+
+			# Review the changes to existing files before overwriting them
+			kickoff project create myproject myskeleton --overwrite --diff
+
+			# Only print the changes to existing files without writing anything
+			kickoff project create myproject myskeleton --overwrite --diff-only
+
+			# Create project with an initial commit on branch main and remote origin
+			kickoff project create myproject myskeleton --git-commit "Initial commit" --git-branch main --git-remote origin
+
+			# Create a sub-project in a directory of an existing git repository
+			kickoff project create myservice myskeleton --dir services/myservice --monorepo
+
+			# Create project without running the hooks of the skeleton
+			kickoff project create myproject myskeleton --no-hooks
+
+			# Create project from the options declared in a project spec file
+			kickoff project create -f project.yaml
+
+			# Recreate a project from the lock file of another project
+			kickoff project create --from-lock /path/to/other/project/.kickoff.lock --dir /path/to/project`),
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			if len(args) == 0 {
 				return nil, cobra.ShellCompDirectiveNoFileComp
@@ -106,6 +138,7 @@ type CreateOptions struct {
 	HTTPClient func() *http.Client
 	Repository func(...string) (kickoff.Repository, error)
 	Prompt     prompt.Prompt
+	Editor     *editor.Editor
 
 	ProjectName  string
 	ProjectDir   string
@@ -114,27 +147,67 @@ type CreateOptions struct {
 	License      string
 	Gitignore    string
 	Values       template.Values
+	LockFile     string
+	SpecFile     string
 
 	RepoNames      []string
 	SkeletonNames  []string
 	AutoApprove    bool
+	Diff           bool
+	DiffOnly       bool
 	Interactive    bool
 	InitGit        bool
+	GitCommit      string
+	GitRemote      string
+	GitBranch      string
+	NoHooks        bool
 	Overwrite      bool
 	OverwriteFiles []string
 	SkipFiles      []string
+	Resolve        bool
+	Monorepo       bool
 
 	rawValues   []string
 	valuesFiles []string
 	gitignores  []string
+	repoRoot    string
+	repoName    string
+	lock        *kickoff.Lock
+	spec        *kickoff.ProjectSpec
+	skeletons   []*kickoff.Skeleton
+	skeleton    *kickoff.Skeleton
+	projectURL  string
+	flags       *pflag.FlagSet
 }
 
 // AddFlags adds flags for all project creation options to cmd.
 func (o *CreateOptions) AddFlags(cmd *cobra.Command) {
+	o.AddConfigFlags(cmd)
+
 	cmd.Flags().BoolVar(&o.AutoApprove, "yes", o.AutoApprove, "Auto-approve all prompts")
-	cmd.Flags().BoolVarP(&o.Interactive, "interactive", "i", o.Interactive, "Configure project via interactive prompts")
 	cmd.Flags().BoolVar(&o.InitGit, "init-git", o.InitGit, "Initialize git in the project directory")
+	cmd.Flags().StringVar(&o.GitCommit, "git-commit", o.GitCommit,
+		"Commit all project files with this message after git was initialized. The author is read from the git config")
+	cmd.Flags().StringVar(&o.GitRemote, "git-remote", o.GitRemote,
+		"Name of a remote, e.g. origin, that is added pointing to the project URL after git was initialized")
+	cmd.Flags().StringVar(&o.GitBranch, "git-branch", o.GitBranch, "Name of the initial branch of the git repository, e.g. main")
+	cmd.Flags().BoolVar(&o.Diff, "diff", o.Diff, "Show the diff between existing files and their new content before overwriting them")
+	cmd.Flags().BoolVar(&o.DiffOnly, "diff-only", o.DiffOnly, "Only show the diff between existing files and their new content, do not write any files")
+	cmd.Flags().BoolVar(&o.Resolve, "resolve", o.Resolve,
+		"Interactively decide for every file that is already present in the output directory whether to keep or overwrite it")
+}
+
+// AddConfigFlags adds flags for all options that affect the project
+// configuration to cmd.
+func (o *CreateOptions) AddConfigFlags(cmd *cobra.Command) {
+	o.flags = cmd.Flags()
+
+	cmd.Flags().BoolVarP(&o.Interactive, "interactive", "i", o.Interactive, "Configure project via interactive prompts")
 	cmd.Flags().BoolVar(&o.Overwrite, "overwrite", o.Overwrite, "Overwrite files that are already present in output directory")
+	cmd.Flags().BoolVar(&o.NoHooks, "no-hooks", o.NoHooks, "Do not run the preCreate and postCreate hooks of the skeletons")
+	cmd.Flags().BoolVar(&o.Monorepo, "monorepo", o.Monorepo,
+		"Create the project as a sub-project of the git repository that contains the project directory. "+
+			"Skips git initialization and the LICENSE file and adds gitignore entries to the .gitignore of the repository root")
 
 	cmd.Flags().StringArrayVar(&o.OverwriteFiles, "overwrite-file", o.OverwriteFiles,
 		"Overwrite a specific file in the output directory, if present. File path must be relative to the output directory. "+
@@ -155,93 +228,218 @@ func (o *CreateOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.ProjectDir, "dir", "d", o.ProjectDir, "Custom project directory. If empty the project is created in $PWD/<project-name>")
 	cmd.Flags().StringVar(&o.ProjectHost, "host", o.ProjectHost, "Project repository host")
 	cmd.Flags().StringVar(&o.ProjectOwner, "owner", o.ProjectOwner, "Project repository owner. This should be the name of the SCM user, e.g. the GitHub user or organization name")
+	cmd.Flags().StringVar(&o.LockFile, "from-lock", o.LockFile,
+		"Create the project from the skeleton commits, values and settings recorded in a "+kickoff.LockFileName+" file. "+
+			"Cannot be combined with project name, skeleton names and flags that alter the project configuration")
+	cmd.Flags().StringVarP(&o.SpecFile, "file", "f", o.SpecFile,
+		"Create the project from the options declared in a project spec file. Project name, skeleton names and flags take precedence over the spec")
 }
 
 // Complete completes the project creation options.
 func (o *CreateOptions) Complete() (err error) {
+	if o.LockFile != "" {
+		return o.completeFromLock()
+	}
+
 	config, err := o.Config()
 	if err != nil {
 		return err
 	}
 
+	if o.SpecFile != "" {
+		if err := o.completeFromSpec(config); err != nil {
+			return err
+		}
+	}
+
 	return o.complete(config)
 }
 
 // Run loads all project skeletons that the user provided and creates the
 // project at the output directory.
 func (o *CreateOptions) Run() error {
-	repo, err := o.Repository(o.RepoNames...)
+	// Cancelling the context on interrupt makes sure that a partially
+	// created project is rolled back.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	config, err := o.makeConfig(ctx)
 	if err != nil {
 		return err
 	}
 
-	skeletons, err := repository.LoadSkeletons(repo, o.SkeletonNames)
-	if err != nil {
+	o.projectURL = config.URL()
+
+	if err := o.printConfig(config); err != nil {
 		return err
 	}
 
-	skeleton, err := kickoff.MergeSkeletons(skeletons...)
+	plan, err := project.MakePlan(config)
 	if err != nil {
 		return err
 	}
 
-	return o.createProject(context.Background(), skeleton)
+	if o.Resolve {
+		if err := o.resolveExisting(plan); err != nil {
+			return err
+		}
+	}
+
+	printPlan(o.Out, plan)
+
+	if o.Diff || o.DiffOnly {
+		if err := printDiffs(o.Out, plan); err != nil {
+			return err
+		}
+
+		if o.DiffOnly {
+			return nil
+		}
+	}
+
+	if plan.SkipsExisting() && !o.Resolve {
+		fmt.Fprintf(o.Out, "%s Some files will be skipped because they already exist, "+
+			"pass %s, %s or %s to overwrite\n\n", color.YellowString("!"), bold.Sprint("--overwrite"),
+			bold.Sprint("--overwrite-file"), bold.Sprint("--resolve"))
+	}
+
+	return o.applyPlan(ctx, plan)
 }
 
-func (o *CreateOptions) createProject(ctx context.Context, skeleton *kickoff.Skeleton) error {
+// makeConfig loads the skeletons and builds the project configuration from
+// the completed options.
+func (o *CreateOptions) makeConfig(ctx context.Context) (*project.Config, error) {
+	var (
+		skeleton *kickoff.Skeleton
+		lock     *kickoff.Lock
+		err      error
+	)
+
+	if o.lock != nil {
+		config, err := o.Config()
+		if err != nil {
+			return nil, err
+		}
+
+		skeleton, err = loadLockedSkeletons(ctx, o.lock, true, cmdutil.RepositoryOptions(config))
+		if err != nil {
+			return nil, err
+		}
+
+		// The lock is recorded as is, including the kickoff version that
+		// originally created the project, to reproduce it byte-for-byte.
+		lock = o.lock
+	} else {
+		// The skeletons were usually loaded already while completing the
+		// parameters and values.
+		skeletons, err := o.loadSkeletons()
+		if err != nil {
+			return nil, err
+		}
+
+		skeleton, err = o.loadSkeleton()
+		if err != nil {
+			return nil, err
+		}
+
+		lock, err = o.makeLock(skeletons, skeleton)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	config := &project.Config{
 		Name:           o.ProjectName,
 		Host:           o.ProjectHost,
 		Owner:          o.ProjectOwner,
 		ProjectDir:     o.ProjectDir,
+		RepoRoot:       o.repoRoot,
+		RepoName:       o.repoName,
 		Overwrite:      o.Overwrite,
 		OverwriteFiles: o.OverwriteFiles,
 		SkipFiles:      o.SkipFiles,
+		NoHooks:        o.NoHooks,
 		Skeleton:       skeleton,
 		Values:         o.Values,
+		Year:           lockYear(lock),
+		Lock:           lock,
 	}
 
-	if o.License != "" {
-		client := license.NewClient(o.HTTPClient())
-
-		license, err := client.GetLicense(ctx, o.License)
-		if err != nil {
-			return err
-		}
-
-		config.License = license
+	config.License, err = fetchLicense(ctx, o.HTTPClient(), o.License)
+	if err != nil {
+		return nil, err
 	}
 
-	if o.Gitignore != "" {
-		client := gitignore.NewClient(o.HTTPClient())
+	config.Gitignore, err = fetchGitignore(ctx, o.HTTPClient(), o.Gitignore)
+	if err != nil {
+		return nil, err
+	}
 
-		template, err := client.GetTemplate(ctx, o.Gitignore)
-		if err != nil {
-			return err
-		}
+	return config, nil
+}
 
-		config.Gitignore = template
+// makeLock creates the lock for the project from the skeletons, the skeleton
+// they were merged into and the completed options.
+func (o *CreateOptions) makeLock(skeletons []*kickoff.Skeleton, merged *kickoff.Skeleton) (*kickoff.Lock, error) {
+	// The lock records the values that are used for rendering, so that
+	// changes to the default values of the skeletons do not alter the
+	// rendered project when it is created from the lock or updated.
+	values, err := template.MergeValues(merged.Values, o.Values)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := o.printConfig(config); err != nil {
-		return err
+	lock := &kickoff.Lock{
+		KickoffVersion: version.Get().GitVersion,
+		CreatedAt:      time.Now().UTC().Truncate(time.Second),
+		Project: kickoff.ProjectLock{
+			Name:      o.ProjectName,
+			Host:      o.ProjectHost,
+			Owner:     o.ProjectOwner,
+			License:   o.License,
+			Gitignore: o.Gitignore,
+			Monorepo:  o.Monorepo,
+		},
+		Skeletons: make([]*kickoff.SkeletonLock, len(skeletons)),
+		Values:    values,
 	}
 
-	plan, err := project.MakePlan(config)
-	if err != nil {
-		return err
-	}
+	for i, skeleton := range skeletons {
+		repoRef := *skeleton.Ref.Repo
+
+		commit, err := repository.ResolveCommit(repoRef)
+		if err != nil {
+			return nil, err
+		}
 
-	o.printPlan(plan)
+		if repoRef.IsLocal() {
+			// Relative paths are not meaningful outside of the current
+			// working directory.
+			repoRef.Path = repoRef.LocalPath()
+		}
 
-	if plan.SkipsExisting() {
-		fmt.Fprintf(o.Out, "%s Some files will be skipped because they already exist, "+
-			"pass %s or %s to overwrite\n\n", color.YellowString("!"), bold.Sprint("--overwrite"), bold.Sprint("--overwrite-file"))
+		lock.Skeletons[i] = &kickoff.SkeletonLock{
+			Name:   skeleton.Ref.Name,
+			Repo:   repoRef,
+			Commit: commit,
+		}
 	}
 
+	return lock, nil
+}
+
+// applyPlan asks for confirmation unless auto-approve is enabled and applies
+// the plan. Hooks are tied to writing the project files, so they are not run
+// if the plan does not write any files.
+func (o *CreateOptions) applyPlan(ctx context.Context, plan *project.Plan) error {
 	if plan.IsNoOp() {
 		fmt.Fprintf(o.Out, "%s No files to write to %s\n",
 			color.YellowString("!"), bold.Sprint(homedir.Collapse(o.ProjectDir)))
+
+		if len(plan.Hooks) > 0 {
+			fmt.Fprintf(o.Out, "%s Skipping %d hook(s) as no files are written\n", color.YellowString("!"), len(plan.Hooks))
+		}
+
 		return nil
 	}
 
@@ -255,7 +453,21 @@ func (o *CreateOptions) createProject(ctx context.Context, skeleton *kickoff.Ske
 		fmt.Fprintln(o.Out)
 	}
 
-	if err := plan.Apply(); err != nil {
+	// The effects of preCreate hooks are not rolled back if applying the plan
+	// fails. At least remove the project directory again if it did not exist
+	// before, as it was created by RunHooks or Apply.
+	_, err := os.Stat(o.ProjectDir)
+	newDir := errors.Is(err, os.ErrNotExist)
+
+	if err := o.createProjectFiles(ctx, plan); err != nil {
+		if newDir {
+			o.removeProjectDir()
+		}
+
+		return err
+	}
+
+	if err := plan.RunHooks(ctx, kickoff.HookPostCreate, o.Out); err != nil {
 		return err
 	}
 
@@ -268,6 +480,54 @@ func (o *CreateOptions) createProject(ctx context.Context, skeleton *kickoff.Ske
 	return o.initGitRepository(o.ProjectDir)
 }
 
+// createProjectFiles runs the preCreate hooks and applies the plan.
+func (o *CreateOptions) createProjectFiles(ctx context.Context, plan *project.Plan) error {
+	if err := plan.RunHooks(ctx, kickoff.HookPreCreate, o.Out); err != nil {
+		return err
+	}
+
+	return plan.Apply(ctx)
+}
+
+// removeProjectDir removes the project directory including everything that
+// preCreate hooks may have put into it.
+func (o *CreateOptions) removeProjectDir() {
+	log.WithField("path", o.ProjectDir).Debug("removing project directory")
+
+	if err := os.RemoveAll(o.ProjectDir); err != nil {
+		log.WithError(err).WithField("path", o.ProjectDir).Warn("failed to remove project directory")
+	}
+}
+
+// lockYear returns the year of the project creation recorded in lock, or zero
+// if it is unknown.
+func lockYear(lock *kickoff.Lock) int {
+	if lock.CreatedAt.IsZero() {
+		return 0
+	}
+
+	return lock.CreatedAt.Year()
+}
+
+// fetchLicense fetches the license with key. Returns nil if key is empty.
+func fetchLicense(ctx context.Context, httpClient *http.Client, key string) (*license.Info, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	return license.NewClient(httpClient).GetLicense(ctx, key)
+}
+
+// fetchGitignore fetches the gitignore template for query. Returns nil if
+// query is empty.
+func fetchGitignore(ctx context.Context, httpClient *http.Client, query string) (*gitignore.Template, error) {
+	if query == "" {
+		return nil, nil
+	}
+
+	return gitignore.NewClient(httpClient).GetTemplate(ctx, query)
+}
+
 func (o *CreateOptions) confirmApply(apply *bool) error {
 	if _, err := os.Stat(o.ProjectDir); err == nil {
 		return o.Prompt.AskOne(&survey.Confirm{
@@ -287,10 +547,36 @@ func (o *CreateOptions) initGitRepository(path string) error {
 
 	client := o.GitClient()
 
-	_, err := client.Init(path)
+	repo, err := client.Init(path)
 	if errors.Is(err, git.ErrRepositoryAlreadyExists) {
 		return nil
 	}
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	if o.GitBranch != "" {
+		if err := repo.SetHead(o.GitBranch); err != nil {
+			return fmt.Errorf("failed to set initial branch: %w", err)
+		}
+	}
+
+	if o.GitCommit != "" {
+		if err := repo.AddAll(); err != nil {
+			return fmt.Errorf("failed to stage project files: %w", err)
+		}
+
+		if _, err := repo.Commit(o.GitCommit); err != nil {
+			return fmt.Errorf("failed to create initial commit: %w", err)
+		}
+	}
+
+	if o.GitRemote != "" {
+		if err := repo.CreateRemote(o.GitRemote, o.projectURL); err != nil {
+			return fmt.Errorf("failed to add remote %s: %w", o.GitRemote, err)
+		}
+	}
+
+	return nil
 }
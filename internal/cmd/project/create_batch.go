@@ -0,0 +1,301 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/fatih/color"
+	"github.com/martinohmann/kickoff/internal/cli"
+	"github.com/martinohmann/kickoff/internal/cmdutil"
+	"github.com/martinohmann/kickoff/internal/git"
+	"github.com/martinohmann/kickoff/internal/homedir"
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/project"
+	"github.com/martinohmann/kickoff/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+// NewCreateBatchCmd creates a command that creates multiple projects that
+// are declared in a manifest file.
+func NewCreateBatchCmd(f *cmdutil.Factory) *cobra.Command {
+	o := &CreateBatchOptions{
+		IOStreams:  f.IOStreams,
+		Config:     f.Config,
+		GitClient:  f.GitClient,
+		HTTPClient: f.HTTPClient,
+		Repository: f.Repository,
+		Prompt:     f.Prompt,
+		Workers:    4,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "create-batch <manifest>",
+		Short: "Create multiple projects declared in a manifest",
+		Long: cmdutil.LongDesc(`
+			Create multiple projects declared in a manifest.
+
+			The manifest contains a list of project specs in the format accepted by
+			'kickoff project create --file'. Every skeleton is loaded only once and the
+			plans for all projects are made before any project is created. After
+			confirmation the projects are created in parallel. A project that fails to
+			be created is rolled back without affecting the others.`),
+		Example: cmdutil.Examples(`
+			# Create all projects from a manifest
+			kickoff project create-batch manifest.yaml
+
+			# Create all projects without confirmation using 8 workers
+			kickoff project create-batch manifest.yaml --yes --workers 8`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.ManifestFile = args[0]
+
+			if o.Workers < 1 {
+				return errors.New("--workers must be at least 1")
+			}
+
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.AutoApprove, "yes", o.AutoApprove, "Auto-approve all prompts")
+	cmd.Flags().BoolVar(&o.NoHooks, "no-hooks", o.NoHooks, "Do not run the preCreate and postCreate hooks of the skeletons")
+	cmd.Flags().IntVar(&o.Workers, "workers", o.Workers, "Maximum number of projects that are created in parallel")
+
+	return cmd
+}
+
+// CreateBatchOptions holds the options for the create-batch command.
+type CreateBatchOptions struct {
+	cli.IOStreams
+
+	Config     func() (*kickoff.Config, error)
+	GitClient  func() git.Client
+	HTTPClient func() *http.Client
+	Repository func(...string) (kickoff.Repository, error)
+	Prompt     prompt.Prompt
+
+	ManifestFile string
+	AutoApprove  bool
+	NoHooks      bool
+	Workers      int
+}
+
+// batchProject is a single project of a batch.
+type batchProject struct {
+	*CreateOptions
+
+	plan *project.Plan
+	out  bytes.Buffer
+	err  error
+}
+
+// Run loads the manifest, makes the plans for all projects and creates them
+// in parallel.
+func (o *CreateBatchOptions) Run() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	manifest, err := kickoff.LoadProjectManifest(o.ManifestFile)
+	if err != nil {
+		return err
+	}
+
+	projects, err := o.makeProjects(ctx, manifest)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range projects {
+		bold.Fprintf(o.Out, "Project %s in %s\n\n", p.ProjectName, homedir.Collapse(p.ProjectDir))
+		printPlan(o.Out, p.plan)
+	}
+
+	if !o.AutoApprove {
+		var apply bool
+
+		err := o.Prompt.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Create %d projects?", len(projects)),
+			Default: true,
+		}, &apply)
+		if err != nil || !apply {
+			return err
+		}
+
+		fmt.Fprintln(o.Out)
+	}
+
+	o.applyProjects(ctx, projects)
+
+	return o.printSummary(projects)
+}
+
+// makeProjects completes the options of every project in manifest and makes
+// their plans. Skeletons are loaded only once for all projects.
+func (o *CreateBatchOptions) makeProjects(ctx context.Context, manifest *kickoff.ProjectManifest) ([]*batchProject, error) {
+	config, err := o.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := addRepositories(config, manifest.Repositories); err != nil {
+		return nil, err
+	}
+
+	for _, spec := range manifest.Projects {
+		if err := addRepositories(config, spec.Repositories); err != nil {
+			return nil, err
+		}
+	}
+
+	repo, err := o.Repository()
+	if err != nil {
+		return nil, err
+	}
+
+	repo = newSkeletonCache(repo)
+
+	projects := make([]*batchProject, len(manifest.Projects))
+	projectDirs := make(map[string]string, len(manifest.Projects))
+
+	for i, spec := range manifest.Projects {
+		p := &batchProject{}
+		p.CreateOptions = &CreateOptions{
+			IOStreams:  cli.IOStreams{In: o.In, Out: &p.out, ErrOut: o.ErrOut},
+			Config:     o.Config,
+			GitClient:  o.GitClient,
+			HTTPClient: o.HTTPClient,
+			Repository: func(...string) (kickoff.Repository, error) { return repo, nil },
+			Prompt:     o.Prompt,
+			InitGit:    true,
+			NoHooks:    o.NoHooks,
+		}
+
+		if err := p.applySpec(config, spec); err != nil {
+			return nil, fmt.Errorf("project %s: %w", spec.Name, err)
+		}
+
+		if err := p.complete(config); err != nil {
+			return nil, fmt.Errorf("project %s: %w", spec.Name, err)
+		}
+
+		if other, ok := projectDirs[p.ProjectDir]; ok {
+			return nil, fmt.Errorf("projects %s and %s must not be created in the same directory %s",
+				other, spec.Name, homedir.Collapse(p.ProjectDir))
+		}
+
+		projectDirs[p.ProjectDir] = spec.Name
+
+		projectConfig, err := p.makeConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", spec.Name, err)
+		}
+
+		p.projectURL = projectConfig.URL()
+
+		p.plan, err = project.MakePlan(projectConfig)
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", spec.Name, err)
+		}
+
+		projects[i] = p
+	}
+
+	// Sub-projects of the same monorepo add their gitignore lines to the
+	// same .gitignore in the repository root. A single operation must write
+	// them, as the projects are created in parallel.
+	plans := make([]*project.Plan, len(projects))
+	for i, p := range projects {
+		plans[i] = p.plan
+	}
+
+	project.CombineSharedGitignores(plans...)
+
+	return projects, nil
+}
+
+// applyProjects applies the plans of all projects using a bounded number of
+// workers. Errors are recorded per project.
+func (o *CreateBatchOptions) applyProjects(ctx context.Context, projects []*batchProject) {
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, o.Workers)
+
+	for _, p := range projects {
+		wg.Add(1)
+
+		go func(p *batchProject) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// Confirmation was already given for the whole batch.
+			p.AutoApprove = true
+			p.err = p.applyPlan(ctx, p.plan)
+		}(p)
+	}
+
+	wg.Wait()
+}
+
+// printSummary prints the output and result of every project. Returns an
+// error if any project failed.
+func (o *CreateBatchOptions) printSummary(projects []*batchProject) error {
+	var failed int
+
+	for _, p := range projects {
+		o.Out.Write(p.out.Bytes())
+
+		if p.err != nil {
+			failed++
+			fmt.Fprintf(o.Out, "%s Project %s failed: %v\n", color.RedString("✗"), bold.Sprint(p.ProjectName), p.err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d projects failed", failed, len(projects))
+	}
+
+	return nil
+}
+
+// skeletonCache is a kickoff.Repository that loads every skeleton only once.
+type skeletonCache struct {
+	kickoff.Repository
+
+	mu        sync.Mutex
+	skeletons map[string]*kickoff.Skeleton
+}
+
+func newSkeletonCache(repo kickoff.Repository) *skeletonCache {
+	return &skeletonCache{
+		Repository: repo,
+		skeletons:  make(map[string]*kickoff.Skeleton),
+	}
+}
+
+// LoadSkeleton implements kickoff.Repository.
+func (c *skeletonCache) LoadSkeleton(name string) (*kickoff.Skeleton, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if skeleton, ok := c.skeletons[name]; ok {
+		return skeleton, nil
+	}
+
+	skeleton, err := c.Repository.LoadSkeleton(name)
+	if err != nil {
+		return nil, err
+	}
+
+	c.skeletons[name] = skeleton
+
+	return skeleton, nil
+}
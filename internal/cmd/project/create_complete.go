@@ -2,6 +2,7 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,11 +12,11 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/ghodss/yaml"
 	"github.com/martinohmann/kickoff/internal/cmdutil"
+	"github.com/martinohmann/kickoff/internal/git"
 	"github.com/martinohmann/kickoff/internal/gitignore"
 	"github.com/martinohmann/kickoff/internal/homedir"
 	"github.com/martinohmann/kickoff/internal/kickoff"
 	"github.com/martinohmann/kickoff/internal/license"
-	"github.com/martinohmann/kickoff/internal/repository"
 	"github.com/martinohmann/kickoff/internal/template"
 	"helm.sh/helm/pkg/strvals"
 )
@@ -25,12 +26,15 @@ func (o *CreateOptions) complete(config *kickoff.Config) error {
 		o.completeSkeletonNames,
 		o.completeProjectName,
 		o.completeProjectDir,
+		o.completeMonorepo,
 		o.completeProjectHost,
 		o.completeProjectOwner,
 		o.completeLicense,
 		o.completeGitignoreTemplates,
 		o.completeGitInit,
 		o.completeValues,
+		o.completeParameters,
+		o.editValues,
 	}
 
 	for _, complete := range completeFuncs {
@@ -42,6 +46,155 @@ func (o *CreateOptions) complete(config *kickoff.Config) error {
 	return nil
 }
 
+// completeFromLock completes the options from the lock file. Options that
+// would alter the project configuration recorded in the lock are rejected.
+func (o *CreateOptions) completeFromLock() (err error) {
+	switch {
+	case o.ProjectName != "" || len(o.SkeletonNames) > 0:
+		return errors.New("project name and skeleton names must not be provided together with --from-lock")
+	case o.SpecFile != "":
+		return errors.New("--from-lock cannot be combined with --file")
+	case o.Interactive || o.ProjectHost != "" || o.ProjectOwner != "" || o.License != "" ||
+		len(o.gitignores) > 0 || len(o.rawValues) > 0 || len(o.valuesFiles) > 0 || len(o.RepoNames) > 0:
+		return errors.New("--from-lock cannot be combined with flags that alter the project configuration")
+	}
+
+	o.lock, err = kickoff.LoadLock(o.LockFile)
+	if err != nil {
+		return err
+	}
+
+	o.ProjectName = o.lock.Project.Name
+	o.ProjectHost = o.lock.Project.Host
+	o.ProjectOwner = o.lock.Project.Owner
+	o.License = o.lock.Project.License
+	o.Gitignore = o.lock.Project.Gitignore
+	o.Values = o.lock.Values
+	o.Monorepo = o.lock.Project.Monorepo
+
+	o.SkeletonNames = make([]string, len(o.lock.Skeletons))
+	for i, sl := range o.lock.Skeletons {
+		o.SkeletonNames[i] = sl.String()
+	}
+
+	if err := o.completeProjectDir(nil); err != nil {
+		return err
+	}
+
+	return o.completeMonorepo(nil)
+}
+
+// completeFromSpec completes the options from the project spec file.
+func (o *CreateOptions) completeFromSpec(config *kickoff.Config) error {
+	spec, err := kickoff.LoadProjectSpec(o.SpecFile)
+	if err != nil {
+		return err
+	}
+
+	// The project name and skeleton names passed as arguments take
+	// precedence, so the spec is only validated after applying them.
+	if o.ProjectName != "" {
+		spec.Name = o.ProjectName
+	}
+
+	if len(o.SkeletonNames) > 0 {
+		spec.Skeletons = o.SkeletonNames
+	}
+
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+
+	return o.applySpec(config, spec)
+}
+
+// applySpec completes the options from spec. Fields of the spec are only used
+// if the corresponding flag was not set explicitly. Repositories declared in
+// the spec are added to config.
+func (o *CreateOptions) applySpec(config *kickoff.Config, spec *kickoff.ProjectSpec) error {
+	if err := addRepositories(config, spec.Repositories); err != nil {
+		return err
+	}
+
+	o.spec = spec
+
+	if o.ProjectName == "" {
+		o.ProjectName = spec.Name
+	}
+
+	if len(o.SkeletonNames) == 0 {
+		o.SkeletonNames = spec.Skeletons
+	}
+
+	o.specString(&o.ProjectDir, "dir", spec.Dir)
+	o.specString(&o.ProjectHost, "host", spec.Host)
+	o.specString(&o.ProjectOwner, "owner", spec.Owner)
+	o.specString(&o.License, "license", spec.License)
+	o.specString(&o.GitCommit, "git-commit", spec.Git.CommitMessage)
+	o.specString(&o.GitRemote, "git-remote", spec.Git.Remote)
+	o.specString(&o.GitBranch, "git-branch", spec.Git.Branch)
+
+	if !o.changed("gitignore") && spec.Gitignore != "" {
+		o.gitignores = strings.Split(spec.Gitignore, ",")
+	}
+
+	if !o.changed("overwrite") {
+		o.Overwrite = spec.Overwrite
+	}
+
+	if !o.changed("overwrite-file") {
+		o.OverwriteFiles = spec.OverwriteFiles
+	}
+
+	if !o.changed("skip-file") {
+		o.SkipFiles = spec.SkipFiles
+	}
+
+	if !o.changed("monorepo") {
+		o.Monorepo = spec.Monorepo
+	}
+
+	if !o.changed("init-git") && spec.Git.Init != nil {
+		o.InitGit = *spec.Git.Init
+	}
+
+	return nil
+}
+
+// addRepositories adds repos to the repositories of config. Returns an error
+// if a repository with the same name but a different URL is configured
+// already.
+func addRepositories(config *kickoff.Config, repos map[string]string) error {
+	for name, url := range repos {
+		if configured, ok := config.Repositories[name]; ok && configured != url {
+			return fmt.Errorf("repository %q from project spec is already configured with URL %s", name, configured)
+		}
+
+		config.Repositories[name] = url
+	}
+
+	return nil
+}
+
+// specString sets *p to value if the flag with name was not set explicitly
+// and value is not empty.
+func (o *CreateOptions) specString(p *string, name, value string) {
+	if !o.changed(name) && value != "" {
+		*p = value
+	}
+}
+
+// changed returns true if the flag with name was set explicitly.
+func (o *CreateOptions) changed(name string) bool {
+	if o.flags == nil {
+		return false
+	}
+
+	flag := o.flags.Lookup(name)
+
+	return flag != nil && flag.Changed
+}
+
 func (o *CreateOptions) completeSkeletonNames(config *kickoff.Config) error {
 	if len(o.SkeletonNames) > 0 && !o.Interactive {
 		return nil
@@ -143,6 +296,36 @@ func isDirOrNonexistent(path string) error {
 	return nil
 }
 
+// completeMonorepo looks up the root of the git repository that contains the
+// project directory if the project is created in monorepo mode. Git is not
+// initialized for sub-projects.
+func (o *CreateOptions) completeMonorepo(config *kickoff.Config) (err error) {
+	if !o.Monorepo {
+		return nil
+	}
+
+	o.repoRoot, err = git.FindRepoRoot(o.ProjectDir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return fmt.Errorf("--monorepo requires %s to be inside of a git repository", homedir.Collapse(o.ProjectDir))
+	} else if err != nil {
+		return err
+	}
+
+	if o.repoRoot == o.ProjectDir {
+		return fmt.Errorf("--monorepo requires the project directory to be a subdirectory of the repository root %s",
+			homedir.Collapse(o.repoRoot))
+	}
+
+	o.repoName, err = git.RemoteRepoName(o.repoRoot, "origin")
+	if err != nil {
+		return fmt.Errorf("failed to read origin remote of %s: %w", homedir.Collapse(o.repoRoot), err)
+	}
+
+	o.InitGit = false
+
+	return nil
+}
+
 func (o *CreateOptions) completeProjectHost(config *kickoff.Config) error {
 	if o.ProjectHost == "" {
 		o.ProjectHost = config.Project.Host
@@ -279,7 +462,16 @@ func (o *CreateOptions) completeGitignoreTemplates(config *kickoff.Config) error
 }
 
 func (o *CreateOptions) completeGitInit(config *kickoff.Config) error {
-	if o.InitGit && !o.Interactive {
+	o.completeGitDefaults(config)
+
+	// Sub-projects are part of an existing repository already.
+	if o.repoRoot != "" {
+		return nil
+	}
+
+	// Project specs are meant for non-interactive creation, so their choice
+	// is not questioned.
+	if (o.InitGit || o.spec != nil) && !o.Interactive {
 		return nil
 	}
 
@@ -289,8 +481,37 @@ func (o *CreateOptions) completeGitInit(config *kickoff.Config) error {
 	}, &o.InitGit)
 }
 
+// completeGitDefaults fills the git settings that were not provided via
+// flags with the defaults from config.
+func (o *CreateOptions) completeGitDefaults(config *kickoff.Config) {
+	if o.GitCommit == "" {
+		o.GitCommit = config.Project.Git.CommitMessage
+	}
+
+	if o.GitRemote == "" {
+		o.GitRemote = config.Project.Git.Remote
+	}
+
+	if o.GitBranch == "" {
+		o.GitBranch = config.Project.Git.Branch
+	}
+}
+
 func (o *CreateOptions) completeValues(config *kickoff.Config) error {
-	o.Values = config.Values
+	// Copy the config values as they are shared with other projects created
+	// in the same batch.
+	var err error
+
+	o.Values, err = template.MergeValues(config.Values)
+	if err != nil {
+		return err
+	}
+
+	if o.spec != nil && o.spec.Values != nil {
+		if err := o.Values.Merge(o.spec.Values); err != nil {
+			return err
+		}
+	}
 
 	for _, path := range o.valuesFiles {
 		vals, err := template.LoadValues(path)
@@ -309,6 +530,12 @@ func (o *CreateOptions) completeValues(config *kickoff.Config) error {
 		}
 	}
 
+	return nil
+}
+
+// editValues offers to edit the merged skeleton and user values in an editor
+// in interactive mode.
+func (o *CreateOptions) editValues(config *kickoff.Config) error {
 	if !o.Interactive {
 		return nil
 	}
@@ -327,22 +554,12 @@ func (o *CreateOptions) completeValues(config *kickoff.Config) error {
 		return err
 	}
 
-	repo, err := o.Repository(o.RepoNames...)
-	if err != nil {
-		return err
-	}
-
-	skeletons, err := repository.LoadSkeletons(repo, o.SkeletonNames)
-	if err != nil {
-		return err
-	}
-
-	merged, err := kickoff.MergeSkeletons(skeletons...)
+	skeleton, err := o.loadSkeleton()
 	if err != nil {
 		return err
 	}
 
-	o.Values, err = template.MergeValues(merged.Values, o.Values)
+	o.Values, err = template.MergeValues(skeleton.Values, o.Values)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,214 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/repository"
+	"github.com/martinohmann/kickoff/internal/template"
+)
+
+// completeParameters prompts for the values of the skeleton parameters in
+// interactive mode. Otherwise it ensures that all parameters without default
+// were provided via the project spec, --values or --set. The values are added
+// to o.Values so that they are recorded in the lock file.
+func (o *CreateOptions) completeParameters(config *kickoff.Config) error {
+	skeleton, err := o.loadSkeleton()
+	if err != nil || len(skeleton.Parameters) == 0 {
+		return err
+	}
+
+	values, err := template.MergeValues(skeleton.Values, o.Values)
+	if err != nil {
+		return err
+	}
+
+	// Defaults and conditions have access to the answers of all previous
+	// parameters via values.
+	data := template.Values{
+		"Project": map[string]string{
+			"Name":  o.ProjectName,
+			"Host":  o.ProjectHost,
+			"Owner": o.ProjectOwner,
+		},
+		"Values": values,
+	}
+
+	for _, param := range skeleton.Parameters {
+		value, ok, err := o.completeParameter(param, values, data)
+		if err != nil {
+			return fmt.Errorf("parameter %q of skeleton %s: %w", param.Name, param.SkeletonRef, err)
+		}
+
+		if ok {
+			values.Set(param.Name, value)
+			o.Values.Set(param.Name, value)
+		}
+	}
+
+	return nil
+}
+
+// completeParameter returns the value for param. The second return value is
+// false if the parameter is skipped because its when condition evaluated to
+// false.
+func (o *CreateOptions) completeParameter(param *kickoff.Parameter, values, data template.Values) (interface{}, bool, error) {
+	if param.When != "" {
+		ok, err := template.EvaluateCondition(param.When, data)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+	}
+
+	value, ok, err := parameterDefault(param, o.Values, values, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if o.Interactive {
+		value, err = o.promptParameter(param, value)
+		if err != nil {
+			return nil, false, err
+		}
+	} else if !ok {
+		return nil, false, errors.New("value is required, provide it via --set or --values")
+	}
+
+	value, err = param.ConvertValue(value)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid value: %w", err)
+	}
+
+	return value, true, nil
+}
+
+// parameterDefault returns the value of param that is used if the user does
+// not provide one. Values provided by the user take precedence over the
+// parameter default, which takes precedence over the skeleton values. The
+// second return value is false if there is no value at all.
+func parameterDefault(param *kickoff.Parameter, userValues, values, data template.Values) (interface{}, bool, error) {
+	if value, ok := userValues.Lookup(param.Name); ok {
+		return value, true, nil
+	}
+
+	if param.HasTemplatedDefault() {
+		value, err := template.Render(param.Default.(string), data)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to render default: %w", err)
+		}
+
+		return value, true, nil
+	}
+
+	if param.Default != nil {
+		return param.Default, true, nil
+	}
+
+	value, ok := values.Lookup(param.Name)
+
+	return value, ok, nil
+}
+
+// promptParameter prompts for the value of param using the prompt matching
+// its type. The answer is returned unconverted.
+func (o *CreateOptions) promptParameter(param *kickoff.Parameter, value interface{}) (interface{}, error) {
+	message := param.Description
+	if message == "" {
+		message = param.Name
+	}
+
+	validate := survey.WithValidator(func(ans interface{}) error {
+		_, err := param.ConvertValue(ans)
+		return err
+	})
+
+	switch param.ValueType() {
+	case kickoff.ParameterBool:
+		def, _ := param.ConvertValue(value)
+
+		answer, _ := def.(bool)
+
+		err := o.Prompt.AskOne(&survey.Confirm{Message: message, Default: answer}, &answer)
+
+		return answer, err
+	case kickoff.ParameterEnum:
+		var answer string
+
+		prompt := &survey.Select{Message: message, Options: param.Options, VimMode: true}
+		if def, err := param.ConvertValue(value); err == nil {
+			prompt.Default = def
+		}
+
+		err := o.Prompt.AskOne(prompt, &answer)
+
+		return answer, err
+	default:
+		var answer string
+
+		err := o.Prompt.AskOne(&survey.Input{Message: message, Default: formatParameterValue(value)}, &answer, validate)
+
+		return answer, err
+	}
+}
+
+// formatParameterValue formats value as the default of an input prompt. List
+// items are separated by commas.
+func formatParameterValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+
+		return strings.Join(items, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// loadSkeletons loads the skeletons for the project. They are only loaded
+// once, so that parameters, values and the project config are based on the
+// same skeletons.
+func (o *CreateOptions) loadSkeletons() ([]*kickoff.Skeleton, error) {
+	if o.skeletons != nil {
+		return o.skeletons, nil
+	}
+
+	repo, err := o.Repository(o.RepoNames...)
+	if err != nil {
+		return nil, err
+	}
+
+	o.skeletons, err = repository.LoadSkeletons(repo, o.SkeletonNames)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.skeletons, nil
+}
+
+// loadSkeleton loads the skeletons for the project and returns the merged
+// result. The skeleton is only loaded once.
+func (o *CreateOptions) loadSkeleton() (*kickoff.Skeleton, error) {
+	if o.skeleton != nil {
+		return o.skeleton, nil
+	}
+
+	skeletons, err := o.loadSkeletons()
+	if err != nil {
+		return nil, err
+	}
+
+	o.skeleton, err = kickoff.MergeSkeletons(skeletons...)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.skeleton, nil
+}
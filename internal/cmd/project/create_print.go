@@ -1,14 +1,19 @@
 package project
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"os"
 	"regexp"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/ghodss/yaml"
 	"github.com/martinohmann/kickoff/internal/cli"
+	"github.com/martinohmann/kickoff/internal/diff"
 	"github.com/martinohmann/kickoff/internal/homedir"
+	"github.com/martinohmann/kickoff/internal/kickoff"
 	"github.com/martinohmann/kickoff/internal/project"
 )
 
@@ -67,10 +72,10 @@ func (o *CreateOptions) printConfig(config *project.Config) error {
 	return nil
 }
 
-func (o *CreateOptions) printPlan(plan *project.Plan) {
-	bold.Fprint(o.Out, "The following file operations will be performed:\n\n")
+func printPlan(w io.Writer, plan *project.Plan) {
+	bold.Fprint(w, "The following file operations will be performed:\n\n")
 
-	tw := cli.NewTableWriter(o.Out)
+	tw := cli.NewTableWriter(w)
 	tw.SetTablePadding(" ")
 
 	for _, op := range plan.Operations {
@@ -86,13 +91,25 @@ func (o *CreateOptions) printPlan(plan *project.Plan) {
 			dirSuffix = "/"
 		}
 
+		if source.IsSymlink() && op.Content != nil {
+			dirSuffix = color.HiBlackString(" -> %s", op.Content)
+		}
+
 		switch op.Type {
 		case project.OpSkipUser:
 			status = color.YellowString("! skip ") + color.HiBlackString("(user)")
 		case project.OpSkipExisting:
 			status = color.YellowString("! skip ") + color.HiBlackString("(exists)")
+		case project.OpSkipSkeleton:
+			status = color.YellowString("! skip ") + color.HiBlackString("(skeleton: %s)", op.Reason)
 		case project.OpOverwrite:
 			status = color.RedString("✓ overwrite")
+		case project.OpMerge:
+			if op.Conflicts > 0 {
+				status = color.RedString("✗ merge ") + color.HiBlackString("(%d conflicts)", op.Conflicts)
+			} else {
+				status = color.BlueString("✓ merge")
+			}
 		default:
 			status = color.GreenString("✓ create")
 		}
@@ -102,6 +119,10 @@ func (o *CreateOptions) printPlan(plan *project.Plan) {
 			origin = ref.String()
 		}
 
+		if len(source.MergedFrom) > 0 {
+			origin = mergedOrigin(source.MergedFrom)
+		}
+
 		tw.Append(
 			color.CyanString(origin),
 			color.HiBlackString("❯"),
@@ -113,7 +134,143 @@ func (o *CreateOptions) printPlan(plan *project.Plan) {
 	}
 
 	tw.Render()
-	fmt.Fprintln(o.Out)
+	fmt.Fprintln(w)
+
+	if len(plan.Hooks) == 0 {
+		return
+	}
+
+	bold.Fprint(w, "The following hooks will be run in the project directory:\n\n")
+
+	tw = cli.NewTableWriter(w)
+	tw.SetTablePadding(" ")
+
+	for _, hook := range plan.Hooks {
+		origin := "<unknown>"
+		if ref := hook.SkeletonRef; ref != nil {
+			origin = ref.String()
+		}
+
+		tw.Append(
+			color.CyanString(origin),
+			color.HiBlackString("❯"),
+			color.MagentaString(string(hook.Stage)),
+			color.HiBlackString("$"),
+			hook.Command,
+		)
+	}
+
+	tw.Render()
+	fmt.Fprintln(w)
+}
+
+// printDiffs prints unified diffs between the existing files and their new
+// content for all overwrite operations of plan.
+func printDiffs(w io.Writer, plan *project.Plan) error {
+	var changed bool
+
+	for _, op := range plan.Operations {
+		if op.Type != project.OpOverwrite || op.Source.Mode.IsDir() {
+			continue
+		}
+
+		content, err := op.ReadContent()
+		if err != nil {
+			return err
+		}
+
+		opChanged, err := printDiff(w, op, content)
+		if err != nil {
+			return err
+		}
+
+		changed = changed || opChanged
+	}
+
+	if !changed {
+		fmt.Fprintf(w, "%s No changes to existing files\n\n", color.GreenString("✓"))
+	}
+
+	return nil
+}
+
+// printDiff prints the diff between the existing destination of op and
+// content. Returns true if there are any changes.
+func printDiff(w io.Writer, op *project.Operation, content []byte) (bool, error) {
+	path := op.Dest.RelPath()
+
+	if op.Source.IsSymlink() {
+		current, err := os.Readlink(op.Dest.AbsPath())
+		if err != nil {
+			current = "<not a symlink>"
+		}
+
+		if current == string(content) {
+			return false, nil
+		}
+
+		fmt.Fprintf(w, "%s\n\n", bold.Sprintf("Symlink %s changes from %s to %s", path, current, content))
+		return true, nil
+	}
+
+	current, err := os.ReadFile(op.Dest.AbsPath())
+	if err != nil {
+		return false, err
+	}
+
+	if op.Source.Binary || isBinary(current) || isBinary(content) {
+		if bytes.Equal(current, content) {
+			return false, nil
+		}
+
+		fmt.Fprintf(w, "%s\n\n", bold.Sprintf("Binary files a/%s and b/%s differ", path, path))
+		return true, nil
+	}
+
+	unified := diff.Unified(string(current), string(content), "a/"+path, "b/"+path, diff.DefaultContext)
+	if unified == "" {
+		return false, nil
+	}
+
+	printUnifiedDiff(w, unified)
+
+	return true, nil
+}
+
+// mergedOrigin formats the refs of all skeletons that contributed to a merged
+// file.
+func mergedOrigin(refs []*kickoff.SkeletonRef) string {
+	names := make([]string, len(refs))
+	for i, ref := range refs {
+		names[i] = ref.String()
+	}
+
+	return strings.Join(names, "+")
+}
+
+func printUnifiedDiff(w io.Writer, unified string) {
+	for _, line := range strings.SplitAfter(unified, "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			bold.Fprint(w, line)
+		case strings.HasPrefix(line, "@@"):
+			fmt.Fprint(w, color.CyanString(line))
+		case strings.HasPrefix(line, "+"):
+			fmt.Fprint(w, color.GreenString(line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Fprint(w, color.RedString(line))
+		default:
+			fmt.Fprint(w, line)
+		}
+	}
+
+	fmt.Fprintln(w)
+}
+
+// isBinary returns true if content contains a NUL byte. This is the same
+// heuristic git uses to detect binary files.
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content, 0) != -1
 }
 
 func (o *CreateOptions) printSummary(plan *project.Plan) {
@@ -122,7 +279,7 @@ func (o *CreateOptions) printSummary(plan *project.Plan) {
 	fmt.Fprintf(o.Out, "%s Project %s created in %s. %s files created, %s skipped and %s overwritten\n",
 		color.GreenString("✓"), bold.Sprint(o.ProjectName), bold.Sprint(homedir.Collapse(o.ProjectDir)),
 		color.GreenString("%d", counts[project.OpCreate]),
-		color.YellowString("%d", counts[project.OpSkipUser]+counts[project.OpSkipExisting]),
+		color.YellowString("%d", counts[project.OpSkipUser]+counts[project.OpSkipExisting]+counts[project.OpSkipSkeleton]),
 		color.RedString("%d", counts[project.OpOverwrite]),
 	)
 }
@@ -0,0 +1,179 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/fatih/color"
+	"github.com/martinohmann/kickoff/internal/diff"
+	"github.com/martinohmann/kickoff/internal/project"
+)
+
+// Choices for resolving existing files.
+const (
+	resolveKeep         = "keep"
+	resolveOverwrite    = "overwrite"
+	resolveDiff         = "show diff"
+	resolveEdit         = "merge in editor"
+	resolveKeepAll      = "keep all remaining"
+	resolveOverwriteAll = "overwrite all remaining"
+)
+
+// Choices for edited files that still contain conflicts.
+const (
+	resolveEditAgain = "edit again"
+	resolveAccept    = "accept anyway"
+	resolveSkip      = "skip"
+)
+
+// resolveExisting asks the user for every file in the plan that is already
+// present in the project directory whether it should be kept or overwritten.
+func (o *CreateOptions) resolveExisting(plan *project.Plan) error {
+	var all string
+
+	for _, op := range plan.Existing() {
+		choice := all
+
+		if choice == "" {
+			var err error
+
+			choice, err = o.resolveOne(plan, op)
+			if err != nil {
+				return err
+			}
+
+			switch choice {
+			case resolveKeepAll:
+				all, choice = resolveKeep, resolveKeep
+			case resolveOverwriteAll:
+				all, choice = resolveOverwrite, resolveOverwrite
+			}
+		}
+
+		switch choice {
+		case resolveKeep:
+			plan.Keep(op)
+		case resolveOverwrite:
+			if err := plan.Overwrite(op, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveOne prompts for the resolution of op until the user keeps or
+// overwrites the file. Merging by hand overwrites the file with the edited
+// content, which is reported as resolveEdit. Skipping an edit prompts for
+// the resolution again.
+func (o *CreateOptions) resolveOne(plan *project.Plan, op *project.Operation) (string, error) {
+	path := op.Dest.RelPath()
+
+	content, err := plan.NewContent(op)
+	if err != nil {
+		return "", err
+	}
+
+	options := []string{resolveKeep, resolveOverwrite, resolveDiff}
+	if !op.Source.IsSymlink() && !op.Source.Binary {
+		options = append(options, resolveEdit)
+	}
+
+	options = append(options, resolveKeepAll, resolveOverwriteAll)
+
+	defaultChoice := resolveKeep
+	if op.Type == project.OpOverwrite {
+		defaultChoice = resolveOverwrite
+	}
+
+	for {
+		var choice string
+
+		err := o.Prompt.AskOne(&survey.Select{
+			Message: fmt.Sprintf("%s already exists", path),
+			Options: options,
+			Default: defaultChoice,
+		}, &choice)
+		if err != nil {
+			return "", err
+		}
+
+		switch choice {
+		case resolveDiff:
+			changed, err := printDiff(o.Out, op, content)
+			if err != nil {
+				return "", err
+			}
+
+			if !changed {
+				fmt.Fprintf(o.Out, "%s No changes to %s\n\n", color.GreenString("✓"), path)
+			}
+		case resolveEdit:
+			merged, ok, err := o.mergeInEditor(op, content)
+			if err != nil {
+				return "", err
+			}
+
+			if !ok {
+				continue
+			}
+
+			return resolveEdit, plan.Overwrite(op, merged)
+		default:
+			return choice, nil
+		}
+	}
+}
+
+// mergeInEditor opens the current content of the destination of op and
+// content in the editor. Both are combined into a single file where
+// differing lines are surrounded by conflict markers. If any of these
+// conflicts is left unchanged, the user can edit the file again, accept it
+// anyway or skip it. The second return value is false if the edit was
+// skipped.
+func (o *CreateOptions) mergeInEditor(op *project.Operation, content []byte) ([]byte, bool, error) {
+	path := op.Dest.RelPath()
+
+	current, err := os.ReadFile(op.Dest.AbsPath())
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := diff.TwoWay(string(current), string(content), diff.Labels{Ours: "current", Theirs: "skeleton"})
+	merged := []byte(result.Text)
+
+	for {
+		merged, err = o.Editor.Edit(merged, "*-"+filepath.Base(path))
+		if err != nil {
+			return nil, false, err
+		}
+
+		unresolved := result.Unresolved(string(merged))
+		if unresolved == 0 {
+			return merged, true, nil
+		}
+
+		fmt.Fprintf(o.Out, "%s %s still contains %d unresolved conflict(s)\n\n", color.YellowString("!"), path, unresolved)
+
+		var choice string
+
+		err := o.Prompt.AskOne(&survey.Select{
+			Message: fmt.Sprintf("How do you want to continue with %s?", path),
+			Options: []string{resolveEditAgain, resolveAccept, resolveSkip},
+			Default: resolveEditAgain,
+		}, &choice)
+		if err != nil {
+			return nil, false, err
+		}
+
+		switch choice {
+		case resolveAccept:
+			return merged, true, nil
+		case resolveSkip:
+			return nil, false, nil
+		}
+	}
+}
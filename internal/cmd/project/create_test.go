@@ -1,15 +1,22 @@
 package project
 
 import (
+	"errors"
 	"io"
+	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"testing"
 
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/jarcoal/httpmock"
 	"github.com/martinohmann/kickoff/internal/cli"
 	"github.com/martinohmann/kickoff/internal/cmdutil"
+	"github.com/martinohmann/kickoff/internal/git"
+	"github.com/martinohmann/kickoff/internal/kickoff"
 	"github.com/martinohmann/kickoff/internal/prompt"
 	"github.com/martinohmann/kickoff/internal/testutil"
 	"github.com/stretchr/testify/assert"
@@ -23,7 +30,7 @@ func TestCreate(t *testing.T) {
 		WithProjectOwner("hansdampf").
 		Create()
 
-	streams, _, _, _ := cli.NewTestIOStreams()
+	streams, _, out, _ := cli.NewTestIOStreams()
 
 	f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
 	f.HTTPClient = func() *http.Client { return http.DefaultClient }
@@ -148,6 +155,41 @@ func TestCreate(t *testing.T) {
 		fakePrompt.AssertExpectations(t)
 	})
 
+	t.Run("--diff-only prints diffs of overwritten files without writing them", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "myproject")
+
+		stubber, fakePrompt := stubPrompt(f)
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{
+			"myproject", "default:advanced", "-d", dir,
+			"--owner", "johndoe", "--license", "mit",
+		})
+		cmd.SetOut(io.Discard)
+
+		// confirm apply
+		stubber.StubOne(true)
+
+		require.NoError(t, cmd.Execute())
+
+		out.Reset()
+
+		cmd = NewCreateCmd(f)
+		cmd.SetArgs([]string{
+			"myproject", "default:advanced", "-d", dir,
+			"--owner", "johndoe", "--license", "unlicense", "--overwrite", "--diff-only",
+		})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, out.String(), "--- a/LICENSE\n+++ b/LICENSE\n")
+		assert.Contains(t, out.String(), "-the-mit-license\n")
+		assert.Contains(t, out.String(), "+the-unlicense\n")
+		assertFileContains(t, filepath.Join(dir, "LICENSE"), "the-mit-license")
+
+		fakePrompt.AssertExpectations(t)
+	})
+
 	t.Run("interactive mode prompts for every config option", func(t *testing.T) {
 		dir := filepath.Join(t.TempDir(), "myproject")
 
@@ -199,14 +241,846 @@ func TestCreate(t *testing.T) {
 	})
 }
 
+func TestCreateFromLock(t *testing.T) {
+	defer testutil.MockRepositoryCacheDir(t.TempDir())()
+
+	repoDir, repo := testutil.InitGitRepo(t)
+
+	testutil.CommitFile(t, repo, repoDir, "skeletons/default/.kickoff.yaml", "values:\n  greeting: hello\n")
+	testutil.CommitFile(t, repo, repoDir, "skeletons/default/README.md.skel", "# {{.Project.Name}}\n\n{{.Values.greeting}} {{.Values.name}}\n")
+
+	configPath := testutil.NewConfigFileBuilder(t).
+		WithRepository("default", repoDir).
+		WithProjectOwner("johndoe").
+		Create()
+
+	streams, _, _, _ := cli.NewTestIOStreams()
+
+	f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
+
+	_, fakePrompt := stubPrompt(f)
+	defer fakePrompt.AssertExpectations(t)
+
+	dir := filepath.Join(t.TempDir(), "myproject")
+
+	cmd := NewCreateCmd(f)
+	cmd.SetArgs([]string{"myproject", "default", "-d", dir, "--set", "name=world", "--yes"})
+	cmd.SetOut(io.Discard)
+
+	require.NoError(t, cmd.Execute())
+
+	lockPath := filepath.Join(dir, kickoff.LockFileName)
+
+	// Pretend that the project was created by another kickoff version. The
+	// recorded version must be carried over into the recreated lock.
+	content, err := os.ReadFile(lockPath)
+	require.NoError(t, err)
+	content = regexp.MustCompile(`(?m)^kickoffVersion: .*$`).ReplaceAll(content, []byte("kickoffVersion: v0.1.0"))
+	require.NoError(t, os.WriteFile(lockPath, content, 0644))
+
+	// Later skeleton changes must not affect projects created from the lock.
+	testutil.CommitFile(t, repo, repoDir, "skeletons/default/README.md.skel", "changed\n")
+
+	t.Run("recreates project byte-for-byte", func(t *testing.T) {
+		otherDir := filepath.Join(t.TempDir(), "myproject")
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"--from-lock", lockPath, "-d", otherDir, "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		assert.Equal(t, readFileTree(t, dir), readFileTree(t, otherDir))
+		assertFileContains(t, filepath.Join(otherDir, "README.md"), "# myproject\n\nhello world\n")
+	})
+
+	t.Run("rejects project name and skeletons", func(t *testing.T) {
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myproject", "default", "--from-lock", lockPath, "-d", t.TempDir(), "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.Error(t, cmd.Execute())
+	})
+
+	t.Run("rejects flags that alter the project configuration", func(t *testing.T) {
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"--from-lock", lockPath, "--set", "name=foo", "-d", t.TempDir(), "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.Error(t, cmd.Execute())
+	})
+}
+
 func stubPrompt(f *cmdutil.Factory) (*prompt.Stubber, *prompt.FakePrompt) {
 	stubber, fakePrompt := prompt.NewStubber()
 	f.Prompt = fakePrompt
 	return stubber, fakePrompt
 }
 
+// readFileTree returns the contents of all files below dir keyed by their
+// path relative to dir. The .git directory is skipped.
+func readFileTree(t *testing.T, dir string) map[string]string {
+	files := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			if err == nil && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return err
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		files[relPath] = string(content)
+		return nil
+	})
+	require.NoError(t, err)
+
+	return files
+}
+
 func assertFileContains(t *testing.T, path, expectedContent string) {
 	contents, err := os.ReadFile(path)
 	require.NoError(t, err)
 	assert.Equal(t, expectedContent, string(contents))
 }
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestCreateHooks(t *testing.T) {
+	repoDir := t.TempDir()
+
+	writeFile(t, filepath.Join(repoDir, "skeletons/hooks/.kickoff.yaml"),
+		"hooks:\n  postCreate:\n  - echo {{.Project.Name}} > hook.txt\n")
+	writeFile(t, filepath.Join(repoDir, "skeletons/hooks/README.md"), "readme")
+
+	configPath := testutil.NewConfigFileBuilder(t).
+		WithRepository("default", repoDir).
+		WithProjectOwner("johndoe").
+		Create()
+
+	streams, _, out, _ := cli.NewTestIOStreams()
+
+	f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
+
+	_, fakePrompt := stubPrompt(f)
+	defer fakePrompt.AssertExpectations(t)
+
+	t.Run("hooks are shown in plan and run", func(t *testing.T) {
+		out.Reset()
+
+		dir := filepath.Join(t.TempDir(), "myproject")
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myproject", "hooks", "-d", dir, "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, out.String(), "echo myproject > hook.txt")
+		assertFileContains(t, filepath.Join(dir, "hook.txt"), "myproject\n")
+	})
+
+	t.Run("hooks are skipped if no files are written", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "myproject")
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myproject", "hooks", "-d", dir, "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+		require.NoError(t, os.Remove(filepath.Join(dir, "hook.txt")))
+
+		out.Reset()
+
+		cmd = NewCreateCmd(f)
+		cmd.SetArgs([]string{"myproject", "hooks", "-d", dir, "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, out.String(), "Skipping 1 hook(s) as no files are written")
+		require.NoFileExists(t, filepath.Join(dir, "hook.txt"))
+	})
+
+	t.Run("hooks are skipped with --no-hooks", func(t *testing.T) {
+		out.Reset()
+
+		dir := filepath.Join(t.TempDir(), "myproject")
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myproject", "hooks", "-d", dir, "--yes", "--no-hooks"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+		assert.NotContains(t, out.String(), "hook.txt")
+		require.FileExists(t, filepath.Join(dir, "README.md"))
+		require.NoFileExists(t, filepath.Join(dir, "hook.txt"))
+	})
+}
+
+func TestCreateHooks_Failure(t *testing.T) {
+	repoDir := t.TempDir()
+
+	writeFile(t, filepath.Join(repoDir, "skeletons/failing-hook/.kickoff.yaml"),
+		"hooks:\n  preCreate:\n  - touch pre.txt\n  - exit 1\n")
+	writeFile(t, filepath.Join(repoDir, "skeletons/failing-hook/README.md"), "readme")
+	writeFile(t, filepath.Join(repoDir, "skeletons/failing-apply/.kickoff.yaml"),
+		"hooks:\n  preCreate:\n  - touch docs\n")
+	writeFile(t, filepath.Join(repoDir, "skeletons/failing-apply/docs/README.md"), "readme")
+
+	configPath := testutil.NewConfigFileBuilder(t).
+		WithRepository("default", repoDir).
+		WithProjectOwner("johndoe").
+		Create()
+
+	streams, _, _, _ := cli.NewTestIOStreams()
+
+	f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
+
+	_, fakePrompt := stubPrompt(f)
+	defer fakePrompt.AssertExpectations(t)
+
+	for _, skeleton := range []string{"failing-hook", "failing-apply"} {
+		t.Run(skeleton+" removes new project directory", func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "myproject")
+
+			cmd := NewCreateCmd(f)
+			cmd.SetArgs([]string{"myproject", skeleton, "-d", dir, "--yes"})
+			cmd.SetOut(io.Discard)
+
+			require.Error(t, cmd.Execute())
+			require.NoDirExists(t, dir)
+		})
+
+		t.Run(skeleton+" keeps existing project directory", func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "myproject")
+			writeFile(t, filepath.Join(dir, "existing.txt"), "existing")
+
+			cmd := NewCreateCmd(f)
+			cmd.SetArgs([]string{"myproject", skeleton, "-d", dir, "--yes"})
+			cmd.SetOut(io.Discard)
+
+			require.Error(t, cmd.Execute())
+			assertFileContains(t, filepath.Join(dir, "existing.txt"), "existing")
+			require.NoDirExists(t, filepath.Join(dir, "docs"))
+		})
+	}
+}
+
+func TestCreateResolve(t *testing.T) {
+	repoDir := t.TempDir()
+
+	writeFile(t, filepath.Join(repoDir, "skeletons/default/.kickoff.yaml"), "")
+	writeFile(t, filepath.Join(repoDir, "skeletons/default/README.md"), "a\nb\n")
+
+	configPath := testutil.NewConfigFileBuilder(t).
+		WithRepository("default", repoDir).
+		WithProjectOwner("johndoe").
+		Create()
+
+	streams, _, out, _ := cli.NewTestIOStreams()
+
+	f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
+
+	originalEditor := os.Getenv(kickoff.EnvKeyEditor)
+	defer os.Setenv(kickoff.EnvKeyEditor, originalEditor)
+
+	conflict := "a\n<<<<<<< current\nc\n=======\nb\n>>>>>>> skeleton\n"
+
+	runResolve := func(t *testing.T, editorCommand string, choices ...string) string {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "README.md"), "a\nc\n")
+
+		os.Setenv(kickoff.EnvKeyEditor, editorCommand)
+
+		stubber, fakePrompt := stubPrompt(f)
+		defer fakePrompt.AssertExpectations(t)
+
+		for _, choice := range choices {
+			stubber.StubOne(choice)
+		}
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myproject", "default", "-d", dir, "--yes", "--resolve"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		content, err := os.ReadFile(filepath.Join(dir, "README.md"))
+		require.NoError(t, err)
+
+		return string(content)
+	}
+
+	editorWriting := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "edited")
+		writeFile(t, path, content)
+		return "cp " + path
+	}
+
+	t.Run("resolved edit is written", func(t *testing.T) {
+		content := runResolve(t, editorWriting(t, "a\nb\nc\n"), resolveEdit)
+		assert.Equal(t, "a\nb\nc\n", content)
+	})
+
+	t.Run("unresolved conflicts can be accepted", func(t *testing.T) {
+		out.Reset()
+
+		content := runResolve(t, "true", resolveEdit, resolveAccept)
+		assert.Equal(t, conflict, content)
+		assert.Contains(t, out.String(), "README.md still contains 1 unresolved conflict(s)")
+	})
+
+	t.Run("unresolved conflicts can be skipped", func(t *testing.T) {
+		content := runResolve(t, "true", resolveEdit, resolveSkip, resolveKeep)
+		assert.Equal(t, "a\nc\n", content)
+	})
+
+	t.Run("only conflicts of the merge are detected", func(t *testing.T) {
+		content := runResolve(t, editorWriting(t, "<<<<<<< ours\nb\n"), resolveEdit)
+		assert.Equal(t, "<<<<<<< ours\nb\n", content)
+	})
+}
+
+func TestCreateGit(t *testing.T) {
+	repoDir := t.TempDir()
+
+	writeFile(t, filepath.Join(repoDir, "skeletons/minimal/.kickoff.yaml"), "")
+	writeFile(t, filepath.Join(repoDir, "skeletons/minimal/README.md"), "readme")
+
+	configPath := testutil.NewConfigFileBuilder(t).
+		WithRepository("default", repoDir).
+		WithProjectOwner("johndoe").
+		WithProjectGit(kickoff.GitConfig{CommitMessage: "Initial commit", Remote: "origin"}).
+		Create()
+
+	streams, _, _, _ := cli.NewTestIOStreams()
+
+	f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
+
+	_, fakePrompt := stubPrompt(f)
+	defer fakePrompt.AssertExpectations(t)
+
+	t.Run("commits files and adds remote", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "myproject")
+
+		fakeRepo := &git.FakeRepository{}
+		fakeRepo.On("SetHead", "main").Return(nil).Once()
+		fakeRepo.On("AddAll").Return(nil).Once()
+		fakeRepo.On("Commit", "Initial commit").Return(plumbing.ZeroHash, nil).Once()
+		fakeRepo.On("CreateRemote", "origin", "https://github.com/johndoe/myproject").Return(nil).Once()
+
+		fakeClient := &git.FakeClient{}
+		fakeClient.On("Init", dir).Return(fakeRepo, nil).Once()
+
+		f.GitClient = func() git.Client { return fakeClient }
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myproject", "minimal", "-d", dir, "--yes", "--git-branch", "main"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		fakeClient.AssertExpectations(t)
+		fakeRepo.AssertExpectations(t)
+	})
+
+	t.Run("commit errors are returned", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "myproject")
+
+		fakeRepo := &git.FakeRepository{}
+		fakeRepo.On("AddAll").Return(nil).Once()
+		fakeRepo.On("Commit", "Add project").Return(nil, errors.New("author field is required")).Once()
+
+		fakeClient := &git.FakeClient{}
+		fakeClient.On("Init", dir).Return(fakeRepo, nil).Once()
+
+		f.GitClient = func() git.Client { return fakeClient }
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myproject", "minimal", "-d", dir, "--yes", "--git-commit", "Add project"})
+		cmd.SetOut(io.Discard)
+
+		require.EqualError(t, cmd.Execute(), "failed to create initial commit: author field is required")
+
+		fakeRepo.AssertExpectations(t)
+	})
+}
+
+func TestCreateMonorepo(t *testing.T) {
+	repoDir := t.TempDir()
+
+	writeFile(t, filepath.Join(repoDir, "skeletons/service/.kickoff.yaml"), "")
+	writeFile(t, filepath.Join(repoDir, "skeletons/service/go.mod.skel"), "module {{.Project.GoPackagePath}}\n")
+
+	configPath := testutil.NewConfigFileBuilder(t).
+		WithRepository("default", repoDir).
+		WithProjectOwner("acme").
+		Create()
+
+	streams, _, _, _ := cli.NewTestIOStreams()
+
+	f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
+
+	_, fakePrompt := stubPrompt(f)
+	defer fakePrompt.AssertExpectations(t)
+
+	// Git must not be initialized for sub-projects.
+	fakeClient := &git.FakeClient{}
+	defer fakeClient.AssertExpectations(t)
+
+	f.GitClient = func() git.Client { return fakeClient }
+
+	t.Run("creates sub-project", func(t *testing.T) {
+		repoRoot := filepath.Join(t.TempDir(), "platform")
+		require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, ".git"), 0755))
+
+		dir := filepath.Join(repoRoot, "services", "myservice")
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myservice", "service", "-d", dir, "--yes", "--monorepo"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		assertFileContains(t, filepath.Join(dir, "go.mod"), "module github.com/acme/platform/services/myservice\n")
+
+		lock, err := kickoff.LoadLock(filepath.Join(dir, kickoff.LockFileName))
+		require.NoError(t, err)
+		assert.True(t, lock.Project.Monorepo)
+	})
+
+	t.Run("uses repository name of origin remote", func(t *testing.T) {
+		repoRoot, repo := testutil.InitGitRepo(t)
+
+		_, err := repo.CreateRemote(&config.RemoteConfig{
+			Name: "origin",
+			URLs: []string{"git@github.com:acme/platform.git"},
+		})
+		require.NoError(t, err)
+
+		// The directory name of the clone differs from the repository name.
+		dir := filepath.Join(repoRoot, "services", "myservice")
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myservice", "service", "-d", dir, "--yes", "--monorepo"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		assertFileContains(t, filepath.Join(dir, "go.mod"), "module github.com/acme/platform/services/myservice\n")
+	})
+
+	t.Run("applies sub-project plan without initializing git", func(t *testing.T) {
+		repoRoot := filepath.Join(t.TempDir(), "platform")
+		require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, ".git"), 0755))
+
+		dir := filepath.Join(repoRoot, "services", "myservice")
+		planFile := filepath.Join(t.TempDir(), "plan.json")
+
+		cmd := NewPlanCmd(f)
+		cmd.SetArgs([]string{"myservice", "service", "-d", dir, "--monorepo", "--out", planFile})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		cmd = NewApplyCmd(f)
+		cmd.SetArgs([]string{planFile, "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		assertFileContains(t, filepath.Join(dir, "go.mod"), "module github.com/acme/platform/services/myservice\n")
+		require.NoDirExists(t, filepath.Join(dir, ".git"))
+	})
+
+	t.Run("requires enclosing git repository", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "myservice")
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myservice", "service", "-d", dir, "--yes", "--monorepo"})
+		cmd.SetOut(io.Discard)
+
+		require.EqualError(t, cmd.Execute(), "--monorepo requires "+dir+" to be inside of a git repository")
+	})
+
+	t.Run("rejects repository root", func(t *testing.T) {
+		repoRoot := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, ".git"), 0755))
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myservice", "service", "-d", repoRoot, "--yes", "--monorepo"})
+		cmd.SetOut(io.Discard)
+
+		require.EqualError(t, cmd.Execute(),
+			"--monorepo requires the project directory to be a subdirectory of the repository root "+repoRoot)
+	})
+}
+
+func TestCreateParameters(t *testing.T) {
+	repoDir := t.TempDir()
+
+	writeFile(t, filepath.Join(repoDir, "skeletons/params/.kickoff.yaml"), `parameters:
+- name: service.port
+  type: int
+  min: 1
+- name: database
+  type: enum
+  options: [postgres, mysql]
+  default: postgres
+- name: dbName
+  default: "{{.Project.Name}}_{{.Values.database}}"
+  when: .Values.database
+- name: docker
+  type: bool
+  default: false
+`)
+	writeFile(t, filepath.Join(repoDir, "skeletons/params/config.txt.skel"),
+		"{{.Values.service.port}} {{.Values.database}} {{.Values.dbName}} {{.Values.docker}}\n")
+
+	configPath := testutil.NewConfigFileBuilder(t).
+		WithRepository("default", repoDir).
+		WithProjectOwner("johndoe").
+		Create()
+
+	streams, _, _, _ := cli.NewTestIOStreams()
+
+	f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
+	f.HTTPClient = func() *http.Client { return http.DefaultClient }
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.github.com/gitignore/templates", httpmock.NewStringResponder(200, `[]`))
+	httpmock.RegisterResponder("GET", "https://api.github.com/licenses", httpmock.NewStringResponder(200, `[]`))
+
+	t.Run("uses provided values and defaults", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "myproject")
+
+		stubPrompt(f)
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myproject", "params", "-d", dir, "--yes", "--set", "service.port=8080"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		assertFileContains(t, filepath.Join(dir, "config.txt"), "8080 postgres myproject_postgres false\n")
+
+		lock, err := kickoff.LoadLock(filepath.Join(dir, kickoff.LockFileName))
+		require.NoError(t, err)
+		assert.Equal(t, "myproject_postgres", lock.Values["dbName"])
+	})
+
+	t.Run("requires parameters without default", func(t *testing.T) {
+		stubPrompt(f)
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myproject", "params", "-d", t.TempDir(), "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.EqualError(t, cmd.Execute(),
+			`parameter "service.port" of skeleton default:params: value is required, provide it via --set or --values`)
+	})
+
+	t.Run("validates provided values", func(t *testing.T) {
+		stubPrompt(f)
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myproject", "params", "-d", t.TempDir(), "--yes", "--set", "service.port=0"})
+		cmd.SetOut(io.Discard)
+
+		require.EqualError(t, cmd.Execute(),
+			`parameter "service.port" of skeleton default:params: invalid value: must be at least 1, got 0`)
+	})
+
+	t.Run("interactive mode prompts for parameters", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "myproject")
+
+		stubber, fakePrompt := stubPrompt(f)
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myproject", "params", "--interactive"})
+		cmd.SetOut(io.Discard)
+
+		// skeleton name, project name and dir
+		stubber.StubOneDefault()
+		stubber.StubOneDefault()
+		stubber.StubOne(dir)
+
+		// project host, project owner
+		stubber.StubOneDefault()
+		stubber.StubOneDefault()
+
+		// git init
+		stubber.StubOne(false)
+
+		// parameters
+		stubber.StubOne("9090")
+		stubber.StubOne("mysql")
+		stubber.StubOneDefault()
+		stubber.StubOne(true)
+
+		// edit values
+		stubber.StubOne(false)
+
+		// confirm apply
+		stubber.StubOne(true)
+
+		require.NoError(t, cmd.Execute())
+
+		fakePrompt.AssertExpectations(t)
+
+		assertFileContains(t, filepath.Join(dir, "config.txt"), "9090 mysql myproject_mysql true\n")
+	})
+}
+
+func TestCreateFromSpec(t *testing.T) {
+	repoDir := t.TempDir()
+
+	writeFile(t, filepath.Join(repoDir, "skeletons/spec/.kickoff.yaml"), "values:\n  greeting: hello\n")
+	writeFile(t, filepath.Join(repoDir, "skeletons/spec/README.md.skel"), "{{.Values.greeting}} {{.Project.Owner}}\n")
+	writeFile(t, filepath.Join(repoDir, "skeletons/spec/skipped.txt"), "skipped")
+
+	configPath := testutil.NewConfigFileBuilder(t).
+		WithRepository("default", "../../testdata/repos/repo1").
+		WithProjectOwner("johndoe").
+		Create()
+
+	streams, _, _, _ := cli.NewTestIOStreams()
+
+	f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
+
+	_, fakePrompt := stubPrompt(f)
+	defer fakePrompt.AssertExpectations(t)
+
+	writeSpec := func(t *testing.T, dir string) string {
+		path := filepath.Join(t.TempDir(), "project.yaml")
+		writeFile(t, path, `name: myproject
+dir: `+dir+`
+owner: acme
+repositories:
+  specrepo: `+repoDir+`
+skeletons:
+- specrepo:spec
+values:
+  greeting: hi
+skipFiles:
+- skipped.txt
+git:
+  init: false
+`)
+		return path
+	}
+
+	t.Run("creates project from spec", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "myproject")
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"-f", writeSpec(t, dir), "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		assertFileContains(t, filepath.Join(dir, "README.md"), "hi acme\n")
+		require.NoFileExists(t, filepath.Join(dir, "skipped.txt"))
+		require.NoDirExists(t, filepath.Join(dir, ".git"))
+	})
+
+	t.Run("flags take precedence over spec", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "myproject")
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{
+			"-f", writeSpec(t, filepath.Join(t.TempDir(), "other")), "--yes",
+			"-d", dir, "--owner", "janedoe", "--set", "greeting=hey", "--skip-file", "README.md", "--init-git",
+		})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		require.NoFileExists(t, filepath.Join(dir, "README.md"))
+		assertFileContains(t, filepath.Join(dir, "skipped.txt"), "skipped")
+		require.DirExists(t, filepath.Join(dir, ".git"))
+
+		lock, err := kickoff.LoadLock(filepath.Join(dir, kickoff.LockFileName))
+		require.NoError(t, err)
+		assert.Equal(t, "janedoe", lock.Project.Owner)
+		assert.Equal(t, "hey", lock.Values["greeting"])
+	})
+
+	t.Run("resolves relative dir against spec file", func(t *testing.T) {
+		path := writeSpec(t, "services/myproject")
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"-f", path, "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		assertFileContains(t, filepath.Join(filepath.Dir(path), "services/myproject/README.md"), "hi acme\n")
+	})
+
+	t.Run("arguments complete incomplete spec", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "myproject")
+
+		path := filepath.Join(t.TempDir(), "project.yaml")
+		writeFile(t, path, "dir: "+dir+"\nowner: acme\nrepositories:\n  specrepo: "+repoDir+"\nvalues:\n  greeting: hi\n")
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"myproject", "specrepo:spec", "-f", path, "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		assertFileContains(t, filepath.Join(dir, "README.md"), "hi acme\n")
+	})
+
+	t.Run("rejects invalid spec", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "project.yaml")
+		writeFile(t, path, "name: myproject\n")
+
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"-f", path, "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.EqualError(t, cmd.Execute(), "invalid project spec: skeletons must not be empty")
+	})
+
+	t.Run("rejects --from-lock", func(t *testing.T) {
+		cmd := NewCreateCmd(f)
+		cmd.SetArgs([]string{"-f", "project.yaml", "--from-lock", kickoff.LockFileName})
+		cmd.SetOut(io.Discard)
+
+		require.EqualError(t, cmd.Execute(), "--from-lock cannot be combined with --file")
+	})
+}
+
+func TestCreateBatch(t *testing.T) {
+	repoDir := t.TempDir()
+
+	writeFile(t, filepath.Join(repoDir, "skeletons/service/.kickoff.yaml"),
+		"values:\n  fail: false\nhooks:\n  postCreate:\n  - test {{.Values.fail}} = false\n")
+	writeFile(t, filepath.Join(repoDir, "skeletons/service/README.md.skel"), "# {{.Project.Name}}\n")
+
+	configPath := testutil.NewConfigFileBuilder(t).
+		WithRepository("default", repoDir).
+		WithProjectOwner("johndoe").
+		Create()
+
+	streams, _, out, _ := cli.NewTestIOStreams()
+
+	f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
+
+	stubber, fakePrompt := stubPrompt(f)
+	defer fakePrompt.AssertExpectations(t)
+
+	t.Run("creates all projects and reports failures per project", func(t *testing.T) {
+		dir := t.TempDir()
+		manifest := filepath.Join(dir, "manifest.yaml")
+
+		writeFile(t, manifest, `projects:
+- name: svc-a
+  dir: `+filepath.Join(dir, "svc-a")+`
+  skeletons: [service]
+  git: {init: false}
+- name: svc-b
+  dir: `+filepath.Join(dir, "svc-b")+`
+  skeletons: [service]
+  values: {fail: true}
+  git: {init: false}
+- name: svc-c
+  dir: `+filepath.Join(dir, "svc-c")+`
+  skeletons: [service]
+  git: {init: false}
+`)
+
+		// confirm batch
+		stubber.StubOne(true)
+
+		cmd := NewCreateBatchCmd(f)
+		cmd.SetArgs([]string{manifest, "--workers", "2"})
+		cmd.SetOut(io.Discard)
+
+		require.EqualError(t, cmd.Execute(), "1 of 3 projects failed")
+
+		assertFileContains(t, filepath.Join(dir, "svc-a", "README.md"), "# svc-a\n")
+		assertFileContains(t, filepath.Join(dir, "svc-c", "README.md"), "# svc-c\n")
+		assert.Contains(t, out.String(), "Project svc-b failed")
+	})
+
+	t.Run("combines gitignore lines of monorepo projects", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("GET", "https://api.github.com/gitignore/templates",
+			httpmock.NewStringResponder(200, `["go", "node"]`))
+		httpmock.RegisterResponder("GET", "https://api.github.com/gitignore/templates/go",
+			httpmock.NewStringResponder(200, `{"name":"go","source":"*.test\nvendor/\n"}`))
+		httpmock.RegisterResponder("GET", "https://api.github.com/gitignore/templates/node",
+			httpmock.NewStringResponder(200, `{"name":"node","source":"node_modules/\n*.log\n"}`))
+
+		repoRoot := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, ".git"), 0755))
+		writeFile(t, filepath.Join(repoRoot, ".gitignore"), "*.log\n")
+
+		manifest := filepath.Join(repoRoot, "manifest.yaml")
+
+		writeFile(t, manifest, `projects:
+- name: svc-a
+  dir: `+filepath.Join(repoRoot, "svc-a")+`
+  skeletons: [service]
+  gitignore: go
+  monorepo: true
+- name: svc-b
+  dir: `+filepath.Join(repoRoot, "svc-b")+`
+  skeletons: [service]
+  gitignore: node
+  monorepo: true
+`)
+
+		cmd := NewCreateBatchCmd(f)
+		cmd.SetArgs([]string{manifest, "--yes", "--workers", "2"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		content, err := os.ReadFile(filepath.Join(repoRoot, ".gitignore"))
+		require.NoError(t, err)
+		assert.Equal(t, "*.log\n### go ###\n*.test\nvendor/\n### node ###\nnode_modules/\n", string(content))
+	})
+
+	t.Run("rejects projects sharing a directory", func(t *testing.T) {
+		dir := t.TempDir()
+		manifest := filepath.Join(dir, "manifest.yaml")
+
+		writeFile(t, manifest, `projects:
+- name: svc-a
+  dir: `+filepath.Join(dir, "svc")+`
+  skeletons: [service]
+- name: svc-b
+  dir: `+filepath.Join(dir, "svc")+`
+  skeletons: [service]
+`)
+
+		cmd := NewCreateBatchCmd(f)
+		cmd.SetArgs([]string{manifest, "--yes"})
+		cmd.SetOut(io.Discard)
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "projects svc-a and svc-b must not be created in the same directory")
+		require.NoDirExists(t, filepath.Join(dir, "svc"))
+	})
+}
@@ -0,0 +1,136 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/martinohmann/kickoff/internal/cmdutil"
+	"github.com/martinohmann/kickoff/internal/homedir"
+	"github.com/martinohmann/kickoff/internal/project"
+	"github.com/spf13/cobra"
+)
+
+// NewPlanCmd creates a command that creates a plan for a new project and
+// writes it to a file or stdout without touching the project directory.
+func NewPlanCmd(f *cmdutil.Factory) *cobra.Command {
+	o := &PlanOptions{
+		CreateOptions: CreateOptions{
+			IOStreams:  f.IOStreams,
+			Config:     f.Config,
+			GitClient:  f.GitClient,
+			HTTPClient: f.HTTPClient,
+			Repository: f.Repository,
+			Prompt:     f.Prompt,
+			InitGit:    true,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "plan <name> <skeleton-name> [<skeleton-name>...]",
+		Short: "Create a plan for a new project",
+		Long: cmdutil.LongDesc(`
+			Create a plan for a new project.
+
+			The plan contains all file operations that are needed to create the project
+			including the rendered file contents and their digests. It can be reviewed
+			before it is applied via 'kickoff project apply'.`),
+		Example: cmdutil.Examples(`
+			# Write the plan as JSON to stdout
+			kickoff project plan myproject myskeleton
+
+			# Write the plan as YAML to a file
+			kickoff project plan myproject myskeleton --output yaml --out plan.yaml
+
+			# Apply the plan
+			kickoff project apply plan.yaml`),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return cmdutil.SkeletonNames(f, o.RepoNames...), cobra.ShellCompDirectiveDefault
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) > 0 {
+				o.ProjectName = args[0]
+			}
+
+			if len(args) > 1 {
+				o.SkeletonNames = args[1:]
+			}
+
+			if err := o.Complete(); err != nil {
+				return err
+			}
+
+			return o.Run()
+		},
+	}
+
+	o.AddConfigFlags(cmd)
+
+	cmdutil.AddRepositoryFlag(cmd, f, &o.RepoNames)
+	cmdutil.AddOutputFlag(cmd, &o.Output, "json", "yaml")
+
+	cmd.Flags().StringVar(&o.OutFile, "out", o.OutFile, "Write the plan to this file. If empty the plan is written to stdout")
+
+	return cmd
+}
+
+// PlanOptions holds the options for the plan command.
+type PlanOptions struct {
+	CreateOptions
+
+	Output  string
+	OutFile string
+}
+
+// Run creates the project plan and writes it to the configured output.
+func (o *PlanOptions) Run() error {
+	config, err := o.makeConfig(context.Background())
+	if err != nil {
+		return err
+	}
+
+	plan, err := project.MakePlan(config)
+	if err != nil {
+		return err
+	}
+
+	if o.OutFile == "" {
+		return o.renderPlan(o.Out, plan)
+	}
+
+	if err := o.printConfig(config); err != nil {
+		return err
+	}
+
+	printPlan(o.Out, plan)
+
+	f, err := os.Create(o.OutFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := o.renderPlan(f, plan); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.Out, "%s Plan written to %s. Apply it using %s\n",
+		color.GreenString("✓"), bold.Sprint(homedir.Collapse(o.OutFile)),
+		bold.Sprintf("kickoff project apply %s", o.OutFile))
+
+	return f.Close()
+}
+
+func (o *PlanOptions) renderPlan(w io.Writer, plan *project.Plan) error {
+	switch o.Output {
+	case "yaml":
+		return cmdutil.RenderYAML(w, plan)
+	default:
+		return cmdutil.RenderJSON(w, plan)
+	}
+}
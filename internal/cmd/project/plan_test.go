@@ -0,0 +1,137 @@
+package project
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/martinohmann/kickoff/internal/cli"
+	"github.com/martinohmann/kickoff/internal/cmdutil"
+	"github.com/martinohmann/kickoff/internal/git"
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanAndApply(t *testing.T) {
+	configPath := testutil.NewConfigFileBuilder(t).
+		WithRepository("default", "../../testdata/repos/repo1").
+		WithProjectOwner("johndoe").
+		Create()
+
+	streams, _, out, _ := cli.NewTestIOStreams()
+
+	f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
+
+	_, fakePrompt := stubPrompt(f)
+	defer fakePrompt.AssertExpectations(t)
+
+	t.Run("plan is written to stdout", func(t *testing.T) {
+		out.Reset()
+
+		dir := filepath.Join(t.TempDir(), "myproject")
+
+		cmd := NewPlanCmd(f)
+		cmd.SetArgs([]string{"myproject", "default:advanced", "-d", dir})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+		require.NoDirExists(t, dir)
+
+		var plan map[string]interface{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &plan))
+		assert.Equal(t, dir, plan["projectDir"])
+	})
+
+	t.Run("plan file is applied", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "myproject")
+		planFile := filepath.Join(t.TempDir(), "plan.yaml")
+
+		cmd := NewPlanCmd(f)
+		cmd.SetArgs([]string{"myproject", "default:advanced", "-d", dir, "-o", "yaml", "--out", planFile})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+		require.FileExists(t, planFile)
+		require.NoDirExists(t, dir)
+
+		cmd = NewApplyCmd(f)
+		cmd.SetArgs([]string{planFile, "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+		require.FileExists(t, filepath.Join(dir, "README.md"))
+		require.DirExists(t, filepath.Join(dir, ".git"))
+	})
+
+	t.Run("outdated plan is rejected", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "myproject")
+		planFile := filepath.Join(t.TempDir(), "plan.json")
+
+		cmd := NewPlanCmd(f)
+		cmd.SetArgs([]string{"myproject", "default:advanced", "-d", dir, "--out", planFile})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		writeFile(t, filepath.Join(dir, "README.md"), "created after planning")
+
+		cmd = NewApplyCmd(f)
+		cmd.SetArgs([]string{planFile, "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.EqualError(t, cmd.Execute(), "project directory changed since the plan was made: README.md")
+
+		content, err := os.ReadFile(filepath.Join(dir, "README.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "created after planning", string(content))
+	})
+}
+
+func TestPlanAndApplyGit(t *testing.T) {
+	configPath := testutil.NewConfigFileBuilder(t).
+		WithRepository("default", "../../testdata/repos/repo1").
+		WithProjectOwner("johndoe").
+		WithProjectGit(kickoff.GitConfig{CommitMessage: "Initial commit", Remote: "origin"}).
+		Create()
+
+	streams, _, _, _ := cli.NewTestIOStreams()
+
+	f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
+
+	_, fakePrompt := stubPrompt(f)
+	defer fakePrompt.AssertExpectations(t)
+
+	dir := filepath.Join(t.TempDir(), "myproject")
+	planFile := filepath.Join(t.TempDir(), "plan.json")
+
+	cmd := NewPlanCmd(f)
+	cmd.SetArgs([]string{"myproject", "default:advanced", "-d", dir, "--out", planFile})
+	cmd.SetOut(io.Discard)
+
+	require.NoError(t, cmd.Execute())
+
+	fakeRepo := &git.FakeRepository{}
+	fakeRepo.On("SetHead", "main").Return(nil).Once()
+	fakeRepo.On("AddAll").Return(nil).Once()
+	fakeRepo.On("Commit", "Initial commit").Return(plumbing.ZeroHash, nil).Once()
+	fakeRepo.On("CreateRemote", "origin", "https://github.com/johndoe/myproject").Return(nil).Once()
+
+	fakeClient := &git.FakeClient{}
+	fakeClient.On("Init", dir).Return(fakeRepo, nil).Once()
+
+	f.GitClient = func() git.Client { return fakeClient }
+
+	cmd = NewApplyCmd(f)
+	cmd.SetArgs([]string{planFile, "--yes", "--git-branch", "main"})
+	cmd.SetOut(io.Discard)
+
+	require.NoError(t, cmd.Execute())
+
+	fakeClient.AssertExpectations(t)
+	fakeRepo.AssertExpectations(t)
+}
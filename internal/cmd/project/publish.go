@@ -0,0 +1,223 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/go-git/go-git/v5/config"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/martinohmann/kickoff/internal/cli"
+	"github.com/martinohmann/kickoff/internal/cmdutil"
+	"github.com/martinohmann/kickoff/internal/forge"
+	"github.com/martinohmann/kickoff/internal/git"
+	"github.com/martinohmann/kickoff/internal/homedir"
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// NewPublishCmd creates a command that creates the remote repository of a
+// project on its forge and pushes the project to it.
+func NewPublishCmd(f *cmdutil.Factory) *cobra.Command {
+	o := &PublishOptions{
+		IOStreams: f.IOStreams,
+		Config:    f.Config,
+		GitClient: f.GitClient,
+		Remote:    "origin",
+	}
+
+	cmd := &cobra.Command{
+		Use:   "publish",
+		Short: "Create the remote repository of a project and push to it",
+		Long: cmdutil.LongDesc(`
+			Create the remote repository of a project and push to it.
+
+			The project name, host and owner are read from the ` + kickoff.LockFileName + ` file in
+			the project directory. The repository is created via the API of the forge that
+			is configured for the project host. Repositories on github.com can be created
+			without further configuration, other hosts need to be added to the forges
+			section of the kickoff config. The API token is read from the
+			` + kickoff.EnvKeyForgeToken + ` environment variable.
+
+			An existing repository is reused if it belongs to the project owner and is
+			still empty. After the repository was created, it is added as remote to the
+			project's git repository unless the remote already exists and the current
+			branch is pushed to it. The project needs at least one commit, see the --git-commit flag of
+			'kickoff project create'.`),
+		Example: cmdutil.Examples(`
+			# Publish the project in the current directory
+			kickoff project publish
+
+			# Publish the project in a specific directory as private repository
+			kickoff project publish --dir /path/to/project --private`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(); err != nil {
+				return err
+			}
+
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.ProjectDir, "dir", "d", o.ProjectDir, "Project directory. If empty the project in $PWD is published")
+	cmd.Flags().StringVar(&o.Remote, "remote", o.Remote, "Name of the git remote to push to")
+	cmd.Flags().BoolVar(&o.Private, "private", o.Private, "Create a private repository")
+	cmd.Flags().StringVar(&o.Description, "description", o.Description, "Description of the repository")
+
+	return cmd
+}
+
+// PublishOptions holds the options for the publish command.
+type PublishOptions struct {
+	cli.IOStreams
+
+	Config    func() (*kickoff.Config, error)
+	GitClient func() git.Client
+
+	ProjectDir  string
+	Remote      string
+	Private     bool
+	Description string
+}
+
+// Complete completes the project publish options.
+func (o *PublishOptions) Complete() (err error) {
+	if o.ProjectDir == "" {
+		o.ProjectDir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+
+	o.ProjectDir, err = filepath.Abs(o.ProjectDir)
+	return err
+}
+
+// Run creates the project repository on the forge configured for the project
+// host and pushes the current branch to it.
+func (o *PublishOptions) Run() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	lock, err := kickoff.LoadLock(filepath.Join(o.ProjectDir, kickoff.LockFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s not found in %s, only projects created by kickoff can be published",
+			kickoff.LockFileName, homedir.Collapse(o.ProjectDir))
+	} else if err != nil {
+		return err
+	}
+
+	if lock.Project.Monorepo {
+		// The project name is not the name of the repository that contains
+		// the sub-project, which is already under version control anyway.
+		return fmt.Errorf("%s is a sub-project of a monorepo, publishing sub-projects is not supported",
+			homedir.Collapse(o.ProjectDir))
+	}
+
+	kickoffConfig, err := o.Config()
+	if err != nil {
+		return err
+	}
+
+	host := lock.Project.Host
+
+	forgeConfig, ok := kickoffConfig.Forge(host)
+	if !ok {
+		return fmt.Errorf("no forge configured for host %q, add it to the forges section of the kickoff config", host)
+	}
+
+	token := os.Getenv(kickoff.EnvKeyForgeToken)
+	if token == "" {
+		return fmt.Errorf("%s must be set to publish projects", kickoff.EnvKeyForgeToken)
+	}
+
+	repo, err := o.GitClient().Open(o.ProjectDir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return fmt.Errorf("%s is not a git repository, create the project with --init-git", homedir.Collapse(o.ProjectDir))
+	} else if err != nil {
+		return err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD, the project repository needs at least one commit: %w", err)
+	}
+
+	provider, err := forge.NewProvider(host, forgeConfig, token, nil)
+	if err != nil {
+		return err
+	}
+
+	login, err := provider.AuthenticatedUser(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to authenticate with %s: %w", host, err)
+	}
+
+	remoteRepo, err := o.createRepository(ctx, provider, lock.Project.Owner, lock.Project.Name)
+	if err != nil {
+		return err
+	}
+
+	err = repo.CreateRemote(o.Remote, remoteRepo.CloneURL)
+	if errors.Is(err, git.ErrRemoteExists) {
+		log.WithField("remote", o.Remote).Debug("remote already exists")
+	} else if err != nil {
+		return fmt.Errorf("failed to add remote %s: %w", o.Remote, err)
+	}
+
+	auth := &githttp.BasicAuth{Username: login, Password: token}
+	refSpec := config.RefSpec(fmt.Sprintf("%s:%s", head.Name(), head.Name()))
+
+	err = repo.Push(ctx, o.Remote, auth, refSpec)
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to push to %s: %w", o.Remote, err)
+	}
+
+	fmt.Fprintf(o.Out, "%s Project %s published to %s\n",
+		color.GreenString("✓"), bold.Sprint(lock.Project.Name), remoteRepo.HTMLURL)
+
+	return nil
+}
+
+// createRepository creates the repository with name for owner. If the
+// repository already exists, e.g. because pushing to it failed in a previous
+// attempt, it is reused as long as it belongs to owner and is still empty.
+func (o *PublishOptions) createRepository(ctx context.Context, provider forge.Provider, owner, name string) (*forge.Repository, error) {
+	repo, err := provider.CreateRepository(ctx, owner, name, &forge.CreateOptions{
+		Private:     o.Private,
+		Description: o.Description,
+	})
+
+	var existsErr forge.AlreadyExistsError
+	if !errors.As(err, &existsErr) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to create repository: %w", err)
+		}
+
+		return repo, nil
+	}
+
+	repo, err = provider.GetRepository(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing repository: %w", err)
+	}
+
+	if !strings.EqualFold(repo.Owner, owner) {
+		return nil, fmt.Errorf("%w and is owned by %s", existsErr, repo.Owner)
+	}
+
+	if !repo.Empty {
+		return nil, fmt.Errorf("%w and is not empty", existsErr)
+	}
+
+	log.WithField("url", repo.HTMLURL).Debug("reusing existing empty repository")
+
+	return repo, nil
+}
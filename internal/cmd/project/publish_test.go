@@ -0,0 +1,215 @@
+package project
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/martinohmann/kickoff/internal/cli"
+	"github.com/martinohmann/kickoff/internal/cmdutil"
+	"github.com/martinohmann/kickoff/internal/git"
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublish(t *testing.T) {
+	// existingRepo is returned by the API if non-nil. Repository creation
+	// fails in that case.
+	var existingRepo map[string]interface{}
+
+	var userRequests int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/user", func(w http.ResponseWriter, r *http.Request) {
+		userRequests++
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(map[string]string{"login": "johndoe"})
+	})
+	mux.HandleFunc("/api/v1/orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		if existingRepo != nil {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"message": "The repository with the same name already exists."})
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{
+			"clone_url": "https://git.example.com/acme/myproject.git",
+			"html_url":  "https://git.example.com/acme/myproject",
+		})
+	})
+	mux.HandleFunc("/api/v1/repos/acme/myproject", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(existingRepo)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	configPath := testutil.NewConfigFileBuilder(t).
+		WithForge("git.example.com", kickoff.ForgeConfig{Type: kickoff.ForgeGitea, URL: server.URL + "/api/v1"}).
+		Create()
+
+	dir := t.TempDir()
+
+	require.NoError(t, kickoff.Save(filepath.Join(dir, kickoff.LockFileName), &kickoff.Lock{
+		Project:   kickoff.ProjectLock{Name: "myproject", Host: "git.example.com", Owner: "acme"},
+		Skeletons: []*kickoff.SkeletonLock{{Name: "default", Repo: kickoff.RepoRef{Path: "/tmp/repo"}}},
+	}))
+
+	streams, _, out, _ := cli.NewTestIOStreams()
+
+	f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
+
+	t.Run("creates repository and pushes current branch", func(t *testing.T) {
+		defer testutil.Setenv(kickoff.EnvKeyForgeToken, "secret")()
+
+		head := plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.ZeroHash)
+		auth := &githttp.BasicAuth{Username: "johndoe", Password: "secret"}
+
+		fakeRepo := &git.FakeRepository{}
+		fakeRepo.On("Head").Return(head, nil).Once()
+		fakeRepo.On("CreateRemote", "origin", "https://git.example.com/acme/myproject.git").Return(nil).Once()
+		fakeRepo.On("Push", mock.Anything, "origin", auth, []config.RefSpec{"refs/heads/main:refs/heads/main"}).Return(nil).Once()
+
+		fakeClient := &git.FakeClient{}
+		fakeClient.On("Open", dir).Return(fakeRepo, nil).Once()
+
+		f.GitClient = func() git.Client { return fakeClient }
+
+		cmd := NewPublishCmd(f)
+		cmd.SetArgs([]string{"-d", dir})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, out.String(), "published to https://git.example.com/acme/myproject")
+		assert.Equal(t, 1, userRequests, "authenticated user must only be requested once")
+
+		fakeClient.AssertExpectations(t)
+		fakeRepo.AssertExpectations(t)
+	})
+
+	t.Run("reuses existing empty repository", func(t *testing.T) {
+		defer testutil.Setenv(kickoff.EnvKeyForgeToken, "secret")()
+
+		existingRepo = map[string]interface{}{
+			"clone_url": "https://git.example.com/acme/myproject.git",
+			"html_url":  "https://git.example.com/acme/myproject",
+			"owner":     map[string]string{"login": "acme"},
+			"empty":     true,
+		}
+		defer func() { existingRepo = nil }()
+
+		head := plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.ZeroHash)
+
+		fakeRepo := &git.FakeRepository{}
+		fakeRepo.On("Head").Return(head, nil).Once()
+		fakeRepo.On("CreateRemote", "origin", "https://git.example.com/acme/myproject.git").Return(git.ErrRemoteExists).Once()
+		fakeRepo.On("Push", mock.Anything, "origin", mock.Anything, []config.RefSpec{"refs/heads/main:refs/heads/main"}).Return(nil).Once()
+
+		fakeClient := &git.FakeClient{}
+		fakeClient.On("Open", dir).Return(fakeRepo, nil).Once()
+
+		f.GitClient = func() git.Client { return fakeClient }
+
+		cmd := NewPublishCmd(f)
+		cmd.SetArgs([]string{"-d", dir})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		fakeRepo.AssertExpectations(t)
+	})
+
+	t.Run("fails if existing repository is not empty", func(t *testing.T) {
+		defer testutil.Setenv(kickoff.EnvKeyForgeToken, "secret")()
+
+		existingRepo = map[string]interface{}{
+			"clone_url": "https://git.example.com/acme/myproject.git",
+			"html_url":  "https://git.example.com/acme/myproject",
+			"owner":     map[string]string{"login": "acme"},
+			"empty":     false,
+		}
+		defer func() { existingRepo = nil }()
+
+		head := plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.ZeroHash)
+
+		fakeRepo := &git.FakeRepository{}
+		fakeRepo.On("Head").Return(head, nil).Once()
+
+		fakeClient := &git.FakeClient{}
+		fakeClient.On("Open", dir).Return(fakeRepo, nil).Once()
+
+		f.GitClient = func() git.Client { return fakeClient }
+
+		cmd := NewPublishCmd(f)
+		cmd.SetArgs([]string{"-d", dir})
+		cmd.SetOut(io.Discard)
+
+		require.EqualError(t, cmd.Execute(), `repository "acme/myproject" already exists and is not empty`)
+
+		fakeRepo.AssertExpectations(t)
+	})
+
+	t.Run("fails without token", func(t *testing.T) {
+		defer testutil.Setenv(kickoff.EnvKeyForgeToken, "")()
+
+		cmd := NewPublishCmd(f)
+		cmd.SetArgs([]string{"-d", dir})
+		cmd.SetOut(io.Discard)
+
+		require.EqualError(t, cmd.Execute(), "KICKOFF_FORGE_TOKEN must be set to publish projects")
+	})
+
+	t.Run("fails if host has no forge", func(t *testing.T) {
+		dir := t.TempDir()
+
+		require.NoError(t, kickoff.Save(filepath.Join(dir, kickoff.LockFileName), &kickoff.Lock{
+			Project:   kickoff.ProjectLock{Name: "myproject", Host: "git.unknown.com", Owner: "acme"},
+			Skeletons: []*kickoff.SkeletonLock{{Name: "default", Repo: kickoff.RepoRef{Path: "/tmp/repo"}}},
+		}))
+
+		cmd := NewPublishCmd(f)
+		cmd.SetArgs([]string{"-d", dir})
+		cmd.SetOut(io.Discard)
+
+		require.EqualError(t, cmd.Execute(), `no forge configured for host "git.unknown.com", add it to the forges section of the kickoff config`)
+	})
+
+	t.Run("fails for monorepo sub-projects", func(t *testing.T) {
+		defer testutil.Setenv(kickoff.EnvKeyForgeToken, "secret")()
+
+		dir := t.TempDir()
+
+		require.NoError(t, kickoff.Save(filepath.Join(dir, kickoff.LockFileName), &kickoff.Lock{
+			Project:   kickoff.ProjectLock{Name: "myservice", Host: "git.example.com", Owner: "acme", Monorepo: true},
+			Skeletons: []*kickoff.SkeletonLock{{Name: "default", Repo: kickoff.RepoRef{Path: "/tmp/repo"}}},
+		}))
+
+		cmd := NewPublishCmd(f)
+		cmd.SetArgs([]string{"-d", dir})
+		cmd.SetOut(io.Discard)
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "publishing sub-projects is not supported")
+	})
+
+	t.Run("fails if lock is missing", func(t *testing.T) {
+		cmd := NewPublishCmd(f)
+		cmd.SetArgs([]string{"-d", t.TempDir()})
+		cmd.SetOut(io.Discard)
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only projects created by kickoff can be published")
+	})
+}
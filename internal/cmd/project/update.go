@@ -0,0 +1,293 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/fatih/color"
+	"github.com/martinohmann/kickoff/internal/cli"
+	"github.com/martinohmann/kickoff/internal/cmdutil"
+	"github.com/martinohmann/kickoff/internal/git"
+	"github.com/martinohmann/kickoff/internal/homedir"
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/project"
+	"github.com/martinohmann/kickoff/internal/prompt"
+	"github.com/martinohmann/kickoff/internal/repository"
+	"github.com/martinohmann/kickoff/internal/template"
+	"github.com/martinohmann/kickoff/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// NewUpdateCmd creates a command that can update existing projects with
+// changes that were made to their skeletons since project creation.
+func NewUpdateCmd(f *cmdutil.Factory) *cobra.Command {
+	o := &UpdateOptions{
+		IOStreams:  f.IOStreams,
+		Config:     f.Config,
+		HTTPClient: f.HTTPClient,
+		Prompt:     f.Prompt,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update a project with the latest changes of its skeletons",
+		Long: cmdutil.LongDesc(`
+			Update a project with the latest changes of its skeletons.
+
+			The skeletons and values the project was created from are read from the
+			` + kickoff.LockFileName + ` file in the project directory. The skeletons are
+			rendered at the commit recorded in the lock file and at the latest revision
+			of their repository. Each project file is then updated via a three-way merge
+			of the old render, the new render and the current file. Conflicting changes
+			are marked with conflict markers that need to be resolved manually.`),
+		Example: cmdutil.Examples(`
+			# Update the project in the current directory
+			kickoff project update
+
+			# Update the project in a specific directory
+			kickoff project update --dir /path/to/project`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(); err != nil {
+				return err
+			}
+
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.AutoApprove, "yes", o.AutoApprove, "Auto-approve all prompts")
+	cmd.Flags().StringVarP(&o.ProjectDir, "dir", "d", o.ProjectDir, "Project directory. If empty the project in $PWD is updated")
+
+	return cmd
+}
+
+// UpdateOptions holds the options for the update command.
+type UpdateOptions struct {
+	cli.IOStreams
+
+	Config     func() (*kickoff.Config, error)
+	HTTPClient func() *http.Client
+	Prompt     prompt.Prompt
+
+	ProjectDir  string
+	AutoApprove bool
+}
+
+// Complete completes the project update options.
+func (o *UpdateOptions) Complete() (err error) {
+	if o.ProjectDir == "" {
+		o.ProjectDir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+
+	o.ProjectDir, err = filepath.Abs(o.ProjectDir)
+	return err
+}
+
+// Run loads the project lock, renders the old and new versions of the
+// project skeletons and merges the changes into the project directory.
+func (o *UpdateOptions) Run() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	lock, err := kickoff.LoadLock(filepath.Join(o.ProjectDir, kickoff.LockFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s not found in %s, only projects created by kickoff can be updated",
+			kickoff.LockFileName, homedir.Collapse(o.ProjectDir))
+	} else if err != nil {
+		return err
+	}
+
+	kickoffConfig, err := o.Config()
+	if err != nil {
+		return err
+	}
+
+	opts := cmdutil.RepositoryOptions(kickoffConfig)
+
+	baseSkeleton, err := loadLockedSkeletons(ctx, lock, true, opts)
+	if err != nil {
+		return err
+	}
+
+	skeleton, err := loadLockedSkeletons(ctx, lock, false, opts)
+	if err != nil {
+		return err
+	}
+
+	newLock, err := updateLock(lock, skeleton)
+	if err != nil {
+		return err
+	}
+
+	var repoRoot, repoName string
+
+	if lock.Project.Monorepo {
+		repoRoot, err = git.FindRepoRoot(o.ProjectDir)
+		if err != nil {
+			return fmt.Errorf("failed to find root of the repository containing the sub-project: %w", err)
+		}
+
+		repoName, err = git.RemoteRepoName(repoRoot, "origin")
+		if err != nil {
+			return fmt.Errorf("failed to read origin remote of %s: %w", homedir.Collapse(repoRoot), err)
+		}
+	}
+
+	base := &project.Config{
+		Name:       lock.Project.Name,
+		Host:       lock.Project.Host,
+		Owner:      lock.Project.Owner,
+		ProjectDir: o.ProjectDir,
+		RepoRoot:   repoRoot,
+		RepoName:   repoName,
+		Skeleton:   baseSkeleton,
+		Values:     lock.Values,
+		Year:       lockYear(lock),
+		Lock:       lock,
+	}
+
+	base.License, err = fetchLicense(ctx, o.HTTPClient(), lock.Project.License)
+	if err != nil {
+		return err
+	}
+
+	base.Gitignore, err = fetchGitignore(ctx, o.HTTPClient(), lock.Project.Gitignore)
+	if err != nil {
+		return err
+	}
+
+	config := *base
+	config.Skeleton = skeleton
+	config.Lock = newLock
+
+	plan, err := project.MakeUpdatePlan(&config, base)
+	if err != nil {
+		return err
+	}
+
+	if plan.IsNoOp() {
+		fmt.Fprintf(o.Out, "%s Project %s is up to date\n", color.GreenString("✓"), bold.Sprint(lock.Project.Name))
+		return nil
+	}
+
+	printPlan(o.Out, plan)
+
+	if !o.AutoApprove {
+		var apply bool
+
+		err := o.Prompt.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Update project in %s?", homedir.Collapse(o.ProjectDir)),
+			Default: true,
+		}, &apply)
+		if err != nil || !apply {
+			return err
+		}
+
+		fmt.Fprintln(o.Out)
+	}
+
+	if err := plan.Apply(ctx); err != nil {
+		return err
+	}
+
+	o.printSummary(lock.Project.Name, plan)
+
+	return nil
+}
+
+func (o *UpdateOptions) printSummary(name string, plan *project.Plan) {
+	counts := plan.OpCounts
+
+	fmt.Fprintf(o.Out, "%s Project %s updated. %s files created and %s merged\n",
+		color.GreenString("✓"), bold.Sprint(name),
+		color.GreenString("%d", counts[project.OpCreate]),
+		color.BlueString("%d", counts[project.OpMerge]),
+	)
+
+	conflicts := plan.Conflicts()
+	if len(conflicts) == 0 {
+		return
+	}
+
+	fmt.Fprintf(o.Out, "\n%s The following files contain conflicts that need to be resolved manually:\n\n",
+		color.YellowString("!"))
+
+	for _, op := range conflicts {
+		fmt.Fprintf(o.Out, "  %s\n", op.Dest.RelPath())
+	}
+}
+
+// loadLockedSkeletons loads and merges the skeletons recorded in lock. If
+// atCommit is true, the skeletons are loaded at the commits recorded in the
+// lock, otherwise the latest revision of their repositories is loaded.
+func loadLockedSkeletons(ctx context.Context, lock *kickoff.Lock, atCommit bool, opts *repository.Options) (*kickoff.Skeleton, error) {
+	skeletons := make([]*kickoff.Skeleton, len(lock.Skeletons))
+
+	for i, sl := range lock.Skeletons {
+		var (
+			repo kickoff.Repository
+			err  error
+		)
+
+		if atCommit {
+			if sl.Commit == "" {
+				return nil, fmt.Errorf("cannot load skeleton %s at the recorded commit: the repository %s is not a git repository, "+
+					"so the commit the project was created from is unknown", sl, sl.Repo.String())
+			}
+
+			repo, err = repository.OpenCommit(ctx, sl.Repo, sl.Commit, opts)
+		} else {
+			repo, err = repository.OpenRef(ctx, sl.Repo, opts)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		skeletons[i], err = repo.LoadSkeleton(sl.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return kickoff.MergeSkeletons(skeletons...)
+}
+
+// updateLock returns a copy of lock with the commits updated to the latest
+// revisions of the skeleton repositories. Values that were added to the
+// skeletons since are recorded with their defaults.
+func updateLock(lock *kickoff.Lock, skeleton *kickoff.Skeleton) (*kickoff.Lock, error) {
+	values, err := template.MergeValues(skeleton.Values, lock.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	newLock := *lock
+	newLock.KickoffVersion = version.Get().GitVersion
+	newLock.Skeletons = make([]*kickoff.SkeletonLock, len(lock.Skeletons))
+	newLock.Values = values
+
+	for i, sl := range lock.Skeletons {
+		commit, err := repository.ResolveCommit(sl.Repo)
+		if err != nil {
+			return nil, err
+		}
+
+		newLock.Skeletons[i] = &kickoff.SkeletonLock{
+			Name:   sl.Name,
+			Repo:   sl.Repo,
+			Commit: commit,
+		}
+	}
+
+	return &newLock, nil
+}
@@ -0,0 +1,102 @@
+package project
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/martinohmann/kickoff/internal/cli"
+	"github.com/martinohmann/kickoff/internal/cmdutil"
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdate(t *testing.T) {
+	defer testutil.MockRepositoryCacheDir(t.TempDir())()
+
+	repoDir, repo := testutil.InitGitRepo(t)
+
+	testutil.CommitFile(t, repo, repoDir, "skeletons/default/.kickoff.yaml", "values:\n  footer: the footer\n")
+	testutil.CommitFile(t, repo, repoDir, "skeletons/default/README.md.skel", "# {{.Project.Name}}\n\nintro\n")
+
+	configPath := testutil.NewConfigFileBuilder(t).
+		WithRepository("default", repoDir).
+		WithProjectOwner("johndoe").
+		Create()
+
+	streams, _, out, _ := cli.NewTestIOStreams()
+
+	f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
+
+	_, fakePrompt := stubPrompt(f)
+	defer fakePrompt.AssertExpectations(t)
+
+	dir := filepath.Join(t.TempDir(), "myproject")
+
+	cmd := NewCreateCmd(f)
+	cmd.SetArgs([]string{"myproject", "default", "-d", dir, "--yes"})
+	cmd.SetOut(io.Discard)
+
+	require.NoError(t, cmd.Execute())
+	require.FileExists(t, filepath.Join(dir, kickoff.LockFileName))
+
+	t.Run("project without changes is up to date", func(t *testing.T) {
+		out.Reset()
+
+		cmd := NewUpdateCmd(f)
+		cmd.SetArgs([]string{"-d", dir, "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, out.String(), "is up to date")
+	})
+
+	t.Run("merges skeleton changes into project", func(t *testing.T) {
+		assertFileContains(t, filepath.Join(dir, "README.md"), "# myproject\n\nintro\n")
+
+		newCommit := testutil.CommitFile(t, repo, repoDir, "skeletons/default/README.md.skel", "# {{.Project.Name}}\n\nintro\n\n{{.Values.footer}}\n")
+
+		writeFile(t, filepath.Join(dir, "README.md"), "# myproject (changed by the user)\n\nintro\n")
+
+		cmd := NewUpdateCmd(f)
+		cmd.SetArgs([]string{"-d", dir, "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		assertFileContains(t, filepath.Join(dir, "README.md"), "# myproject (changed by the user)\n\nintro\n\nthe footer\n")
+
+		lock, err := kickoff.LoadLock(filepath.Join(dir, kickoff.LockFileName))
+		require.NoError(t, err)
+		assert.Equal(t, newCommit, lock.Skeletons[0].Commit)
+	})
+
+	t.Run("keeps recorded values if skeleton defaults change", func(t *testing.T) {
+		testutil.CommitFile(t, repo, repoDir, "skeletons/default/.kickoff.yaml", "values:\n  footer: new footer\n  license: MIT\n")
+		testutil.CommitFile(t, repo, repoDir, "skeletons/default/README.md.skel",
+			"# {{.Project.Name}}\n\nintro\n\n{{.Values.footer}}\n\n{{.Values.license}}\n")
+
+		cmd := NewUpdateCmd(f)
+		cmd.SetArgs([]string{"-d", dir, "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		assertFileContains(t, filepath.Join(dir, "README.md"), "# myproject (changed by the user)\n\nintro\n\nthe footer\n\nMIT\n")
+
+		lock, err := kickoff.LoadLock(filepath.Join(dir, kickoff.LockFileName))
+		require.NoError(t, err)
+		assert.Equal(t, "the footer", lock.Values["footer"])
+		assert.Equal(t, "MIT", lock.Values["license"])
+	})
+
+	t.Run("fails if lock is missing", func(t *testing.T) {
+		cmd := NewUpdateCmd(f)
+		cmd.SetArgs([]string{"-d", t.TempDir(), "--yes"})
+		cmd.SetOut(io.Discard)
+
+		require.Error(t, cmd.Execute())
+	})
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/martinohmann/kickoff/internal/cmdutil"
 	"github.com/martinohmann/kickoff/internal/homedir"
 	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/repository"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -61,6 +63,14 @@ func (o *ListOptions) Run() error {
 		return err
 	}
 
+	// Skeletons that cannot be used with this version of kickoff are listed
+	// anyway, but the user is warned about them.
+	for _, skeleton := range skeletons {
+		if err := repository.CheckRequirements(skeleton); err != nil {
+			log.Warn(err)
+		}
+	}
+
 	switch o.Output {
 	case "name":
 		for _, skeleton := range skeletons {
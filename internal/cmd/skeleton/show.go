@@ -2,6 +2,7 @@ package skeleton
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"github.com/martinohmann/kickoff/internal/filetree"
 	"github.com/martinohmann/kickoff/internal/homedir"
 	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/repository"
 	"github.com/spf13/cobra"
 )
 
@@ -41,7 +43,10 @@ func NewShowCmd(f *cmdutil.Factory) *cobra.Command {
 			kickoff skeleton show myrepo:myskeleton relpath/to/file
 
 			# Show skeleton config using different output
-			kickoff skeleton show myskeleton --output json`),
+			kickoff skeleton show myskeleton --output json
+
+			# Also list the files excluded via .kickoffignore
+			kickoff skeleton show myskeleton --verbose`),
 		Args: cobra.RangeArgs(1, 2),
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			switch len(args) {
@@ -64,6 +69,8 @@ func NewShowCmd(f *cmdutil.Factory) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVarP(&o.Verbose, "verbose", "v", o.Verbose, "List files that were excluded from the skeleton via .kickoffignore")
+
 	cmdutil.AddOutputFlag(cmd, &o.Output, "full", "json", "yaml")
 	cmdutil.AddRepositoryFlag(cmd, f, &o.RepoNames)
 
@@ -80,6 +87,7 @@ type ShowOptions struct {
 	Output       string
 	RepoNames    []string
 	SkeletonName string
+	Verbose      bool
 }
 
 // Run prints information about a project skeleton in the output format
@@ -99,10 +107,27 @@ func (o *ShowOptions) Run() error {
 		return o.showSkeletonFile(skeleton, o.FilePath)
 	}
 
-	return o.showSkeleton(skeleton)
+	chain := []*kickoff.Skeleton{skeleton}
+
+	if len(skeleton.Extends) > 0 {
+		// Only the skeleton itself is shown, but the skeletons it extends
+		// are resolved to display the inheritance chain.
+		chain, err = repository.LoadSkeletons(repo, []string{o.SkeletonName})
+		if err != nil {
+			return err
+		}
+	}
+
+	return o.showSkeleton(skeleton, chain)
 }
 
-func (o *ShowOptions) showSkeleton(skeleton *kickoff.Skeleton) error {
+func (o *ShowOptions) showSkeleton(skeleton *kickoff.Skeleton, chain []*kickoff.Skeleton) error {
+	if !o.Verbose {
+		s := *skeleton
+		s.IgnoredFiles = nil
+		skeleton = &s
+	}
+
 	switch o.Output {
 	case "json":
 		return cmdutil.RenderJSON(o.Out, skeleton)
@@ -113,6 +138,9 @@ func (o *ShowOptions) showSkeleton(skeleton *kickoff.Skeleton) error {
 		tw.Append(bold.Sprint("Repository"), skeleton.Ref.Repo.Name)
 		tw.Append(bold.Sprint("Name"), skeleton.Ref.Name)
 		tw.Append(bold.Sprint("Path"), homedir.Collapse(skeleton.Ref.Path))
+		if len(chain) > 1 {
+			tw.Append(bold.Sprint("Inheritance"), formatChain(chain))
+		}
 		tw.Render()
 
 		fmt.Fprintln(o.Out)
@@ -137,10 +165,36 @@ func (o *ShowOptions) showSkeleton(skeleton *kickoff.Skeleton) error {
 		tw.Append(tree.Print(), string(buf))
 		tw.Render()
 
+		if len(skeleton.IgnoredFiles) > 0 {
+			fmt.Fprintln(o.Out)
+			fmt.Fprintln(o.Out, bold.Sprint("Ignored files"))
+
+			for _, path := range skeleton.IgnoredFiles {
+				fmt.Fprintln(o.Out, path)
+			}
+		}
+
+		for _, schema := range skeleton.ValuesSchemas {
+			fmt.Fprintln(o.Out)
+			fmt.Fprintln(o.Out, bold.Sprint("Values schema"))
+			fmt.Fprintln(o.Out, schema)
+		}
+
 		return nil
 	}
 }
 
+// formatChain formats the skeletons of an inheritance chain in the order they
+// are composed.
+func formatChain(chain []*kickoff.Skeleton) string {
+	names := make([]string, len(chain))
+	for i, skeleton := range chain {
+		names[i] = skeleton.String()
+	}
+
+	return strings.Join(names, " -> ")
+}
+
 func (o *ShowOptions) showSkeletonFile(skeleton *kickoff.Skeleton, path string) error {
 	file, err := findFile(skeleton.Files, path)
 	if err != nil {
@@ -151,13 +205,31 @@ func (o *ShowOptions) showSkeletonFile(skeleton *kickoff.Skeleton, path string)
 		return fmt.Errorf("%s is a directory", file.RelPath)
 	}
 
+	if file.IsSymlink() && o.Output == "" {
+		_, err = fmt.Fprintf(o.Out, "%s -> %s\n", file.RelPath, file.LinkTarget)
+		return err
+	}
+
 	switch o.Output {
-	case "json":
-		return cmdutil.RenderJSON(o.Out, file)
-	case "yaml":
+	case "json", "yaml":
+		file.Content, err = file.ReadContent()
+		if err != nil {
+			return err
+		}
+
+		if o.Output == "json" {
+			return cmdutil.RenderJSON(o.Out, file)
+		}
+
 		return cmdutil.RenderYAML(o.Out, file)
 	default:
-		_, err = o.Out.Write(file.Content)
+		r, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		_, err = io.Copy(o.Out, r)
 		return err
 	}
 }
@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ghodss/yaml"
 	"github.com/martinohmann/kickoff/internal/cli"
 	"github.com/martinohmann/kickoff/internal/cmdutil"
+	"github.com/martinohmann/kickoff/internal/kickoff"
 	"github.com/martinohmann/kickoff/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -55,6 +57,87 @@ func TestShowCmd(t *testing.T) {
 		assert.Regexp(t, `Files\s+Values`, output)
 	})
 
+	t.Run("show values schema", func(t *testing.T) {
+		out.Reset()
+
+		repoDir := t.TempDir()
+		skeletonDir := filepath.Join(repoDir, "skeletons", "schema")
+
+		require.NoError(t, os.MkdirAll(skeletonDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, kickoff.SkeletonConfigFileName), []byte("{}"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, kickoff.ValuesSchemaFileName), []byte(`{"required": ["image"]}`), 0644))
+
+		configPath := testutil.NewConfigFileBuilder(t).
+			WithRepository("default", repoDir).
+			Create()
+
+		cmd := NewShowCmd(cmdutil.NewFactoryWithConfigPath(streams, configPath))
+		cmd.SetArgs([]string{"schema"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		assert.Contains(t, out.String(), "Values schema\n{\n  \"required\": [\n    \"image\"\n  ]\n}\n")
+	})
+
+	t.Run("show inheritance chain", func(t *testing.T) {
+		out.Reset()
+
+		repoDir := t.TempDir()
+
+		for name, config := range map[string]string{"base": "{}", "go": "extends: [base]", "service": "extends: [go]"} {
+			skeletonDir := filepath.Join(repoDir, "skeletons", name)
+			require.NoError(t, os.MkdirAll(skeletonDir, 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, kickoff.SkeletonConfigFileName), []byte(config), 0644))
+		}
+
+		configPath := testutil.NewConfigFileBuilder(t).
+			WithRepository("default", repoDir).
+			Create()
+
+		cmd := NewShowCmd(cmdutil.NewFactoryWithConfigPath(streams, configPath))
+		cmd.SetArgs([]string{"service"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+
+		assert.Regexp(t, `Inheritance\s+default:base -> default:go -> default:service`, out.String())
+	})
+
+	t.Run("show ignored files in verbose mode", func(t *testing.T) {
+		repoDir := t.TempDir()
+		skeletonDir := filepath.Join(repoDir, "skeletons", "ignore")
+
+		require.NoError(t, os.MkdirAll(skeletonDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, kickoff.SkeletonConfigFileName), []byte("{}"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, kickoff.IgnoreFileName), []byte(".DS_Store\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, ".DS_Store"), nil, 0644))
+
+		configPath := testutil.NewConfigFileBuilder(t).
+			WithRepository("default", repoDir).
+			Create()
+
+		f := cmdutil.NewFactoryWithConfigPath(streams, configPath)
+
+		out.Reset()
+
+		cmd := NewShowCmd(f)
+		cmd.SetArgs([]string{"ignore"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+		assert.NotContains(t, out.String(), "Ignored files")
+
+		out.Reset()
+
+		cmd = NewShowCmd(f)
+		cmd.SetArgs([]string{"ignore", "--verbose"})
+		cmd.SetOut(io.Discard)
+
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, out.String(), "Ignored files\n.DS_Store\n")
+	})
+
 	t.Run("show yaml", func(t *testing.T) {
 		out.Reset()
 
@@ -77,7 +77,7 @@ func NewFactoryWithConfigPath(ioStreams cli.IOStreams, configPath string) *Facto
 			repos = config.Repositories
 		}
 
-		return repository.OpenMap(context.Background(), repos, nil)
+		return repository.OpenMap(context.Background(), repos, RepositoryOptions(config))
 	}
 
 	return &Factory{
@@ -99,3 +99,9 @@ func getConfigPath() string {
 
 	return kickoff.DefaultConfigPath
 }
+
+// RepositoryOptions returns the options for opening skeleton repositories
+// configured in config.
+func RepositoryOptions(config *kickoff.Config) *repository.Options {
+	return &repository.Options{MaxFileSize: config.Skeletons.MaxFileSize}
+}
@@ -0,0 +1,69 @@
+// Package diff provides line oriented diffs and three-way merges of text
+// files.
+package diff
+
+import (
+	"strings"
+
+	gitdiff "github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// LineType is the type of a line in a line oriented diff.
+type LineType uint8
+
+const (
+	// Equal denotes a line that is present in both texts.
+	Equal LineType = iota
+	// Insert denotes a line that is only present in the second text.
+	Insert
+	// Delete denotes a line that is only present in the first text.
+	Delete
+)
+
+// Line is a single line of a line oriented diff.
+type Line struct {
+	Type LineType
+	// Text is the line's content including the trailing newline, if any.
+	Text string
+}
+
+// Lines computes the line oriented modifications needed to turn text a into
+// text b.
+func Lines(a, b string) []Line {
+	lines := make([]Line, 0)
+
+	for _, d := range gitdiff.Do(a, b) {
+		var lineType LineType
+
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			lineType = Insert
+		case diffmatchpatch.DiffDelete:
+			lineType = Delete
+		default:
+			lineType = Equal
+		}
+
+		for _, text := range splitLines(d.Text) {
+			lines = append(lines, Line{Type: lineType, Text: text})
+		}
+	}
+
+	return lines
+}
+
+// splitLines splits s after each newline. The last line does not have a
+// trailing newline if s does not end with one.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
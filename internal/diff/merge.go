@@ -0,0 +1,204 @@
+package diff
+
+import "strings"
+
+// Labels are used to annotate conflict markers produced by Merge.
+type Labels struct {
+	// Ours is the label of the local side of the merge, e.g. "current".
+	Ours string
+	// Theirs is the label of the incoming side of the merge, e.g.
+	// "skeleton".
+	Theirs string
+}
+
+// MergeResult is the result of a three-way merge.
+type MergeResult struct {
+	// Text is the merged text. If there were conflicts, it contains git-style
+	// conflict markers.
+	Text string
+	// Conflicts is the number of conflicting hunks in Text.
+	Conflicts int
+	// Hunks contains the text of each conflicting hunk in Text, including
+	// its conflict markers.
+	Hunks []string
+}
+
+// Unresolved returns the number of conflicting hunks of the merge result that
+// are still present verbatim in text, e.g. after text was edited by hand.
+func (r *MergeResult) Unresolved(text string) int {
+	var n int
+
+	for _, hunk := range r.Hunks {
+		if strings.Contains(text, hunk) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Merge performs a three-way merge of ours and theirs, using base as their
+// common ancestor. Changes that only happened on one side are applied
+// automatically. Overlapping changes that differ from each other are
+// surrounded by conflict markers which are annotated with labels.
+func Merge(base, ours, theirs string, labels Labels) *MergeResult {
+	baseLines := splitLines(base)
+	oursLines := splitLines(ours)
+	theirsLines := splitLines(theirs)
+
+	oursMatches := matchLines(base, ours, len(baseLines))
+	theirsMatches := matchLines(base, theirs, len(baseLines))
+
+	var (
+		buf     strings.Builder
+		hunks   []string
+		i, a, b int
+	)
+
+	for i < len(baseLines) || a < len(oursLines) || b < len(theirsLines) {
+		if i < len(baseLines) && oursMatches[i] == a && theirsMatches[i] == b {
+			// Stable line that is unchanged on both sides.
+			buf.WriteString(baseLines[i])
+			i, a, b = i+1, a+1, b+1
+			continue
+		}
+
+		// Find the next base line that is still present on both sides. All
+		// lines up to there form an unstable chunk that changed on at least
+		// one side.
+		j := i
+		for j < len(baseLines) && (oursMatches[j] < 0 || theirsMatches[j] < 0) {
+			j++
+		}
+
+		endA, endB := len(oursLines), len(theirsLines)
+		if j < len(baseLines) {
+			endA, endB = oursMatches[j], theirsMatches[j]
+		}
+
+		baseChunk := baseLines[i:j]
+		oursChunk := oursLines[a:endA]
+		theirsChunk := theirsLines[b:endB]
+
+		switch {
+		case equalLines(oursChunk, baseChunk):
+			writeLines(&buf, theirsChunk)
+		case equalLines(theirsChunk, baseChunk), equalLines(oursChunk, theirsChunk):
+			writeLines(&buf, oursChunk)
+		default:
+			hunks = append(hunks, writeConflict(&buf, oursChunk, theirsChunk, labels))
+		}
+
+		i, a, b = j, endA, endB
+	}
+
+	return &MergeResult{Text: buf.String(), Conflicts: len(hunks), Hunks: hunks}
+}
+
+// TwoWay combines ours and theirs without a common ancestor. Lines present
+// in both are kept, every differing chunk is surrounded by conflict markers
+// which are annotated with labels. The result can be used to merge two
+// versions of a file by hand.
+func TwoWay(ours, theirs string, labels Labels) *MergeResult {
+	var (
+		buf                    strings.Builder
+		hunks                  []string
+		oursChunk, theirsChunk []string
+	)
+
+	flush := func() {
+		if len(oursChunk) == 0 && len(theirsChunk) == 0 {
+			return
+		}
+
+		hunks = append(hunks, writeConflict(&buf, oursChunk, theirsChunk, labels))
+		oursChunk, theirsChunk = nil, nil
+	}
+
+	for _, line := range Lines(ours, theirs) {
+		switch line.Type {
+		case Delete:
+			oursChunk = append(oursChunk, line.Text)
+		case Insert:
+			theirsChunk = append(theirsChunk, line.Text)
+		default:
+			flush()
+			buf.WriteString(line.Text)
+		}
+	}
+
+	flush()
+
+	return &MergeResult{Text: buf.String(), Conflicts: len(hunks), Hunks: hunks}
+}
+
+// matchLines computes a mapping of line indices in base to the indices of
+// the same line in other. Lines of base that are not present in other are
+// mapped to -1.
+func matchLines(base, other string, n int) []int {
+	matches := make([]int, n)
+
+	var i, j int
+
+	for _, line := range Lines(base, other) {
+		switch line.Type {
+		case Equal:
+			matches[i] = j
+			i++
+			j++
+		case Delete:
+			matches[i] = -1
+			i++
+		case Insert:
+			j++
+		}
+	}
+
+	return matches
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func writeLines(buf *strings.Builder, lines []string) {
+	for _, line := range lines {
+		buf.WriteString(line)
+	}
+}
+
+// writeConflict writes a conflicting hunk surrounded by conflict markers to
+// buf and returns it.
+func writeConflict(buf *strings.Builder, ours, theirs []string, labels Labels) string {
+	var hunk strings.Builder
+
+	hunk.WriteString("<<<<<<< " + labels.Ours + "\n")
+	writeTerminatedLines(&hunk, ours)
+	hunk.WriteString("=======\n")
+	writeTerminatedLines(&hunk, theirs)
+	hunk.WriteString(">>>>>>> " + labels.Theirs + "\n")
+
+	buf.WriteString(hunk.String())
+
+	return hunk.String()
+}
+
+// writeTerminatedLines writes lines to buf and ensures that the last line is
+// terminated by a newline so that it does not run into a conflict marker.
+func writeTerminatedLines(buf *strings.Builder, lines []string) {
+	writeLines(buf, lines)
+
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		buf.WriteString("\n")
+	}
+}
@@ -0,0 +1,123 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	labels := Labels{Ours: "current", Theirs: "skeleton"}
+
+	testCases := []struct {
+		name              string
+		base              string
+		ours              string
+		theirs            string
+		expected          string
+		expectedConflicts int
+	}{
+		{
+			name:     "no changes",
+			base:     "a\nb\nc\n",
+			ours:     "a\nb\nc\n",
+			theirs:   "a\nb\nc\n",
+			expected: "a\nb\nc\n",
+		},
+		{
+			name:     "only ours changed",
+			base:     "a\nb\nc\n",
+			ours:     "a\nB\nc\n",
+			theirs:   "a\nb\nc\n",
+			expected: "a\nB\nc\n",
+		},
+		{
+			name:     "only theirs changed",
+			base:     "a\nb\nc\n",
+			ours:     "a\nb\nc\n",
+			theirs:   "a\nb\nC\nd\n",
+			expected: "a\nb\nC\nd\n",
+		},
+		{
+			name:     "non-overlapping changes on both sides",
+			base:     "a\nb\nc\nd\ne\n",
+			ours:     "A\nb\nc\nd\ne\n",
+			theirs:   "a\nb\nc\nd\nE\nf\n",
+			expected: "A\nb\nc\nd\nE\nf\n",
+		},
+		{
+			name:     "identical changes on both sides",
+			base:     "a\nb\nc\n",
+			ours:     "a\nx\nc\n",
+			theirs:   "a\nx\nc\n",
+			expected: "a\nx\nc\n",
+		},
+		{
+			name:              "conflicting changes",
+			base:              "a\nb\nc\n",
+			ours:              "a\nx\nc\n",
+			theirs:            "a\ny\nc\n",
+			expected:          "a\n<<<<<<< current\nx\n=======\ny\n>>>>>>> skeleton\nc\n",
+			expectedConflicts: 1,
+		},
+		{
+			name:              "conflicting additions without base",
+			base:              "",
+			ours:              "x",
+			theirs:            "y\n",
+			expected:          "<<<<<<< current\nx\n=======\ny\n>>>>>>> skeleton\n",
+			expectedConflicts: 1,
+		},
+		{
+			name:     "deletion on one side",
+			base:     "a\nb\nc\n",
+			ours:     "a\nc\n",
+			theirs:   "a\nb\nc\nd\n",
+			expected: "a\nc\nd\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Merge(tc.base, tc.ours, tc.theirs, labels)
+			assert.Equal(t, tc.expected, result.Text)
+			assert.Equal(t, tc.expectedConflicts, result.Conflicts)
+		})
+	}
+}
+
+func TestTwoWay(t *testing.T) {
+	labels := Labels{Ours: "current", Theirs: "skeleton"}
+
+	result := TwoWay("a\nb\nc\n", "a\nx\nc\nd\n", labels)
+
+	assert.Equal(t, 2, result.Conflicts)
+	assert.Equal(t, `a
+<<<<<<< current
+b
+=======
+x
+>>>>>>> skeleton
+c
+<<<<<<< current
+=======
+d
+>>>>>>> skeleton
+`, result.Text)
+
+	result = TwoWay("same\n", "same\n", labels)
+	assert.Equal(t, 0, result.Conflicts)
+	assert.Equal(t, "same\n", result.Text)
+}
+
+func TestMergeResult_Unresolved(t *testing.T) {
+	result := TwoWay("a\nb\nc\n", "a\nx\nc\nd\n", Labels{Ours: "current", Theirs: "skeleton"})
+	require.Len(t, result.Hunks, 2)
+
+	assert.Equal(t, 2, result.Unresolved(result.Text))
+	assert.Equal(t, 1, result.Unresolved("a\nx\nc\n"+result.Hunks[1]))
+	assert.Equal(t, 0, result.Unresolved("a\nx\nc\nd\n"))
+	// Conflict markers that are part of the content itself do not count.
+	assert.Equal(t, 0, result.Unresolved("<<<<<<< ours\n=======\n>>>>>>> theirs\n"))
+}
@@ -0,0 +1,192 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hunkHeaderRegexp = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// HunkError is returned by Apply if a hunk of the patch does not match the
+// text it is applied to.
+type HunkError struct {
+	// Hunk is the 1-based index of the hunk within the patch.
+	Hunk int
+	// Header is the header line of the hunk, e.g. `@@ -3,2 +3,3 @@`.
+	Header string
+}
+
+// Error implements the error interface.
+func (e *HunkError) Error() string {
+	return fmt.Sprintf("hunk #%d %s does not apply", e.Hunk, e.Header)
+}
+
+type patchHunk struct {
+	header   string
+	oldStart int
+	oldLines []string
+	newLines []string
+}
+
+// Apply applies the unified diff patch to text and returns the result. Hunks
+// are applied in order. If the lines a hunk replaces are not found at the
+// position given in its header, the closest position after the previous hunk
+// is used. Returns a *HunkError if a hunk does not match at all.
+func Apply(text, patch string) (string, error) {
+	hunks, err := parsePatch(patch)
+	if err != nil {
+		return "", err
+	}
+
+	lines := splitLines(text)
+
+	var (
+		sb     strings.Builder
+		cursor int
+	)
+
+	for i, h := range hunks {
+		pos := findHunk(lines, h, cursor)
+		if pos < 0 {
+			return "", &HunkError{Hunk: i + 1, Header: h.header}
+		}
+
+		writeLines(&sb, lines[cursor:pos])
+		writeLines(&sb, h.newLines)
+		cursor = pos + len(h.oldLines)
+	}
+
+	writeLines(&sb, lines[cursor:])
+
+	return sb.String(), nil
+}
+
+// findHunk returns the position of the old lines of h in lines. The search
+// starts at the position given in the hunk header and expands in both
+// directions, but never goes before min. Returns -1 if there is no match.
+func findHunk(lines []string, h *patchHunk, min int) int {
+	expected := h.oldStart - 1
+	if len(h.oldLines) == 0 {
+		// Empty ranges refer to the line before the hunk.
+		expected = h.oldStart
+	}
+
+	for offset := 0; ; offset++ {
+		before, after := expected-offset, expected+offset
+
+		if before < min && after > len(lines)-len(h.oldLines) {
+			return -1
+		}
+
+		if after >= min && matchesAt(lines, h.oldLines, after) {
+			return after
+		}
+
+		if before >= min && matchesAt(lines, h.oldLines, before) {
+			return before
+		}
+	}
+}
+
+func matchesAt(lines, want []string, pos int) bool {
+	if pos < 0 || pos+len(want) > len(lines) {
+		return false
+	}
+
+	return equalLines(lines[pos:pos+len(want)], want)
+}
+
+// parsePatch parses the hunks of a unified diff. File headers and any other
+// lines before the first hunk are ignored.
+func parsePatch(patch string) ([]*patchHunk, error) {
+	lines := splitLines(patch)
+
+	var hunks []*patchHunk
+
+	for i := 0; i < len(lines); {
+		header := strings.TrimRight(lines[i], "\r\n")
+		i++
+
+		m := hunkHeaderRegexp.FindStringSubmatch(header)
+		if m == nil {
+			if len(hunks) > 0 && strings.TrimSpace(header) != "" {
+				return nil, fmt.Errorf("invalid patch: unexpected line %q after hunk", header)
+			}
+
+			continue
+		}
+
+		h := &patchHunk{header: header, oldStart: atoi(m[1])}
+		oldCount, newCount := rangeCount(m[2]), rangeCount(m[4])
+
+		var prev byte
+
+		for i < len(lines) && (len(h.oldLines) < oldCount || len(h.newLines) < newCount || strings.HasPrefix(lines[i], `\`)) {
+			line := lines[i]
+			i++
+
+			// Some tools strip the space of blank context lines.
+			op, text := byte(' '), line
+			if line != "\n" && line != "\r\n" {
+				op, text = line[0], line[1:]
+			}
+
+			switch op {
+			case ' ':
+				h.oldLines = append(h.oldLines, text)
+				h.newLines = append(h.newLines, text)
+			case '-':
+				h.oldLines = append(h.oldLines, text)
+			case '+':
+				h.newLines = append(h.newLines, text)
+			case '\\':
+				// "\ No newline at end of file" refers to the previous line.
+				if prev == ' ' || prev == '-' {
+					trimLastNewline(h.oldLines)
+				}
+
+				if prev == ' ' || prev == '+' {
+					trimLastNewline(h.newLines)
+				}
+			default:
+				return nil, fmt.Errorf("invalid patch: unexpected line %q in hunk %s", strings.TrimRight(line, "\r\n"), header)
+			}
+
+			prev = op
+		}
+
+		if len(h.oldLines) != oldCount || len(h.newLines) != newCount {
+			return nil, fmt.Errorf("invalid patch: hunk %s is incomplete", header)
+		}
+
+		hunks = append(hunks, h)
+	}
+
+	if len(hunks) == 0 {
+		return nil, errors.New("invalid patch: no hunks found")
+	}
+
+	return hunks, nil
+}
+
+func trimLastNewline(lines []string) {
+	if n := len(lines); n > 0 {
+		lines[n-1] = strings.TrimSuffix(strings.TrimSuffix(lines[n-1], "\n"), "\r")
+	}
+}
+
+func rangeCount(s string) int {
+	if s == "" {
+		return 1
+	}
+
+	return atoi(s)
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,123 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	testCases := []struct {
+		name        string
+		text        string
+		patch       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name: "changed line",
+			text: "a\nb\nc\n",
+			patch: `--- a/file
++++ b/file
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+`,
+			expected: "a\nB\nc\n",
+		},
+		{
+			name: "hunk at an offset",
+			text: "x\ny\na\nb\nc\n",
+			patch: `@@ -1,2 +1,3 @@
+ a
++a2
+ b
+`,
+			expected: "x\ny\na\na2\nb\nc\n",
+		},
+		{
+			name: "missing newline at end of file",
+			text: "a\nb",
+			patch: `@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`,
+			expected: "a\nb\n",
+		},
+		{
+			name:     "CRLF line endings with blank context line",
+			text:     "a\r\n\r\nb\r\n",
+			patch:    "--- a/file\r\n+++ b/file\r\n@@ -1,3 +1,3 @@\r\n a\r\n\r\n-b\r\n+B\r\n",
+			expected: "a\r\n\r\nB\r\n",
+		},
+		{
+			name:     "CRLF line endings without newline at end of file",
+			text:     "a\r\nb",
+			patch:    "@@ -1,2 +1,2 @@\r\n a\r\n-b\r\n\\ No newline at end of file\r\n+b\r\n",
+			expected: "a\r\nb\r\n",
+		},
+		{
+			name: "insertion into empty text",
+			text: "",
+			patch: `@@ -0,0 +1,2 @@
++a
++b
+`,
+			expected: "a\nb\n",
+		},
+		{
+			name: "mismatching hunk",
+			text: "a\nb\nc\n",
+			patch: `@@ -1,2 +1,2 @@
+ a
+-b
++B
+@@ -3 +3 @@
+-d
++D
+`,
+			expectedErr: "hunk #2 @@ -3 +3 @@ does not apply",
+		},
+		{
+			name:        "no hunks",
+			text:        "a\n",
+			patch:       "--- a/file\n+++ b/file\n",
+			expectedErr: "invalid patch: no hunks found",
+		},
+		{
+			name: "incomplete hunk",
+			text: "a\n",
+			patch: `@@ -1,2 +1,2 @@
+ a
+`,
+			expectedErr: "invalid patch: hunk @@ -1,2 +1,2 @@ is incomplete",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Apply(tc.text, tc.patch)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestApply_Unified(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	b := "0\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11"
+
+	result, err := Apply(a, Unified(a, b, "a/file", "b/file", 1))
+	require.NoError(t, err)
+	assert.Equal(t, b, result)
+}
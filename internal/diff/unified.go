@@ -0,0 +1,128 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the default number of unchanged lines that are shown
+// around changes in a unified diff.
+const DefaultContext = 3
+
+// Unified returns the unified diff between text a and text b using the
+// provided labels for the file headers. Changes are surrounded by the given
+// number of context lines. Returns an empty string if a and b are equal.
+func Unified(a, b, labelA, labelB string, context int) string {
+	if a == b {
+		return ""
+	}
+
+	lines := Lines(a, b)
+
+	// aPos[i] and bPos[i] hold the number of lines of text a and b that
+	// precede lines[i].
+	aPos := make([]int, len(lines)+1)
+	bPos := make([]int, len(lines)+1)
+
+	for i, line := range lines {
+		aPos[i+1], bPos[i+1] = aPos[i], bPos[i]
+
+		if line.Type != Insert {
+			aPos[i+1]++
+		}
+
+		if line.Type != Delete {
+			bPos[i+1]++
+		}
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", labelA, labelB)
+
+	for _, h := range makeHunks(lines, context) {
+		aCount := aPos[h.end] - aPos[h.start]
+		bCount := bPos[h.end] - bPos[h.start]
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			hunkRange(aPos[h.start], aCount), hunkRange(bPos[h.start], bCount))
+
+		for _, line := range lines[h.start:h.end] {
+			switch line.Type {
+			case Insert:
+				sb.WriteByte('+')
+			case Delete:
+				sb.WriteByte('-')
+			default:
+				sb.WriteByte(' ')
+			}
+
+			sb.WriteString(line.Text)
+
+			if !strings.HasSuffix(line.Text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+type hunk struct {
+	start, end int
+}
+
+// makeHunks groups the changed lines into hunks surrounded by context lines.
+// Changes that are separated by no more than twice the context are joined
+// into a single hunk.
+func makeHunks(lines []Line, context int) []hunk {
+	var hunks []hunk
+
+	for i, line := range lines {
+		if line.Type == Equal {
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := min(i+context+1, len(lines))
+
+		if n := len(hunks); n > 0 && start <= hunks[n-1].end {
+			hunks[n-1].end = end
+			continue
+		}
+
+		hunks = append(hunks, hunk{start: start, end: end})
+	}
+
+	return hunks
+}
+
+// hunkRange formats the line range of a hunk. Empty ranges refer to the line
+// before the hunk as it is customary for unified diffs.
+func hunkRange(pos, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", pos)
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d", pos+1)
+	}
+
+	return fmt.Sprintf("%d,%d", pos+1, count)
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,101 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	testCases := []struct {
+		name     string
+		a        string
+		b        string
+		context  int
+		expected string
+	}{
+		{
+			name: "equal texts",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+		},
+		{
+			name:    "changed line",
+			a:       "a\nb\nc\n",
+			b:       "a\nB\nc\n",
+			context: 3,
+			expected: `--- a/file
++++ b/file
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+`,
+		},
+		{
+			name:    "distant changes produce separate hunks",
+			a:       "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:       "0\n2\n3\n4\n5\n6\n7\n9\n",
+			context: 1,
+			expected: `--- a/file
++++ b/file
+@@ -1,2 +1,2 @@
+-1
++0
+ 2
+@@ -7,2 +7,2 @@
+ 7
+-8
++9
+`,
+		},
+		{
+			name:    "nearby changes are joined",
+			a:       "1\n2\n3\n4\n",
+			b:       "0\n2\n3\n5\n",
+			context: 1,
+			expected: `--- a/file
++++ b/file
+@@ -1,4 +1,4 @@
+-1
++0
+ 2
+ 3
+-4
++5
+`,
+		},
+		{
+			name:    "new file",
+			a:       "",
+			b:       "a\n",
+			context: 3,
+			expected: `--- a/file
++++ b/file
+@@ -0,0 +1 @@
++a
+`,
+		},
+		{
+			name:    "missing newline at end of file",
+			a:       "a\nb",
+			b:       "a\nb\n",
+			context: 3,
+			expected: `--- a/file
++++ b/file
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Unified(tc.a, tc.b, "a/file", "b/file", tc.context))
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Package forge provides clients to create repositories on code hosting
+// platforms like GitHub and Gitea.
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/martinohmann/kickoff/internal/kickoff"
+)
+
+// AlreadyExistsError is returned if a repository cannot be created because it
+// already exists.
+type AlreadyExistsError string
+
+func (e AlreadyExistsError) Error() string {
+	return fmt.Sprintf("repository %q already exists", string(e))
+}
+
+// Repository holds information about a repository on a forge.
+type Repository struct {
+	// CloneURL is the https URL that can be used to clone and push to the
+	// repository.
+	CloneURL string
+	// HTMLURL is the URL of the repository's web page.
+	HTMLURL string
+	// Owner is the login of the user or organization that owns the
+	// repository.
+	Owner string
+	// Empty is true if the repository does not contain any commits yet.
+	Empty bool
+}
+
+// CreateOptions configures the repository that is created.
+type CreateOptions struct {
+	// Private creates a private repository if true.
+	Private bool
+	// Description is the repository description. Optional.
+	Description string
+}
+
+// Provider is the interface for the API of a code hosting platform.
+type Provider interface {
+	// AuthenticatedUser returns the login of the user the API token belongs
+	// to. The login is only requested once and cached afterwards.
+	AuthenticatedUser(ctx context.Context) (string, error)
+
+	// CreateRepository creates a repository with name for owner, which is
+	// either the authenticated user or an organization. Returns an
+	// AlreadyExistsError if the repository already exists.
+	CreateRepository(ctx context.Context, owner, name string, opts *CreateOptions) (*Repository, error)
+
+	// GetRepository returns the repository with name that belongs to owner.
+	GetRepository(ctx context.Context, owner, name string) (*Repository, error)
+}
+
+// NewProvider creates a new Provider for the forge at host which is
+// configured by config. The provider authenticates API requests using token
+// and makes http requests with httpClient. If httpClient is nil,
+// http.DefaultClient will be used instead.
+func NewProvider(host string, config kickoff.ForgeConfig, token string, httpClient *http.Client) (Provider, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	httpClient = &http.Client{
+		Transport: &tokenTransport{token: token, base: httpClient.Transport},
+		Timeout:   httpClient.Timeout,
+	}
+
+	switch config.Type {
+	case kickoff.ForgeGitHub:
+		return newGitHubProvider(host, config.URL, httpClient)
+	case kickoff.ForgeGitea:
+		return newGiteaProvider(host, config.URL, httpClient), nil
+	default:
+		return nil, fmt.Errorf("unsupported forge type %q", config.Type)
+	}
+}
+
+// tokenTransport adds the API token to the Authorization header of every
+// request.
+type tokenTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if t.token == "" {
+		return base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token "+t.token)
+
+	return base.RoundTrip(req)
+}
@@ -0,0 +1,306 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, handler http.Handler) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	require.NoError(t, json.NewEncoder(w).Encode(v))
+}
+
+func decodeJSON(t *testing.T, r *http.Request) map[string]interface{} {
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	return body
+}
+
+func TestProvider_CreateRepository(t *testing.T) {
+	testCases := []struct {
+		name        string
+		forgeType   kickoff.ForgeType
+		apiPath     string
+		owner       string
+		createPath  string
+		status      int
+		response    interface{}
+		expected    *Repository
+		expectedErr string
+	}{
+		{
+			name:       "github user repository",
+			forgeType:  kickoff.ForgeGitHub,
+			apiPath:    "/api/v3",
+			owner:      "JohnDoe",
+			createPath: "/api/v3/user/repos",
+			status:     http.StatusCreated,
+			response: map[string]string{
+				"clone_url": "https://git.example.com/johndoe/myproject.git",
+				"html_url":  "https://git.example.com/johndoe/myproject",
+			},
+			expected: &Repository{
+				CloneURL: "https://git.example.com/johndoe/myproject.git",
+				HTMLURL:  "https://git.example.com/johndoe/myproject",
+				Empty:    true,
+			},
+		},
+		{
+			name:       "github organization repository",
+			forgeType:  kickoff.ForgeGitHub,
+			apiPath:    "/api/v3",
+			owner:      "acme",
+			createPath: "/api/v3/orgs/acme/repos",
+			status:     http.StatusCreated,
+			response: map[string]string{
+				"clone_url": "https://git.example.com/acme/myproject.git",
+				"html_url":  "https://git.example.com/acme/myproject",
+			},
+			expected: &Repository{
+				CloneURL: "https://git.example.com/acme/myproject.git",
+				HTMLURL:  "https://git.example.com/acme/myproject",
+				Empty:    true,
+			},
+		},
+		{
+			name:       "github repository already exists",
+			forgeType:  kickoff.ForgeGitHub,
+			apiPath:    "/api/v3",
+			owner:      "johndoe",
+			createPath: "/api/v3/user/repos",
+			status:     http.StatusUnprocessableEntity,
+			response: map[string]interface{}{
+				"message": "Repository creation failed.",
+				"errors": []map[string]string{
+					{"resource": "Repository", "code": "custom", "field": "name", "message": "name already exists on this account"},
+				},
+			},
+			expectedErr: `repository "johndoe/myproject" already exists`,
+		},
+		{
+			name:       "gitea user repository",
+			forgeType:  kickoff.ForgeGitea,
+			apiPath:    "/api/v1",
+			owner:      "johndoe",
+			createPath: "/api/v1/user/repos",
+			status:     http.StatusCreated,
+			response: map[string]string{
+				"clone_url": "https://git.example.com/johndoe/myproject.git",
+				"html_url":  "https://git.example.com/johndoe/myproject",
+			},
+			expected: &Repository{
+				CloneURL: "https://git.example.com/johndoe/myproject.git",
+				HTMLURL:  "https://git.example.com/johndoe/myproject",
+				Empty:    true,
+			},
+		},
+		{
+			name:       "gitea organization repository",
+			forgeType:  kickoff.ForgeGitea,
+			apiPath:    "/api/v1",
+			owner:      "acme",
+			createPath: "/api/v1/orgs/acme/repos",
+			status:     http.StatusCreated,
+			response: map[string]string{
+				"clone_url": "https://git.example.com/acme/myproject.git",
+				"html_url":  "https://git.example.com/acme/myproject",
+			},
+			expected: &Repository{
+				CloneURL: "https://git.example.com/acme/myproject.git",
+				HTMLURL:  "https://git.example.com/acme/myproject",
+				Empty:    true,
+			},
+		},
+		{
+			name:        "gitea repository already exists",
+			forgeType:   kickoff.ForgeGitea,
+			apiPath:     "/api/v1",
+			owner:       "johndoe",
+			createPath:  "/api/v1/user/repos",
+			status:      http.StatusConflict,
+			response:    map[string]string{"message": "The repository with the same name already exists."},
+			expectedErr: `repository "johndoe/myproject" already exists`,
+		},
+		{
+			name:        "gitea error",
+			forgeType:   kickoff.ForgeGitea,
+			apiPath:     "/api/v1",
+			owner:       "acme",
+			createPath:  "/api/v1/orgs/acme/repos",
+			status:      http.StatusForbidden,
+			response:    map[string]string{"message": "user is not allowed to create repositories"},
+			expectedErr: "403 user is not allowed to create repositories",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var userRequests int
+
+			mux := http.NewServeMux()
+			mux.HandleFunc(tc.apiPath+"/user", func(w http.ResponseWriter, r *http.Request) {
+				userRequests++
+				writeJSON(t, w, http.StatusOK, map[string]string{"login": "johndoe"})
+			})
+			mux.HandleFunc(tc.createPath, func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodPost, r.Method)
+
+				body := decodeJSON(t, r)
+				assert.Equal(t, "myproject", body["name"])
+				assert.Equal(t, true, body["private"])
+
+				writeJSON(t, w, tc.status, tc.response)
+			})
+
+			server := newTestServer(t, mux)
+
+			config := kickoff.ForgeConfig{Type: tc.forgeType, URL: server.URL + tc.apiPath}
+
+			provider, err := NewProvider("git.example.com", config, "secret", server.Client())
+			require.NoError(t, err)
+
+			login, err := provider.AuthenticatedUser(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "johndoe", login)
+
+			repo, err := provider.CreateRepository(context.Background(), tc.owner, "myproject", &CreateOptions{Private: true})
+			assert.Equal(t, 1, userRequests, "login must be cached")
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, repo)
+		})
+	}
+}
+
+func TestProvider_GetRepository(t *testing.T) {
+	testCases := []struct {
+		name      string
+		forgeType kickoff.ForgeType
+		apiPath   string
+		handlers  map[string]interface{}
+		expected  *Repository
+	}{
+		{
+			name:      "github empty repository",
+			forgeType: kickoff.ForgeGitHub,
+			apiPath:   "/api/v3",
+			handlers: map[string]interface{}{
+				"/repos/acme/myproject": map[string]interface{}{
+					"clone_url": "https://git.example.com/acme/myproject.git",
+					"html_url":  "https://git.example.com/acme/myproject",
+					"owner":     map[string]string{"login": "acme"},
+				},
+				"/repos/acme/myproject/branches": []interface{}{},
+			},
+			expected: &Repository{
+				CloneURL: "https://git.example.com/acme/myproject.git",
+				HTMLURL:  "https://git.example.com/acme/myproject",
+				Owner:    "acme",
+				Empty:    true,
+			},
+		},
+		{
+			name:      "github repository with branches",
+			forgeType: kickoff.ForgeGitHub,
+			apiPath:   "/api/v3",
+			handlers: map[string]interface{}{
+				"/repos/acme/myproject": map[string]interface{}{
+					"clone_url": "https://git.example.com/acme/myproject.git",
+					"html_url":  "https://git.example.com/acme/myproject",
+					"owner":     map[string]string{"login": "acme"},
+				},
+				"/repos/acme/myproject/branches": []map[string]string{{"name": "main"}},
+			},
+			expected: &Repository{
+				CloneURL: "https://git.example.com/acme/myproject.git",
+				HTMLURL:  "https://git.example.com/acme/myproject",
+				Owner:    "acme",
+			},
+		},
+		{
+			name:      "gitea repository",
+			forgeType: kickoff.ForgeGitea,
+			apiPath:   "/api/v1",
+			handlers: map[string]interface{}{
+				"/repos/acme/myproject": map[string]interface{}{
+					"clone_url": "https://git.example.com/acme/myproject.git",
+					"html_url":  "https://git.example.com/acme/myproject",
+					"owner":     map[string]string{"login": "acme"},
+					"empty":     true,
+				},
+			},
+			expected: &Repository{
+				CloneURL: "https://git.example.com/acme/myproject.git",
+				HTMLURL:  "https://git.example.com/acme/myproject",
+				Owner:    "acme",
+				Empty:    true,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+
+			for path, response := range tc.handlers {
+				response := response
+				mux.HandleFunc(tc.apiPath+path, func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, http.MethodGet, r.Method)
+					writeJSON(t, w, http.StatusOK, response)
+				})
+			}
+
+			server := newTestServer(t, mux)
+
+			config := kickoff.ForgeConfig{Type: tc.forgeType, URL: server.URL + tc.apiPath}
+
+			provider, err := NewProvider("git.example.com", config, "secret", server.Client())
+			require.NoError(t, err)
+
+			repo, err := provider.GetRepository(context.Background(), "acme", "myproject")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, repo)
+		})
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	t.Run("derives github enterprise API URL from host", func(t *testing.T) {
+		provider, err := NewProvider("git.example.com", kickoff.ForgeConfig{Type: kickoff.ForgeGitHub}, "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "https://git.example.com/api/v3/", provider.(*githubProvider).client.BaseURL.String())
+	})
+
+	t.Run("derives gitea API URL from host", func(t *testing.T) {
+		provider, err := NewProvider("git.example.com", kickoff.ForgeConfig{Type: kickoff.ForgeGitea}, "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "https://git.example.com/api/v1", provider.(*giteaProvider).baseURL)
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := NewProvider("git.example.com", kickoff.ForgeConfig{Type: "gitlab"}, "", nil)
+		require.EqualError(t, err, `unsupported forge type "gitlab"`)
+	})
+}
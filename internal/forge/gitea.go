@@ -0,0 +1,178 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type giteaProvider struct {
+	client  *http.Client
+	baseURL string
+	login   string
+}
+
+func newGiteaProvider(host, apiURL string, httpClient *http.Client) *giteaProvider {
+	if apiURL == "" {
+		apiURL = "https://" + host + "/api/v1"
+	}
+
+	return &giteaProvider{client: httpClient, baseURL: strings.TrimSuffix(apiURL, "/")}
+}
+
+type giteaUser struct {
+	Login string `json:"login"`
+}
+
+type giteaCreateRepoOptions struct {
+	Name        string `json:"name"`
+	Private     bool   `json:"private"`
+	Description string `json:"description,omitempty"`
+}
+
+type giteaRepository struct {
+	CloneURL string    `json:"clone_url"`
+	HTMLURL  string    `json:"html_url"`
+	Owner    giteaUser `json:"owner"`
+	Empty    bool      `json:"empty"`
+}
+
+func (r *giteaRepository) toRepository() *Repository {
+	return &Repository{
+		CloneURL: r.CloneURL,
+		HTMLURL:  r.HTMLURL,
+		Owner:    r.Owner.Login,
+		Empty:    r.Empty,
+	}
+}
+
+// AuthenticatedUser implements Provider.
+func (p *giteaProvider) AuthenticatedUser(ctx context.Context) (string, error) {
+	if p.login != "" {
+		return p.login, nil
+	}
+
+	var user giteaUser
+
+	if err := p.do(ctx, http.MethodGet, "/user", nil, &user); err != nil {
+		return "", err
+	}
+
+	p.login = user.Login
+
+	return p.login, nil
+}
+
+// CreateRepository implements Provider.
+func (p *giteaProvider) CreateRepository(ctx context.Context, owner, name string, opts *CreateOptions) (*Repository, error) {
+	log.WithField("owner", owner).WithField("name", name).Debug("creating gitea repository")
+
+	login, err := p.AuthenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	path := "/user/repos"
+	if !strings.EqualFold(owner, login) {
+		path = "/orgs/" + url.PathEscape(owner) + "/repos"
+	}
+
+	body := &giteaCreateRepoOptions{
+		Name:        name,
+		Private:     opts.Private,
+		Description: opts.Description,
+	}
+
+	var repo giteaRepository
+
+	err = p.do(ctx, http.MethodPost, path, body, &repo)
+	if err != nil {
+		var errResp *giteaErrorResponse
+		if errors.As(err, &errResp) && errResp.StatusCode == http.StatusConflict {
+			return nil, AlreadyExistsError(owner + "/" + name)
+		}
+
+		return nil, err
+	}
+
+	created := repo.toRepository()
+	// Newly created repositories are always empty.
+	created.Empty = true
+
+	return created, nil
+}
+
+// GetRepository implements Provider.
+func (p *giteaProvider) GetRepository(ctx context.Context, owner, name string) (*Repository, error) {
+	var repo giteaRepository
+
+	path := "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name)
+
+	if err := p.do(ctx, http.MethodGet, path, nil, &repo); err != nil {
+		return nil, err
+	}
+
+	return repo.toRepository(), nil
+}
+
+// giteaErrorResponse is returned for API responses with a non-2xx status
+// code.
+type giteaErrorResponse struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Message    string `json:"message"`
+}
+
+func (e *giteaErrorResponse) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, e.Message)
+}
+
+func (p *giteaProvider) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body bytes.Buffer
+
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, &body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		errResp := &giteaErrorResponse{
+			Method:     method,
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+		}
+
+		// The body is only decoded for a more helpful message.
+		_ = json.NewDecoder(resp.Body).Decode(errResp)
+
+		return errResp
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
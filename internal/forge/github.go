@@ -0,0 +1,128 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v28/github"
+	log "github.com/sirupsen/logrus"
+)
+
+// githubHost is the host of the public GitHub instance. All other hosts are
+// treated as GitHub Enterprise instances.
+const githubHost = "github.com"
+
+type githubProvider struct {
+	client *github.Client
+	login  string
+}
+
+func newGitHubProvider(host, apiURL string, httpClient *http.Client) (*githubProvider, error) {
+	if apiURL == "" {
+		if host == githubHost {
+			return &githubProvider{client: github.NewClient(httpClient)}, nil
+		}
+
+		apiURL = "https://" + host + "/api/v3/"
+	}
+
+	client, err := github.NewEnterpriseClient(apiURL, apiURL, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &githubProvider{client: client}, nil
+}
+
+// AuthenticatedUser implements Provider.
+func (p *githubProvider) AuthenticatedUser(ctx context.Context) (string, error) {
+	if p.login != "" {
+		return p.login, nil
+	}
+
+	user, _, err := p.client.Users.Get(ctx, "")
+	if err != nil {
+		return "", err
+	}
+
+	p.login = user.GetLogin()
+
+	return p.login, nil
+}
+
+// CreateRepository implements Provider.
+func (p *githubProvider) CreateRepository(ctx context.Context, owner, name string, opts *CreateOptions) (*Repository, error) {
+	log.WithField("owner", owner).WithField("name", name).Debug("creating github repository")
+
+	login, err := p.AuthenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	org := owner
+	if strings.EqualFold(owner, login) {
+		org = ""
+	}
+
+	repo, _, err := p.client.Repositories.Create(ctx, org, &github.Repository{
+		Name:        github.String(name),
+		Private:     github.Bool(opts.Private),
+		Description: github.String(opts.Description),
+	})
+	if err != nil {
+		if isAlreadyExists(err) {
+			return nil, AlreadyExistsError(owner + "/" + name)
+		}
+
+		return nil, err
+	}
+
+	// Newly created repositories are always empty.
+	return &Repository{
+		CloneURL: repo.GetCloneURL(),
+		HTMLURL:  repo.GetHTMLURL(),
+		Owner:    repo.GetOwner().GetLogin(),
+		Empty:    true,
+	}, nil
+}
+
+// GetRepository implements Provider.
+func (p *githubProvider) GetRepository(ctx context.Context, owner, name string) (*Repository, error) {
+	repo, _, err := p.client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// The GitHub API does not tell whether a repository is empty, but empty
+	// repositories do not have any branches.
+	branches, _, err := p.client.Repositories.ListBranches(ctx, owner, name, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Repository{
+		CloneURL: repo.GetCloneURL(),
+		HTMLURL:  repo.GetHTMLURL(),
+		Owner:    repo.GetOwner().GetLogin(),
+		Empty:    len(branches) == 0,
+	}, nil
+}
+
+// isAlreadyExists returns true if err is the validation error returned by the
+// GitHub API if a repository with the same name exists.
+func isAlreadyExists(err error) bool {
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+
+	for _, e := range errResp.Errors {
+		if strings.Contains(e.Message, "already exists") {
+			return true
+		}
+	}
+
+	return false
+}
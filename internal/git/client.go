@@ -4,6 +4,10 @@ package git
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 
 	git "github.com/go-git/go-git/v5"
 )
@@ -63,3 +67,68 @@ func (*client) Init(path string) (Repository, error) {
 
 	return NewRepository(r), nil
 }
+
+// FindRepoRoot returns the root directory of the git repository that contains
+// path. The path itself does not need to exist yet. Returns
+// ErrRepositoryNotExists if none of the parent directories of path contains a
+// .git directory or file.
+func FindRepoRoot(path string) (string, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if _, err := os.Lstat(filepath.Join(dir, git.GitDirName)); err == nil {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrRepositoryNotExists
+		}
+
+		dir = parent
+	}
+}
+
+// RemoteRepoName returns the name of the repository that the remote with name
+// of the git repository at dir points to, e.g. "kickoff" for the remote url
+// git@github.com:martinohmann/kickoff.git. Returns an empty string if the
+// repository does not have such a remote or if dir does not contain a valid
+// repository.
+func RemoteRepoName(dir, name string) (string, error) {
+	repo, err := git.PlainOpen(dir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	remote, err := repo.Remote(name)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", nil
+	}
+
+	return repoNameFromURL(urls[0]), nil
+}
+
+// repoNameFromURL returns the last path element of the repository url
+// without the .git suffix. It supports URLs as well as scp-like addresses,
+// e.g. git@github.com:owner/repo.git.
+func repoNameFromURL(url string) string {
+	url = strings.TrimSuffix(strings.TrimRight(url, "/"), ".git")
+
+	if i := strings.LastIndexAny(url, "/:\\"); i >= 0 {
+		url = url[i+1:]
+	}
+
+	return url
+}
@@ -6,5 +6,6 @@ import git "github.com/go-git/go-git/v5"
 var (
 	ErrRepositoryAlreadyExists = git.ErrRepositoryAlreadyExists
 	ErrRepositoryNotExists     = git.ErrRepositoryNotExists
+	ErrRemoteExists            = git.ErrRemoteExists
 	NoErrAlreadyUpToDate       = git.NoErrAlreadyUpToDate
 )
@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -65,3 +66,51 @@ func (r *FakeRepository) Checkout(hash plumbing.Hash) error {
 	args := r.Called(hash)
 	return args.Error(0)
 }
+
+// Head implements Repository.
+func (r *FakeRepository) Head() (*plumbing.Reference, error) {
+	args := r.Called()
+	if ref, ok := args.Get(0).(*plumbing.Reference); ok {
+		return ref, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// Export implements Repository.
+func (r *FakeRepository) Export(hash plumbing.Hash, dir string) error {
+	args := r.Called(hash, dir)
+	return args.Error(0)
+}
+
+// AddAll implements Repository.
+func (r *FakeRepository) AddAll() error {
+	args := r.Called()
+	return args.Error(0)
+}
+
+// Commit implements Repository.
+func (r *FakeRepository) Commit(message string) (plumbing.Hash, error) {
+	args := r.Called(message)
+	if hash, ok := args.Get(0).(plumbing.Hash); ok {
+		return hash, args.Error(1)
+	}
+	return plumbing.ZeroHash, args.Error(1)
+}
+
+// CreateRemote implements Repository.
+func (r *FakeRepository) CreateRemote(name, url string) error {
+	args := r.Called(name, url)
+	return args.Error(0)
+}
+
+// SetHead implements Repository.
+func (r *FakeRepository) SetHead(branch string) error {
+	args := r.Called(branch)
+	return args.Error(0)
+}
+
+// Push implements Repository.
+func (r *FakeRepository) Push(ctx context.Context, remote string, auth transport.AuthMethod, refSpecs ...config.RefSpec) error {
+	args := r.Called(ctx, remote, auth, refSpecs)
+	return args.Error(0)
+}
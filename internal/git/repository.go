@@ -2,10 +2,16 @@ package git
 
 import (
 	"context"
+	"io"
+	"os"
+	"path/filepath"
 
 	git "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 )
 
 // Repository is the interface for a git repository.
@@ -24,6 +30,34 @@ type Repository interface {
 	// Checkout checks out the commit referenced by the provided hash. The
 	// checkout is performed in force mode to throw away local changes.
 	Checkout(hash plumbing.Hash) error
+
+	// Head returns the reference where HEAD is pointing to.
+	Head() (*plumbing.Reference, error)
+
+	// Export writes the files of the commit referenced by the provided hash
+	// into dir without touching the repository's worktree or HEAD.
+	Export(hash plumbing.Hash, dir string) error
+
+	// AddAll stages all changes in the worktree. Files ignored via
+	// .gitignore are not staged.
+	AddAll() error
+
+	// Commit records the staged changes in a new commit with message. Author
+	// and committer are read from the git config.
+	Commit(message string) (plumbing.Hash, error)
+
+	// CreateRemote adds a remote with name that points to url.
+	CreateRemote(name, url string) error
+
+	// SetHead points HEAD to the branch with name. In a repository without
+	// commits this sets the name of the initial branch.
+	SetHead(branch string) error
+
+	// Push pushes refSpecs to the remote with name using auth.
+	//
+	// Returns nil if the operation is successful, NoErrAlreadyUpToDate if
+	// there are no changes to be pushed, or an error.
+	Push(ctx context.Context, remote string, auth transport.AuthMethod, refSpecs ...config.RefSpec) error
 }
 
 // NewRepository creates a new Repository from given go-git repository.
@@ -52,3 +86,94 @@ func (r *repository) Checkout(hash plumbing.Hash) error {
 		Force: true,
 	})
 }
+
+func (r *repository) AddAll() error {
+	worktree, err := r.Worktree()
+	if err != nil {
+		return err
+	}
+
+	return worktree.AddWithOptions(&git.AddOptions{All: true})
+}
+
+func (r *repository) Commit(message string) (plumbing.Hash, error) {
+	worktree, err := r.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return worktree.Commit(message, &git.CommitOptions{})
+}
+
+func (r *repository) CreateRemote(name, url string) error {
+	_, err := r.Repository.CreateRemote(&config.RemoteConfig{
+		Name: name,
+		URLs: []string{url},
+	})
+	return err
+}
+
+func (r *repository) SetHead(branch string) error {
+	ref := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName(branch))
+
+	return r.Storer.SetReference(ref)
+}
+
+func (r *repository) Push(ctx context.Context, remote string, auth transport.AuthMethod, refSpecs ...config.RefSpec) error {
+	return r.Repository.PushContext(ctx, &git.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   refSpecs,
+		Auth:       auth,
+	})
+}
+
+func (r *repository) Export(hash plumbing.Hash, dir string) error {
+	commit, err := r.CommitObject(hash)
+	if err != nil {
+		return err
+	}
+
+	files, err := commit.Files()
+	if err != nil {
+		return err
+	}
+
+	return files.ForEach(func(f *object.File) error {
+		return exportFile(f, filepath.Join(dir, filepath.FromSlash(f.Name)))
+	})
+}
+
+func exportFile(f *object.File, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if f.Mode == filemode.Symlink {
+		target, err := f.Contents()
+		if err != nil {
+			return err
+		}
+
+		return os.Symlink(target, path)
+	}
+
+	mode, err := f.Mode.ToOSFileMode()
+	if err != nil {
+		return err
+	}
+
+	r, err := f.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	_, err = io.Copy(w, r)
+	return err
+}
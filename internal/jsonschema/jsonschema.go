@@ -0,0 +1,504 @@
+// Package jsonschema validates values against JSON schemas. It supports the
+// commonly used subset of JSON Schema draft-07: type, enum, const, the
+// numeric, string, array and object assertions, allOf, anyOf, oneOf, not and
+// local $ref pointers into definitions or $defs. Annotations like title,
+// description and default are ignored. Schemas using any other keyword, e.g.
+// format, are rejected instead of being silently accepted.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var types = []string{"null", "boolean", "object", "array", "number", "integer", "string"}
+
+// keywords contains all supported schema keywords.
+var keywords = []string{
+	"type", "enum", "const",
+	"multipleOf", "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum",
+	"minLength", "maxLength", "pattern",
+	"items", "minItems", "maxItems", "uniqueItems",
+	"required", "properties", "patternProperties", "additionalProperties", "minProperties", "maxProperties",
+	"allOf", "anyOf", "oneOf", "not",
+	"$ref", "definitions", "$defs",
+}
+
+// annotations contains keywords that are allowed, but do not affect
+// validation.
+var annotations = []string{"$schema", "$id", "$comment", "title", "description", "default", "examples", "readOnly", "writeOnly"}
+
+// Schema is a compiled JSON schema.
+type Schema struct {
+	raw  json.RawMessage
+	root *node
+}
+
+// node is a compiled schema or subschema.
+type node struct {
+	// always is non-nil for boolean schemas.
+	always *bool
+
+	types    []string
+	enum     []interface{}
+	constant interface{}
+	hasConst bool
+
+	multipleOf       *float64
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	items       *node
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	required             []string
+	properties           map[string]*node
+	patternProperties    []*patternProperty
+	additionalProperties *node
+	minProperties        *int
+	maxProperties        *int
+
+	allOf []*node
+	anyOf []*node
+	oneOf []*node
+	not   *node
+
+	ref     string
+	refNode *node
+}
+
+type patternProperty struct {
+	pattern *regexp.Regexp
+	schema  *node
+}
+
+// Compile parses the JSON schema in data. Returns an error if data is not a
+// valid schema, uses unsupported features or contains unresolvable $ref
+// pointers.
+func Compile(data []byte) (*Schema, error) {
+	var v interface{}
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	c := &compiler{nodes: make(map[string]*node)}
+
+	root, err := c.compile(v, "#")
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	if err := c.resolveRefs(); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, err
+	}
+
+	return &Schema{raw: buf.Bytes(), root: root}, nil
+}
+
+// MarshalJSON implements json.Marshaler. It returns the schema source.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	return s.raw, nil
+}
+
+// String returns the indented schema source.
+func (s *Schema) String() string {
+	var buf bytes.Buffer
+
+	if err := json.Indent(&buf, s.raw, "", "  "); err != nil {
+		return string(s.raw)
+	}
+
+	return buf.String()
+}
+
+type compiler struct {
+	// nodes maps the JSON pointers of all compiled (sub)schemas to the
+	// compiled nodes. It is used to resolve $ref.
+	nodes map[string]*node
+	refs  []*node
+}
+
+func (c *compiler) compile(v interface{}, ptr string) (*node, error) {
+	n := &node{}
+
+	c.nodes[ptr] = n
+
+	switch s := v.(type) {
+	case bool:
+		n.always = &s
+		return n, nil
+	case map[string]interface{}:
+		return n, c.compileKeywords(n, s, ptr)
+	default:
+		return nil, fmt.Errorf("%s: schema must be an object or a bool, got %T", ptr, v)
+	}
+}
+
+func (c *compiler) compileKeywords(n *node, s map[string]interface{}, ptr string) (err error) {
+	if err := checkKeywords(s, ptr); err != nil {
+		return err
+	}
+
+	for _, key := range []string{"definitions", "$defs"} {
+		if _, err := c.compileMap(s, key, ptr); err != nil {
+			return err
+		}
+	}
+
+	if n.types, err = compileTypes(s["type"], ptr); err != nil {
+		return err
+	}
+
+	if enum, ok := s["enum"]; ok {
+		if n.enum, ok = enum.([]interface{}); !ok {
+			return fmt.Errorf("%s/enum: must be an array", ptr)
+		}
+	}
+
+	n.constant, n.hasConst = s["const"]
+
+	for key, p := range map[string]**float64{
+		"multipleOf":       &n.multipleOf,
+		"minimum":          &n.minimum,
+		"maximum":          &n.maximum,
+		"exclusiveMinimum": &n.exclusiveMinimum,
+		"exclusiveMaximum": &n.exclusiveMaximum,
+	} {
+		if *p, err = compileNumber(s, key, ptr); err != nil {
+			return err
+		}
+	}
+
+	for key, p := range map[string]**int{
+		"minLength":     &n.minLength,
+		"maxLength":     &n.maxLength,
+		"minItems":      &n.minItems,
+		"maxItems":      &n.maxItems,
+		"minProperties": &n.minProperties,
+		"maxProperties": &n.maxProperties,
+	} {
+		if *p, err = compileCount(s, key, ptr); err != nil {
+			return err
+		}
+	}
+
+	if n.pattern, err = compilePattern(s, "pattern", ptr); err != nil {
+		return err
+	}
+
+	if items, ok := s["items"]; ok {
+		if _, isArray := items.([]interface{}); isArray {
+			return fmt.Errorf("%s/items: arrays of schemas are not supported", ptr)
+		}
+
+		if n.items, err = c.compile(items, ptr+"/items"); err != nil {
+			return err
+		}
+	}
+
+	if unique, ok := s["uniqueItems"]; ok {
+		if n.uniqueItems, ok = unique.(bool); !ok {
+			return fmt.Errorf("%s/uniqueItems: must be a bool", ptr)
+		}
+	}
+
+	if n.required, err = compileStrings(s, "required", ptr); err != nil {
+		return err
+	}
+
+	if n.properties, err = c.compileMap(s, "properties", ptr); err != nil {
+		return err
+	}
+
+	if err := c.compilePatternProperties(n, s, ptr); err != nil {
+		return err
+	}
+
+	if additional, ok := s["additionalProperties"]; ok {
+		if n.additionalProperties, err = c.compile(additional, ptr+"/additionalProperties"); err != nil {
+			return err
+		}
+	}
+
+	for key, p := range map[string]*[]*node{"allOf": &n.allOf, "anyOf": &n.anyOf, "oneOf": &n.oneOf} {
+		if *p, err = c.compileList(s, key, ptr); err != nil {
+			return err
+		}
+	}
+
+	if not, ok := s["not"]; ok {
+		if n.not, err = c.compile(not, ptr+"/not"); err != nil {
+			return err
+		}
+	}
+
+	if ref, ok := s["$ref"]; ok {
+		if n.ref, ok = ref.(string); !ok {
+			return fmt.Errorf("%s/$ref: must be a string", ptr)
+		}
+
+		c.refs = append(c.refs, n)
+	}
+
+	return nil
+}
+
+func (c *compiler) compileMap(s map[string]interface{}, key, ptr string) (map[string]*node, error) {
+	v, ok := s[key]
+	if !ok {
+		return nil, nil
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s/%s: must be an object", ptr, key)
+	}
+
+	nodes := make(map[string]*node, len(m))
+
+	for name, schema := range m {
+		n, err := c.compile(schema, ptr+"/"+key+"/"+escapePointer(name))
+		if err != nil {
+			return nil, err
+		}
+
+		nodes[name] = n
+	}
+
+	return nodes, nil
+}
+
+func (c *compiler) compilePatternProperties(n *node, s map[string]interface{}, ptr string) error {
+	nodes, err := c.compileMap(s, "patternProperties", ptr)
+	if err != nil {
+		return err
+	}
+
+	patterns := make([]string, 0, len(nodes))
+	for pattern := range nodes {
+		patterns = append(patterns, pattern)
+	}
+
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("%s/patternProperties: invalid pattern %q: %w", ptr, pattern, err)
+		}
+
+		n.patternProperties = append(n.patternProperties, &patternProperty{pattern: re, schema: nodes[pattern]})
+	}
+
+	return nil
+}
+
+func (c *compiler) compileList(s map[string]interface{}, key, ptr string) ([]*node, error) {
+	v, ok := s[key]
+	if !ok {
+		return nil, nil
+	}
+
+	list, ok := v.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("%s/%s: must be a non-empty array", ptr, key)
+	}
+
+	nodes := make([]*node, len(list))
+
+	for i, schema := range list {
+		n, err := c.compile(schema, fmt.Sprintf("%s/%s/%d", ptr, key, i))
+		if err != nil {
+			return nil, err
+		}
+
+		nodes[i] = n
+	}
+
+	return nodes, nil
+}
+
+// resolveRefs resolves the $ref pointers of all nodes. Only pointers into
+// the same document are supported.
+func (c *compiler) resolveRefs() error {
+	for _, n := range c.refs {
+		if !strings.HasPrefix(n.ref, "#") {
+			return fmt.Errorf("$ref %q: only references within the schema are supported", n.ref)
+		}
+
+		target, ok := c.nodes[n.ref]
+		if !ok {
+			return fmt.Errorf("$ref %q: no such schema", n.ref)
+		}
+
+		n.refNode = target
+	}
+
+	// A chain of references that leads back to itself would never terminate
+	// during validation.
+	for _, n := range c.refs {
+		seen := map[*node]bool{n: true}
+
+		for next := n.refNode; next.refNode != nil; next = next.refNode {
+			if seen[next] {
+				return fmt.Errorf("$ref %q: circular reference", n.ref)
+			}
+
+			seen[next] = true
+		}
+	}
+
+	return nil
+}
+
+// checkKeywords returns an error for the first keyword of s that is neither
+// supported nor an annotation.
+func checkKeywords(s map[string]interface{}, ptr string) error {
+	keys := make([]string, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !contains(keywords, key) && !contains(annotations, key) {
+			return fmt.Errorf("%s: unsupported keyword %q", ptr, key)
+		}
+	}
+
+	return nil
+}
+
+func compileTypes(v interface{}, ptr string) ([]string, error) {
+	var names []string
+
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		names = []string{t}
+	case []interface{}:
+		for _, name := range t {
+			s, ok := name.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s/type: must be a string or an array of strings", ptr)
+			}
+
+			names = append(names, s)
+		}
+	default:
+		return nil, fmt.Errorf("%s/type: must be a string or an array of strings", ptr)
+	}
+
+	for _, name := range names {
+		if !contains(types, name) {
+			return nil, fmt.Errorf("%s/type: invalid type %q, must be one of %v", ptr, name, types)
+		}
+	}
+
+	return names, nil
+}
+
+func compileNumber(s map[string]interface{}, key, ptr string) (*float64, error) {
+	v, ok := s[key]
+	if !ok {
+		return nil, nil
+	}
+
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("%s/%s: must be a number", ptr, key)
+	}
+
+	return &f, nil
+}
+
+func compileCount(s map[string]interface{}, key, ptr string) (*int, error) {
+	f, err := compileNumber(s, key, ptr)
+	if err != nil || f == nil {
+		return nil, err
+	}
+
+	if *f < 0 || !isInteger(*f) {
+		return nil, fmt.Errorf("%s/%s: must be a non-negative integer", ptr, key)
+	}
+
+	i := int(*f)
+
+	return &i, nil
+}
+
+func compilePattern(s map[string]interface{}, key, ptr string) (*regexp.Regexp, error) {
+	v, ok := s[key]
+	if !ok {
+		return nil, nil
+	}
+
+	pattern, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s/%s: must be a string", ptr, key)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s/%s: %w", ptr, key, err)
+	}
+
+	return re, nil
+}
+
+func compileStrings(s map[string]interface{}, key, ptr string) ([]string, error) {
+	v, ok := s[key]
+	if !ok {
+		return nil, nil
+	}
+
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s/%s: must be an array of strings", ptr, key)
+	}
+
+	strs := make([]string, len(list))
+
+	for i, item := range list {
+		if strs[i], ok = item.(string); !ok {
+			return nil, fmt.Errorf("%s/%s: must be an array of strings", ptr, key)
+		}
+	}
+
+	return strs, nil
+}
+
+// escapePointer escapes name for use as a JSON pointer reference token.
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,194 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["replicas"],
+  "properties": {
+    "replicas": {"type": "integer", "minimum": 1},
+    "image": {
+      "type": "object",
+      "properties": {
+        "repository": {"type": "string", "pattern": "^[a-z0-9./-]+$"},
+        "pullPolicy": {"enum": ["Always", "IfNotPresent"]}
+      },
+      "additionalProperties": false
+    },
+    "ports": {
+      "type": "array",
+      "items": {"$ref": "#/definitions/port"},
+      "uniqueItems": true
+    },
+    "labels": {
+      "type": "object",
+      "additionalProperties": {"type": "string", "maxLength": 5}
+    },
+    "storage": {
+      "oneOf": [
+        {"type": "null"},
+        {"type": "string", "minLength": 1}
+      ]
+    }
+  },
+  "definitions": {
+    "port": {"type": "integer", "exclusiveMinimum": 0, "maximum": 65535}
+  }
+}`
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := Compile([]byte(testSchema))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected []string
+	}{
+		{
+			name: "valid",
+			value: map[string]interface{}{
+				"replicas": int64(3),
+				"image":    map[string]interface{}{"repository": "nginx", "pullPolicy": "Always"},
+				"ports":    []interface{}{80, 443},
+				"labels":   map[string]interface{}{"app": "web"},
+				"storage":  nil,
+			},
+		},
+		{
+			name:     "wrong type",
+			value:    map[string]interface{}{"replicas": "three"},
+			expected: []string{"$.replicas: expected integer, got string"},
+		},
+		{
+			name:     "missing required value",
+			value:    map[string]interface{}{},
+			expected: []string{"$.replicas: required value is missing"},
+		},
+		{
+			name: "nested violations",
+			value: map[string]interface{}{
+				"replicas": 0.5,
+				"image":    map[string]interface{}{"repository": "NGINX", "pullPolicy": "Never", "tag": "latest"},
+				"ports":    []interface{}{80, 0, 80},
+				"labels":   map[string]interface{}{"app.kubernetes.io/name": "webserver"},
+				"storage":  "",
+			},
+			expected: []string{
+				`$.image.pullPolicy: must be one of ["Always","IfNotPresent"], got "Never"`,
+				`$.image.repository: must match pattern ^[a-z0-9./-]+$, got "NGINX"`,
+				"$.image.tag: property is not allowed",
+				`$.labels["app.kubernetes.io/name"]: must be at most 5 characters long, got 9`,
+				"$.ports: items must be unique, item 2 is a duplicate",
+				"$.ports[1]: must be > 0, got 0",
+				"$.replicas: expected integer, got number",
+				"$.storage: must match exactly one of the oneOf schemas, matched 0",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			violations, err := schema.Validate(test.value)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, v := range violations {
+				messages = append(messages, v.Error())
+			}
+
+			assert.Equal(t, test.expected, messages)
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name        string
+		schema      string
+		expectedErr string
+	}{
+		{
+			name:   "boolean schema",
+			schema: `true`,
+		},
+		{
+			name:        "invalid JSON",
+			schema:      `{`,
+			expectedErr: "invalid JSON schema: unexpected end of JSON input",
+		},
+		{
+			name:        "invalid type",
+			schema:      `{"properties": {"foo": {"type": "float"}}}`,
+			expectedErr: `invalid JSON schema: #/properties/foo/type: invalid type "float", must be one of [null boolean object array number integer string]`,
+		},
+		{
+			name:        "invalid pattern",
+			schema:      `{"pattern": "["}`,
+			expectedErr: "invalid JSON schema: #/pattern: error parsing regexp: missing closing ]: `[`",
+		},
+		{
+			name:        "unresolvable ref",
+			schema:      `{"$ref": "#/definitions/missing"}`,
+			expectedErr: `invalid JSON schema: $ref "#/definitions/missing": no such schema`,
+		},
+		{
+			name:        "remote ref",
+			schema:      `{"$ref": "https://example.com/schema.json"}`,
+			expectedErr: `invalid JSON schema: $ref "https://example.com/schema.json": only references within the schema are supported`,
+		},
+		{
+			name:        "circular ref",
+			schema:      `{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}}`,
+			expectedErr: `circular reference`,
+		},
+		{
+			name:   "annotations",
+			schema: `{"$schema": "http://json-schema.org/draft-07/schema#", "title": "Values", "properties": {"port": {"description": "The port", "default": 80}}}`,
+		},
+		{
+			name:        "unsupported keyword",
+			schema:      `{"properties": {"email": {"type": "string", "format": "email"}}}`,
+			expectedErr: `invalid JSON schema: #/properties/email: unsupported keyword "format"`,
+		},
+		{
+			name:        "unsupported keyword in definitions",
+			schema:      `{"definitions": {"a": {"dependencies": {"b": ["c"]}}}}`,
+			expectedErr: `invalid JSON schema: #/definitions/a: unsupported keyword "dependencies"`,
+		},
+		{
+			name:        "tuple items",
+			schema:      `{"items": [{"type": "string"}]}`,
+			expectedErr: "invalid JSON schema: #/items: arrays of schemas are not supported",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Compile([]byte(test.schema))
+			if test.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestSchema_MarshalJSON(t *testing.T) {
+	schema, err := Compile([]byte(`{ "type": "object" }`))
+	require.NoError(t, err)
+
+	buf, err := schema.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"object"}`, string(buf))
+	assert.Equal(t, "{\n  \"type\": \"object\"\n}", schema.String())
+}
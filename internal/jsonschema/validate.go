@@ -0,0 +1,340 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+)
+
+// Violation describes a value that does not conform to a schema.
+type Violation struct {
+	// Path is the JSON path of the value, e.g. `$.service.ports[0]`.
+	Path string
+	// Message describes the violation.
+	Message string
+}
+
+// Error implements the error interface.
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// Validate validates value against the schema and returns all violations in
+// a stable order. The value is converted into its JSON representation first,
+// so that e.g. integers of different Go types are treated the same. Returns an
+// error if that conversion fails.
+func (s *Schema) Validate(value interface{}) ([]*Violation, error) {
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return nil, err
+	}
+
+	return validate(s.root, v, "$"), nil
+}
+
+func validate(n *node, value interface{}, path string) []*Violation {
+	if n.always != nil {
+		if *n.always {
+			return nil
+		}
+
+		return []*Violation{{Path: path, Message: "value is not allowed"}}
+	}
+
+	var violations []*Violation
+
+	report := func(format string, args ...interface{}) {
+		violations = append(violations, &Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n.refNode != nil {
+		violations = append(violations, validate(n.refNode, value, path)...)
+	}
+
+	if len(n.types) > 0 && !matchesType(n.types, value) {
+		if len(n.types) == 1 {
+			report("expected %s, got %s", n.types[0], typeOf(value))
+		} else {
+			report("expected one of %v, got %s", n.types, typeOf(value))
+		}
+
+		// Further assertions would only produce follow-up errors.
+		return violations
+	}
+
+	if n.enum != nil && !containsValue(n.enum, value) {
+		report("must be one of %s, got %s", formatValue(n.enum), formatValue(value))
+	}
+
+	if n.hasConst && !reflect.DeepEqual(n.constant, value) {
+		report("must be %s, got %s", formatValue(n.constant), formatValue(value))
+	}
+
+	switch v := value.(type) {
+	case float64:
+		violations = append(violations, validateNumber(n, v, path)...)
+	case string:
+		violations = append(violations, validateString(n, v, path)...)
+	case []interface{}:
+		violations = append(violations, validateArray(n, v, path)...)
+	case map[string]interface{}:
+		violations = append(violations, validateObject(n, v, path)...)
+	}
+
+	for _, sub := range n.allOf {
+		violations = append(violations, validate(sub, value, path)...)
+	}
+
+	if n.anyOf != nil && countMatches(n.anyOf, value, path) == 0 {
+		report("must match at least one of the anyOf schemas")
+	}
+
+	if n.oneOf != nil {
+		if matches := countMatches(n.oneOf, value, path); matches != 1 {
+			report("must match exactly one of the oneOf schemas, matched %d", matches)
+		}
+	}
+
+	if n.not != nil && len(validate(n.not, value, path)) == 0 {
+		report("must not match the not schema")
+	}
+
+	return violations
+}
+
+func validateNumber(n *node, v float64, path string) []*Violation {
+	var violations []*Violation
+
+	report := func(format string, args ...interface{}) {
+		violations = append(violations, &Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n.minimum != nil && v < *n.minimum {
+		report("must be >= %v, got %v", *n.minimum, v)
+	}
+
+	if n.maximum != nil && v > *n.maximum {
+		report("must be <= %v, got %v", *n.maximum, v)
+	}
+
+	if n.exclusiveMinimum != nil && v <= *n.exclusiveMinimum {
+		report("must be > %v, got %v", *n.exclusiveMinimum, v)
+	}
+
+	if n.exclusiveMaximum != nil && v >= *n.exclusiveMaximum {
+		report("must be < %v, got %v", *n.exclusiveMaximum, v)
+	}
+
+	if n.multipleOf != nil && *n.multipleOf != 0 && !isInteger(v / *n.multipleOf) {
+		report("must be a multiple of %v, got %v", *n.multipleOf, v)
+	}
+
+	return violations
+}
+
+func validateString(n *node, v string, path string) []*Violation {
+	var violations []*Violation
+
+	report := func(format string, args ...interface{}) {
+		violations = append(violations, &Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	length := utf8.RuneCountInString(v)
+
+	if n.minLength != nil && length < *n.minLength {
+		report("must be at least %d characters long, got %d", *n.minLength, length)
+	}
+
+	if n.maxLength != nil && length > *n.maxLength {
+		report("must be at most %d characters long, got %d", *n.maxLength, length)
+	}
+
+	if n.pattern != nil && !n.pattern.MatchString(v) {
+		report("must match pattern %s, got %q", n.pattern, v)
+	}
+
+	return violations
+}
+
+func validateArray(n *node, v []interface{}, path string) []*Violation {
+	var violations []*Violation
+
+	report := func(format string, args ...interface{}) {
+		violations = append(violations, &Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n.minItems != nil && len(v) < *n.minItems {
+		report("must have at least %d items, got %d", *n.minItems, len(v))
+	}
+
+	if n.maxItems != nil && len(v) > *n.maxItems {
+		report("must have at most %d items, got %d", *n.maxItems, len(v))
+	}
+
+	if n.uniqueItems {
+		for i := 1; i < len(v); i++ {
+			if containsValue(v[:i], v[i]) {
+				report("items must be unique, item %d is a duplicate", i)
+				break
+			}
+		}
+	}
+
+	if n.items != nil {
+		for i, item := range v {
+			violations = append(violations, validate(n.items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return violations
+}
+
+func validateObject(n *node, v map[string]interface{}, path string) []*Violation {
+	var violations []*Violation
+
+	report := func(format string, args ...interface{}) {
+		violations = append(violations, &Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n.minProperties != nil && len(v) < *n.minProperties {
+		report("must have at least %d properties, got %d", *n.minProperties, len(v))
+	}
+
+	if n.maxProperties != nil && len(v) > *n.maxProperties {
+		report("must have at most %d properties, got %d", *n.maxProperties, len(v))
+	}
+
+	for _, name := range n.required {
+		if _, ok := v[name]; !ok {
+			violations = append(violations, &Violation{Path: propertyPath(path, name), Message: "required value is missing"})
+		}
+	}
+
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		childPath := propertyPath(path, name)
+		matched := false
+
+		if schema, ok := n.properties[name]; ok {
+			matched = true
+			violations = append(violations, validate(schema, v[name], childPath)...)
+		}
+
+		for _, pp := range n.patternProperties {
+			if pp.pattern.MatchString(name) {
+				matched = true
+				violations = append(violations, validate(pp.schema, v[name], childPath)...)
+			}
+		}
+
+		if matched || n.additionalProperties == nil {
+			continue
+		}
+
+		if a := n.additionalProperties.always; a != nil && !*a {
+			violations = append(violations, &Violation{Path: childPath, Message: "property is not allowed"})
+			continue
+		}
+
+		violations = append(violations, validate(n.additionalProperties, v[name], childPath)...)
+	}
+
+	return violations
+}
+
+func countMatches(nodes []*node, value interface{}, path string) int {
+	var matches int
+
+	for _, n := range nodes {
+		if len(validate(n, value, path)) == 0 {
+			matches++
+		}
+	}
+
+	return matches
+}
+
+var identifierRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// propertyPath returns the JSON path of the property name of the object at
+// path.
+func propertyPath(path, name string) string {
+	if identifierRegexp.MatchString(name) {
+		return path + "." + name
+	}
+
+	return fmt.Sprintf("%s[%q]", path, name)
+}
+
+func matchesType(types []string, value interface{}) bool {
+	actual := typeOf(value)
+
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// typeOf returns the JSON schema type of value. Numbers without fractional
+// part are integers.
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if isInteger(v) {
+			return "integer"
+		}
+
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func isInteger(f float64) bool {
+	return f == math.Trunc(f) && !math.IsInf(f, 0)
+}
+
+func containsValue(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func formatValue(value interface{}) string {
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(buf)
+}
@@ -27,6 +27,11 @@ type Config struct {
 	// Values holds user-defined values that get merged on to of skeleton
 	// values.
 	Values template.Values `json:"values,omitempty"`
+	// Skeletons holds configuration for loading skeletons.
+	Skeletons SkeletonsConfig `json:"skeletons,omitempty"`
+	// Forges holds the configuration of code hosting platforms that projects
+	// can be published to. Keys are project hosts, e.g. 'git.example.com'.
+	Forges map[string]ForgeConfig `json:"forges,omitempty"`
 }
 
 // DefaultConfig returns the default config.
@@ -69,9 +74,87 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if err := c.Skeletons.Validate(); err != nil {
+		return err
+	}
+
+	for host, forge := range c.Forges {
+		if !isForgeType(forge.Type) {
+			return newForgeConfigError("invalid type %q for host %q, must be one of %v", forge.Type, host, forgeTypes)
+		}
+
+		if _, err := url.Parse(forge.URL); err != nil {
+			return newForgeConfigError("invalid URL for host %q: %w", host, err)
+		}
+	}
+
 	return c.Project.Validate()
 }
 
+// SkeletonsConfig contains configuration for loading skeletons.
+type SkeletonsConfig struct {
+	// MaxFileSize is the maximum size in bytes of skeleton files that need
+	// to be loaded into memory, i.e. templates, patches and files that are
+	// merged with files of other skeletons. Other files are streamed and are
+	// not subject to this limit. If zero, DefaultMaxFileSize is used.
+	MaxFileSize int64 `json:"maxFileSize,omitempty"`
+}
+
+// Validate implements the Validator interface.
+func (c *SkeletonsConfig) Validate() error {
+	if c.MaxFileSize < 0 {
+		return newSkeletonsConfigError("maxFileSize must not be negative, got %d", c.MaxFileSize)
+	}
+
+	return nil
+}
+
+// Forge returns the forge config for host. Hosts without explicit config fall
+// back to the GitHub API if host is github.com. Returns false if there is no
+// forge config for host.
+func (c *Config) Forge(host string) (ForgeConfig, bool) {
+	if forge, ok := c.Forges[host]; ok {
+		return forge, true
+	}
+
+	if host == DefaultProjectHost {
+		return ForgeConfig{Type: ForgeGitHub}, true
+	}
+
+	return ForgeConfig{}, false
+}
+
+// ForgeType is the type of a code hosting platform.
+type ForgeType string
+
+const (
+	// ForgeGitHub is the type of GitHub and GitHub Enterprise instances.
+	ForgeGitHub ForgeType = "github"
+	// ForgeGitea is the type of Gitea instances.
+	ForgeGitea ForgeType = "gitea"
+)
+
+var forgeTypes = []ForgeType{ForgeGitHub, ForgeGitea}
+
+// ForgeConfig configures the API of a code hosting platform.
+type ForgeConfig struct {
+	// Type is the type of the platform, either 'github' or 'gitea'.
+	Type ForgeType `json:"type"`
+	// URL is the base URL of the platform API. If empty, the URL is derived
+	// from the host, e.g. 'https://git.example.com/api/v1' for Gitea.
+	URL string `json:"url,omitempty"`
+}
+
+func isForgeType(typ ForgeType) bool {
+	for _, t := range forgeTypes {
+		if typ == t {
+			return true
+		}
+	}
+
+	return false
+}
+
 // ProjectConfig contains project specific configuration like git host, owner and
 // project name.
 type ProjectConfig struct {
@@ -84,6 +167,23 @@ type ProjectConfig struct {
 	// Gitignore holds a comma-separated list of gitignore templates, e.g.
 	// 'go,hugo'.
 	Gitignore string `json:"gitignore,omitempty"`
+	// Git holds defaults for the git repository that is initialized in new
+	// project directories.
+	Git GitConfig `json:"git,omitempty"`
+}
+
+// GitConfig contains the defaults for setting up the git repository of new
+// projects.
+type GitConfig struct {
+	// CommitMessage is the message of the initial commit of all project
+	// files. No commit is made if empty.
+	CommitMessage string `json:"commitMessage,omitempty"`
+	// Remote is the name of the remote that is added pointing to the project
+	// URL, e.g. 'origin'. No remote is added if empty.
+	Remote string `json:"remote,omitempty"`
+	// Branch is the name of the initial branch, e.g. 'main'. Uses the go-git
+	// default if empty.
+	Branch string `json:"branch,omitempty"`
 }
 
 // ApplyDefaults applies defaults to unset fields. If the Owner field is empty
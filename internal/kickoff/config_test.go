@@ -95,6 +95,11 @@ func TestConfig_Validate(t *testing.T) {
 				Repositories: map[string]string{"default": "/tmp/foo"},
 			},
 		},
+		{
+			name: "config with negative max file size",
+			v:    &Config{Skeletons: SkeletonsConfig{MaxFileSize: -1}},
+			err:  newSkeletonsConfigError("maxFileSize must not be negative, got -1"),
+		},
 		{
 			name: "config with invalid repository name",
 			v: &Config{
@@ -102,6 +107,19 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			err: newRepositoryRefError(`repository name "invalid:" does not match pattern: ^[a-zA-Z0-9_/.+-]+$`),
 		},
+		{
+			name: "config with forge",
+			v: &Config{
+				Forges: map[string]ForgeConfig{"git.example.com": {Type: ForgeGitea, URL: "https://git.example.com/api/v1"}},
+			},
+		},
+		{
+			name: "config with invalid forge type",
+			v: &Config{
+				Forges: map[string]ForgeConfig{"git.example.com": {Type: "gitlab"}},
+			},
+			err: newForgeConfigError(`invalid type "gitlab" for host "git.example.com", must be one of [github gitea]`),
+		},
 	}
 
 	runValidatorTests(t, testCases)
@@ -17,11 +17,30 @@ var (
 
 // Base validation errors.
 var (
-	invalidProjectConfig = "invalid project config"
-	invalidRepositoryRef = "invalid repository ref"
-	invalidSkeletonRef   = "invalid skeleton ref"
+	invalidForgeConfig      = "invalid forge config"
+	invalidLock             = "invalid lock"
+	invalidProjectConfig    = "invalid project config"
+	invalidProjectManifest  = "invalid project manifest"
+	invalidProjectSpec      = "invalid project spec"
+	invalidRepositoryConfig = "invalid repository config"
+	invalidRepositoryRef    = "invalid repository ref"
+	invalidSkeletonRef      = "invalid skeleton ref"
+	invalidSkeletonConfig   = "invalid skeleton config"
+	invalidSkeletonsConfig  = "invalid skeletons config"
 )
 
+// VersionMismatchError is returned if the kickoff version does not satisfy
+// the version constraint of a skeleton or repository.
+type VersionMismatchError struct {
+	Constraint string
+	Version    string
+}
+
+// Error implements the error interface.
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("kickoff %s is required, but this is kickoff %s, please upgrade kickoff", e.Constraint, e.Version)
+}
+
 // ValidationError wraps all errors that occur during validation.
 type ValidationError struct {
 	Context string
@@ -47,10 +66,30 @@ func newValidationError(context, format string, args ...interface{}) *Validation
 	}
 }
 
+func newForgeConfigError(format string, args ...interface{}) *ValidationError {
+	return newValidationError(invalidForgeConfig, format, args...)
+}
+
+func newLockError(format string, args ...interface{}) *ValidationError {
+	return newValidationError(invalidLock, format, args...)
+}
+
 func newProjectConfigError(format string, args ...interface{}) *ValidationError {
 	return newValidationError(invalidProjectConfig, format, args...)
 }
 
+func newProjectManifestError(format string, args ...interface{}) *ValidationError {
+	return newValidationError(invalidProjectManifest, format, args...)
+}
+
+func newProjectSpecError(format string, args ...interface{}) *ValidationError {
+	return newValidationError(invalidProjectSpec, format, args...)
+}
+
+func newRepositoryConfigError(format string, args ...interface{}) *ValidationError {
+	return newValidationError(invalidRepositoryConfig, format, args...)
+}
+
 func newRepositoryRefError(format string, args ...interface{}) *ValidationError {
 	return newValidationError(invalidRepositoryRef, format, args...)
 }
@@ -58,3 +97,11 @@ func newRepositoryRefError(format string, args ...interface{}) *ValidationError
 func newSkeletonRefError(format string, args ...interface{}) *ValidationError {
 	return newValidationError(invalidSkeletonRef, format, args...)
 }
+
+func newSkeletonConfigError(format string, args ...interface{}) *ValidationError {
+	return newValidationError(invalidSkeletonConfig, format, args...)
+}
+
+func newSkeletonsConfigError(format string, args ...interface{}) *ValidationError {
+	return newValidationError(invalidSkeletonsConfig, format, args...)
+}
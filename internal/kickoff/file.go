@@ -1,40 +1,137 @@
 package kickoff
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 )
 
-// BufferedFile is a file that is already buffered in memory.
+// BufferedFile is a skeleton file. Its content is either buffered in memory
+// or read on demand via Opener.
 type BufferedFile struct {
 	// RelPath is the file path relative to root directory of the skeleton.
 	// This is used to construct the path for the file relative to the the
 	// target project directory.
 	RelPath string `json:"relPath"`
-	// Content holds the file contents.
+	// Content holds the file contents if they are buffered in memory. Use
+	// Open or ReadContent to access the content of files regardless of
+	// whether it is buffered or not.
 	Content []byte `json:"content"`
+	// Opener opens the file content for reading if the content is not
+	// buffered in memory.
+	Opener func() (io.ReadCloser, error) `json:"-"`
+	// Size is the size of the file content in bytes. Zero for files whose
+	// content is buffered in Content or whose size is not known in advance,
+	// e.g. files merged from multiple skeletons.
+	Size int64 `json:"size,omitempty"`
+	// Binary is true if the file content is binary. Binary files are never
+	// rendered as templates.
+	Binary bool `json:"binary,omitempty"`
 	// Mode is the os.Mode for the file. This provides information about the
-	// type of file, e.g. whether it is a directory or not.
+	// type of file, e.g. whether it is a directory or a symlink.
 	Mode os.FileMode `json:"mode"`
+	// LinkTarget is the target of a symlink. It may contain template
+	// expressions. Empty if the file is not a symlink.
+	LinkTarget string `json:"linkTarget,omitempty"`
 	// SkeletonRef contains the ref to the skeleton the file originated from or
 	// nil if it does not belong to a specific skeleton. Used to keep track of
 	// file origins during skeleton composition.
 	SkeletonRef *SkeletonRef `json:"-"`
+	// MergedFrom contains the refs of all skeletons whose content was
+	// combined into the file in composition order. Nil if the file was not
+	// merged.
+	MergedFrom []*SkeletonRef `json:"-"`
+	// Merge is the strategy that is used to combine Parts. Empty if the file
+	// was not merged.
+	Merge MergeStrategy `json:"-"`
+	// Parts holds the files that are combined into the file using Merge in
+	// composition order. Each part is rendered on its own before the results
+	// are combined, thus neither Content nor Opener are set for merged files.
+	Parts []*BufferedFile `json:"-"`
+	// Patches holds the patch files of skeletons composed later. They are
+	// applied in order to the rendered content of the file.
+	Patches []*BufferedFile `json:"-"`
+}
+
+// Open opens the file content for reading.
+func (f *BufferedFile) Open() (io.ReadCloser, error) {
+	if f.Opener == nil {
+		return io.NopCloser(bytes.NewReader(f.Content)), nil
+	}
+
+	return f.Opener()
+}
+
+// ReadContent returns the file content. If the content is not buffered in
+// memory, it is read via Opener.
+func (f *BufferedFile) ReadContent() ([]byte, error) {
+	if f.Opener == nil {
+		return f.Content, nil
+	}
+
+	r, err := f.Opener()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// IsSymlink returns true if f is a symlink.
+func (f *BufferedFile) IsSymlink() bool {
+	return f.Mode&os.ModeSymlink != 0
 }
 
 // MergeFiles merges two lists of files. Files in the rhs list take precedence
 // over files in the lhs list with the same name.
 func MergeFiles(lhs, rhs []*BufferedFile) []*BufferedFile {
+	return mergeFiles(lhs, rhs, nil)
+}
+
+// mergeFiles merges two lists of files like MergeFiles. Regular files present
+// in both lists are combined according to the merge strategy of the last rule
+// in rules that matches the file path. Patch files in rhs are applied to the
+// file they target in lhs.
+func mergeFiles(lhs, rhs []*BufferedFile, rules []*FileRule) []*BufferedFile {
 	fileMap := make(map[string]*BufferedFile)
 
 	for _, f := range lhs {
 		fileMap[f.RelPath] = f
 	}
 
+	// Patch targets must be resolved before files of rhs are added.
+	patchTargets := make(map[*BufferedFile]string)
+
+	for _, f := range rhs {
+		if target, ok := patchTarget(fileMap, f); ok {
+			patchTargets[f] = target
+		}
+	}
+
 	for _, f := range rhs {
+		if _, ok := patchTargets[f]; ok {
+			continue
+		}
+
+		if existing, ok := fileMap[f.RelPath]; ok {
+			f = mergeFile(existing, f, mergeStrategy(rules, f.RelPath))
+		}
+
 		fileMap[f.RelPath] = f
 	}
 
+	for _, f := range rhs {
+		if target, ok := patchTargets[f]; ok {
+			fileMap[target] = patchFile(fileMap[target], f)
+		}
+	}
+
 	filePaths := make([]string, 0, len(fileMap))
 	for path := range fileMap {
 		filePaths = append(filePaths, path)
@@ -49,3 +146,142 @@ func MergeFiles(lhs, rhs []*BufferedFile) []*BufferedFile {
 
 	return files
 }
+
+// mergeStrategy returns the merge strategy of the last rule that matches path
+// and declares one. Defaults to MergeReplace.
+func mergeStrategy(rules []*FileRule, path string) MergeStrategy {
+	strategy := MergeReplace
+
+	for _, rule := range rules {
+		if rule.Merge != "" && rule.MatchPath(path) {
+			strategy = rule.Merge
+		}
+	}
+
+	return strategy
+}
+
+// mergeFile returns a file that combines lhs and rhs using strategy. Returns
+// rhs if strategy is MergeReplace or if any of the two is not a regular text
+// file.
+func mergeFile(lhs, rhs *BufferedFile, strategy MergeStrategy) *BufferedFile {
+	if strategy == MergeReplace || !isMergeable(lhs) || !isMergeable(rhs) {
+		return rhs
+	}
+
+	return &BufferedFile{
+		RelPath:     rhs.RelPath,
+		Mode:        rhs.Mode,
+		SkeletonRef: rhs.SkeletonRef,
+		MergedFrom:  mergedFrom(lhs, rhs.SkeletonRef),
+		Merge:       strategy,
+		Parts:       []*BufferedFile{lhs, rhs},
+	}
+}
+
+// patchTarget returns the path of the file in fileMap that patch applies to.
+// The target of `foo.patch` and `foo.patch.skel` is either `foo` or
+// `foo.skel`. Returns false if patch is not a patch file or if there is no
+// regular text file it applies to.
+func patchTarget(fileMap map[string]*BufferedFile, patch *BufferedFile) (string, bool) {
+	path := strings.TrimSuffix(patch.RelPath, SkeletonTemplateExtension)
+
+	if filepath.Ext(path) != PatchExtension || !isMergeable(patch) {
+		return "", false
+	}
+
+	path = strings.TrimSuffix(path, PatchExtension)
+
+	for _, target := range []string{path, path + SkeletonTemplateExtension} {
+		if f, ok := fileMap[target]; ok && isMergeable(f) {
+			return target, true
+		}
+	}
+
+	return "", false
+}
+
+// patchFile returns a copy of target with patch added to its patches.
+func patchFile(target, patch *BufferedFile) *BufferedFile {
+	patched := *target
+	patched.MergedFrom = mergedFrom(target, patch.SkeletonRef)
+	patched.Patches = append(target.Patches[:len(target.Patches):len(target.Patches)], patch)
+
+	return &patched
+}
+
+// mergedFrom returns the refs of all skeletons that contributed to f followed
+// by ref.
+func mergedFrom(f *BufferedFile, ref *SkeletonRef) []*SkeletonRef {
+	refs := f.MergedFrom
+	if refs == nil {
+		refs = []*SkeletonRef{f.SkeletonRef}
+	}
+
+	return append(refs[:len(refs):len(refs)], ref)
+}
+
+// checkPartSizes returns an error if any of the parts of merged files in files
+// is larger than maxFileSize. Zero means no limit.
+func checkPartSizes(files []*BufferedFile, maxFileSize int64) error {
+	if maxFileSize == 0 {
+		return nil
+	}
+
+	for _, f := range files {
+		for _, part := range f.Parts {
+			if part.Size > maxFileSize {
+				return fmt.Errorf("file %s of skeleton %s too large: refusing to merge files larger than %d bytes",
+					part.RelPath, part.SkeletonRef, maxFileSize)
+			}
+		}
+
+		if err := checkPartSizes(f.Parts, maxFileSize); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isMergeable(f *BufferedFile) bool {
+	return f.Mode.IsRegular() && !f.Binary
+}
+
+// ValidateLinkTarget returns an error if target is absolute or if the symlink
+// at relPath pointing to target would escape the root directory relPath is
+// relative to.
+func ValidateLinkTarget(relPath, target string) error {
+	if target == "" {
+		return errors.New("symlink target must not be empty")
+	}
+
+	if filepath.IsAbs(target) {
+		return fmt.Errorf("symlink target %q must be relative", target)
+	}
+
+	resolved := filepath.Join(filepath.Dir(relPath), target)
+
+	if resolved == ".." || strings.HasPrefix(resolved, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("symlink target %q escapes the root directory", target)
+	}
+
+	return nil
+}
+
+// binarySniffLen is the number of bytes that are inspected to detect binary
+// content. This is the same heuristic git uses.
+const binarySniffLen = 8000
+
+// IsBinary returns true if r contains a NUL byte within the first 8000
+// bytes.
+func IsBinary(r io.Reader) (bool, error) {
+	buf := make([]byte, binarySniffLen)
+
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+
+	return bytes.IndexByte(buf[:n], 0) != -1, nil
+}
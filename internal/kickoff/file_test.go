@@ -0,0 +1,65 @@
+package kickoff
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateLinkTarget(t *testing.T) {
+	assert.NoError(t, ValidateLinkTarget("AGENTS.md", "README.md"))
+	assert.NoError(t, ValidateLinkTarget("docs/README.md", "../README.md"))
+	assert.NoError(t, ValidateLinkTarget("docs/current", "."))
+	assert.EqualError(t, ValidateLinkTarget("README.md", ""), "symlink target must not be empty")
+	assert.EqualError(t, ValidateLinkTarget("README.md", "/etc/passwd"), `symlink target "/etc/passwd" must be relative`)
+	assert.EqualError(t, ValidateLinkTarget("docs/README.md", "../../README.md"), `symlink target "../../README.md" escapes the root directory`)
+	assert.EqualError(t, ValidateLinkTarget("link", ".."), `symlink target ".." escapes the root directory`)
+}
+
+func TestBufferedFile_ReadContent(t *testing.T) {
+	buffered := &BufferedFile{Content: []byte("buffered")}
+
+	buf, err := buffered.ReadContent()
+	require.NoError(t, err)
+	assert.Equal(t, "buffered", string(buf))
+
+	var opened int
+
+	lazy := &BufferedFile{
+		Opener: func() (io.ReadCloser, error) {
+			opened++
+			return io.NopCloser(strings.NewReader("lazy")), nil
+		},
+	}
+
+	assert.Equal(t, 0, opened)
+
+	buf, err = lazy.ReadContent()
+	require.NoError(t, err)
+	assert.Equal(t, "lazy", string(buf))
+	assert.Equal(t, 1, opened)
+
+	r, err := lazy.Open()
+	require.NoError(t, err)
+	defer r.Close()
+
+	buf, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "lazy", string(buf))
+}
+
+func TestIsBinary(t *testing.T) {
+	for content, expected := range map[string]bool{
+		"":                                     false,
+		"some text\n":                          false,
+		"binary\x00content":                    true,
+		strings.Repeat("a", 8000) + "\x00late": false,
+	} {
+		binary, err := IsBinary(strings.NewReader(content))
+		require.NoError(t, err)
+		assert.Equal(t, expected, binary)
+	}
+}
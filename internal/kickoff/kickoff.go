@@ -12,12 +12,30 @@ const (
 	// SkeletonConfigFileName is the name of the file that is searched to
 	// file skeletons and their config.
 	SkeletonConfigFileName = ".kickoff.yaml"
+	// ValuesSchemaFileName is the name of the optional JSON schema file next
+	// to the skeleton config that the template values are validated against.
+	ValuesSchemaFileName = "values.schema.json"
+	// IgnoreFileName is the name of the file in skeleton directories and
+	// repository roots that contains gitignore patterns of files that should
+	// be excluded from skeletons.
+	IgnoreFileName = ".kickoffignore"
+	// RepositoryConfigFileName is the name of the optional repository config
+	// file in the root of a skeleton repository.
+	RepositoryConfigFileName = "repository.yaml"
+	// LockFileName is the name of the file that is written into new project
+	// directories to record the skeletons and values the project was created
+	// from.
+	LockFileName = ".kickoff.lock"
 	// SkeletonTemplateExtension is the file extension for template files
 	// within kickoff skeletons. Although kickoff template files are
 	// gotemplates, we must not use .tmpl as user may want to include their own
 	// gotemplate files in skeletons which must not be evaluated by kickoff,
 	// hence we use .skel to avoid issues here.
 	SkeletonTemplateExtension = ".skel"
+	// PatchExtension is the file extension of patch files within skeletons.
+	// When skeletons are composed, a patch file is applied to the file at
+	// the same path without the extension from the skeletons before it.
+	PatchExtension = ".patch"
 	// SkeletonsDir is the subdirectory of a repository where skeletons
 	// can be found.
 	SkeletonsDir = "skeletons"
@@ -32,6 +50,9 @@ const (
 	DefaultRepositoryName = "default"
 	// DefaultSkeletonName is the name of the default skeleton in a repository.
 	DefaultSkeletonName = "default"
+	// DefaultMaxFileSize is the default maximum size in bytes of skeleton
+	// files that need to be loaded into memory, e.g. templates and patches.
+	DefaultMaxFileSize = 100 * 1024 * 1024
 )
 
 const (
@@ -43,6 +64,8 @@ const (
 	EnvKeyLogLevel = "KICKOFF_LOG_LEVEL"
 	// EnvKeyNoUpdateCheck disables update checks.
 	EnvKeyNoUpdateCheck = "KICKOFF_NO_UPDATE_CHECK"
+	// EnvKeyForgeToken holds the API token used to publish projects.
+	EnvKeyForgeToken = "KICKOFF_FORGE_TOKEN"
 )
 
 var (
@@ -0,0 +1,112 @@
+package kickoff
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/martinohmann/kickoff/internal/template"
+)
+
+// Lock describes the schema of the .kickoff.lock file that is written into
+// the project directory upon project creation. It records everything that is
+// needed to render the project skeletons again, e.g. to update the project
+// with later skeleton changes.
+type Lock struct {
+	// KickoffVersion is the version of kickoff that created the project.
+	KickoffVersion string `json:"kickoffVersion,omitempty"`
+	// CreatedAt is the time of the project creation. It is used to fill the
+	// year into license texts when rendering the project again.
+	CreatedAt time.Time `json:"createdAt"`
+	// Project holds the project configuration that was made available to
+	// templates.
+	Project ProjectLock `json:"project"`
+	// Skeletons holds the skeletons the project was created from in the order
+	// they were composed.
+	Skeletons []*SkeletonLock `json:"skeletons"`
+	// Values holds the values the project was rendered with, i.e. the
+	// skeleton values merged with the user-defined values from the kickoff
+	// config, values files and --set flags.
+	Values template.Values `json:"values,omitempty"`
+}
+
+// Validate implements the Validator interface.
+func (l *Lock) Validate() error {
+	if l.Project.Name == "" {
+		return newLockError("project name must not be empty")
+	}
+
+	if len(l.Skeletons) == 0 {
+		return newLockError("skeletons must not be empty")
+	}
+
+	for _, skeleton := range l.Skeletons {
+		if err := skeleton.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ProjectLock holds the project configuration recorded in the lock.
+type ProjectLock struct {
+	// Name is the project name.
+	Name string `json:"name"`
+	// Host is the project host, e.g. github.com.
+	Host string `json:"host,omitempty"`
+	// Owner is the project owner, e.g. SCM username.
+	Owner string `json:"owner,omitempty"`
+	// License holds the key of the open source license, if any.
+	License string `json:"license,omitempty"`
+	// Gitignore holds the comma-separated list of gitignore templates, if
+	// any.
+	Gitignore string `json:"gitignore,omitempty"`
+	// Monorepo is true if the project was created as a sub-project of an
+	// existing git repository.
+	Monorepo bool `json:"monorepo,omitempty"`
+}
+
+// SkeletonLock records a skeleton and the exact state of the repository it
+// was loaded from.
+type SkeletonLock struct {
+	// Name of the skeleton within its repository.
+	Name string `json:"name"`
+	// Repo references the repository where the skeleton can be found.
+	Repo RepoRef `json:"repo"`
+	// Commit is the hash of the commit the repository was at when the
+	// skeleton was loaded. Empty if the repository is not a git repository.
+	Commit string `json:"commit,omitempty"`
+}
+
+// String implements fmt.Stringer.
+func (l *SkeletonLock) String() string {
+	if l.Repo.Name == "" {
+		return l.Name
+	}
+
+	return fmt.Sprintf("%s:%s", l.Repo.Name, l.Name)
+}
+
+// Validate implements the Validator interface.
+func (l *SkeletonLock) Validate() error {
+	if l.Name == "" {
+		return newSkeletonRefError("Name must not be empty")
+	}
+
+	return l.Repo.Validate()
+}
+
+// LoadLock loads the lock from path and returns it.
+func LoadLock(path string) (*Lock, error) {
+	var lock Lock
+
+	if err := Load(path, &lock); err != nil {
+		return nil, fmt.Errorf("failed to load lock: %w", err)
+	}
+
+	if err := lock.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &lock, nil
+}
@@ -0,0 +1,66 @@
+package kickoff
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/martinohmann/kickoff/internal/template"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock_Validate(t *testing.T) {
+	testCases := []validatorTestCase{
+		{
+			name: "empty project name is invalid",
+			v:    &Lock{},
+			err:  newLockError("project name must not be empty"),
+		},
+		{
+			name: "empty skeletons are invalid",
+			v:    &Lock{Project: ProjectLock{Name: "myproject"}},
+			err:  newLockError("skeletons must not be empty"),
+		},
+		{
+			name: "skeleton with empty repo is invalid",
+			v: &Lock{
+				Project:   ProjectLock{Name: "myproject"},
+				Skeletons: []*SkeletonLock{{Name: "default"}},
+			},
+			err: newRepositoryRefError("URL or Path must be set"),
+		},
+		{
+			name: "valid lock",
+			v: &Lock{
+				Project: ProjectLock{Name: "myproject"},
+				Skeletons: []*SkeletonLock{
+					{Name: "default", Repo: RepoRef{Path: "/tmp/repo"}, Commit: "abc"},
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, testCases)
+}
+
+func TestLoadLock(t *testing.T) {
+	lock := &Lock{
+		KickoffVersion: "v0.1.0",
+		CreatedAt:      time.Date(2020, 10, 16, 12, 0, 0, 0, time.UTC),
+		Project:        ProjectLock{Name: "myproject", Host: "github.com", Owner: "johndoe"},
+		Skeletons: []*SkeletonLock{
+			{Name: "default", Repo: RepoRef{Name: "default", URL: "https://github.com/foo/bar", Revision: "main"}, Commit: "abc"},
+		},
+		Values: template.Values{"foo": "bar"},
+	}
+
+	path := filepath.Join(t.TempDir(), LockFileName)
+	require.NoError(t, Save(path, lock))
+
+	loaded, err := LoadLock(path)
+	require.NoError(t, err)
+	require.Equal(t, lock, loaded)
+
+	_, err = LoadLock(filepath.Join(t.TempDir(), LockFileName))
+	require.Error(t, err)
+}
@@ -0,0 +1,300 @@
+package kickoff
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParameterType defines the type of the value of a skeleton parameter.
+type ParameterType string
+
+const (
+	// ParameterString is a free-form string. This is the default.
+	ParameterString ParameterType = "string"
+	// ParameterBool is a boolean.
+	ParameterBool ParameterType = "bool"
+	// ParameterInt is an integer.
+	ParameterInt ParameterType = "int"
+	// ParameterEnum is a string that must be one of the parameter options.
+	ParameterEnum ParameterType = "enum"
+	// ParameterList is a list of strings.
+	ParameterList ParameterType = "list"
+)
+
+var parameterTypes = []ParameterType{ParameterString, ParameterBool, ParameterInt, ParameterEnum, ParameterList}
+
+var parameterNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)*$`)
+
+// Parameter declares a value that the user is asked for on project creation.
+// The answer is stored in the template values at the parameter name.
+type Parameter struct {
+	// Name is the dot-separated path of the value within .Values, e.g.
+	// `database.port`.
+	Name string `json:"name"`
+	// Type is the type of the value. Defaults to string if empty.
+	Type ParameterType `json:"type,omitempty"`
+	// Description is shown to the user when prompting for the value.
+	Description string `json:"description,omitempty"`
+	// Default is used if the user does not provide a value. String defaults
+	// are templates that are rendered against the template values, which
+	// include the answers to all previous parameters. A parameter without
+	// default must be provided by the user unless the skeleton values
+	// contain a value for it.
+	Default interface{} `json:"default,omitempty"`
+	// Options holds the allowed values of enum parameters.
+	Options []string `json:"options,omitempty"`
+	// Pattern is a regular expression that string values and the items of
+	// list values must match completely.
+	Pattern string `json:"pattern,omitempty"`
+	// Min is the minimum of int values.
+	Min *int `json:"min,omitempty"`
+	// Max is the maximum of int values.
+	Max *int `json:"max,omitempty"`
+	// When is a template expression like the if expression of file rules,
+	// e.g. `.Values.database.enabled`. The parameter is skipped if it
+	// evaluates to false.
+	When string `json:"when,omitempty"`
+	// SkeletonRef contains the ref to the skeleton that declared the
+	// parameter.
+	SkeletonRef *SkeletonRef `json:"-"`
+}
+
+// ValueType returns the type of the parameter value.
+func (p *Parameter) ValueType() ParameterType {
+	if p.Type == "" {
+		return ParameterString
+	}
+
+	return p.Type
+}
+
+// Validate implements the Validator interface.
+func (p *Parameter) Validate() error {
+	if !parameterNameRegexp.MatchString(p.Name) {
+		return newSkeletonConfigError("parameter name %q does not match pattern: %s", p.Name, parameterNameRegexp)
+	}
+
+	typ := p.ValueType()
+
+	if !isParameterType(typ) {
+		return newSkeletonConfigError("invalid type %q for parameter %q, must be one of %v", p.Type, p.Name, parameterTypes)
+	}
+
+	if typ == ParameterEnum && len(p.Options) == 0 {
+		return newSkeletonConfigError("enum parameter %q must have options", p.Name)
+	}
+
+	if typ != ParameterEnum && len(p.Options) > 0 {
+		return newSkeletonConfigError("options are only allowed for enum parameters, found them on %q", p.Name)
+	}
+
+	if p.Pattern != "" {
+		if typ != ParameterString && typ != ParameterList {
+			return newSkeletonConfigError("pattern is only allowed for string and list parameters, found it on %q", p.Name)
+		}
+
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return newSkeletonConfigError("invalid pattern for parameter %q: %w", p.Name, err)
+		}
+	}
+
+	if p.Min != nil || p.Max != nil {
+		if typ != ParameterInt {
+			return newSkeletonConfigError("min and max are only allowed for int parameters, found them on %q", p.Name)
+		}
+
+		if p.Min != nil && p.Max != nil && *p.Min > *p.Max {
+			return newSkeletonConfigError("min of parameter %q must not be greater than max", p.Name)
+		}
+	}
+
+	if p.Default != nil && !p.HasTemplatedDefault() {
+		if _, err := p.ConvertValue(p.Default); err != nil {
+			return newSkeletonConfigError("invalid default for parameter %q: %w", p.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// HasTemplatedDefault returns true if the default is a template that needs
+// to be rendered before use.
+func (p *Parameter) HasTemplatedDefault() bool {
+	s, ok := p.Default.(string)
+	return ok && strings.Contains(s, "{{")
+}
+
+// ConvertValue converts value to the type of the parameter and validates it.
+// Strings are parsed for bool, int and list parameters. List items are
+// separated by commas. Returns the converted value.
+func (p *Parameter) ConvertValue(value interface{}) (interface{}, error) {
+	switch p.ValueType() {
+	case ParameterBool:
+		return convertBool(value)
+	case ParameterInt:
+		return p.convertInt(value)
+	case ParameterEnum:
+		return p.convertEnum(value)
+	case ParameterList:
+		return p.convertList(value)
+	default:
+		return p.convertString(value)
+	}
+}
+
+func convertBool(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("expected a bool, got %q", v)
+		}
+
+		return b, nil
+	default:
+		return nil, fmt.Errorf("expected a bool, got %T", value)
+	}
+}
+
+func (p *Parameter) convertInt(value interface{}) (interface{}, error) {
+	var n int
+
+	switch v := value.(type) {
+	case int:
+		n = v
+	case int64:
+		n = int(v)
+	case float64:
+		if v != math.Trunc(v) {
+			return nil, fmt.Errorf("expected an integer, got %v", v)
+		}
+
+		n = int(v)
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got %q", v)
+		}
+
+		n = i
+	default:
+		return nil, fmt.Errorf("expected an integer, got %T", value)
+	}
+
+	if p.Min != nil && n < *p.Min {
+		return nil, fmt.Errorf("must be at least %d, got %d", *p.Min, n)
+	}
+
+	if p.Max != nil && n > *p.Max {
+		return nil, fmt.Errorf("must be at most %d, got %d", *p.Max, n)
+	}
+
+	return n, nil
+}
+
+func (p *Parameter) convertEnum(value interface{}) (interface{}, error) {
+	s := fmt.Sprint(value)
+
+	for _, option := range p.Options {
+		if s == option {
+			return s, nil
+		}
+	}
+
+	return nil, fmt.Errorf("must be one of %v, got %q", p.Options, s)
+}
+
+func (p *Parameter) convertList(value interface{}) (interface{}, error) {
+	var items []interface{}
+
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	case []string:
+		for _, s := range v {
+			items = append(items, s)
+		}
+	case string:
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				items = append(items, s)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("expected a list, got %T", value)
+	}
+
+	list := make([]interface{}, len(items))
+
+	for i, item := range items {
+		s, err := p.convertString(item)
+		if err != nil {
+			return nil, fmt.Errorf("item #%d %w", i+1, err)
+		}
+
+		list[i] = s
+	}
+
+	return list, nil
+}
+
+func (p *Parameter) convertString(value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return nil, fmt.Errorf("expected a string, got %T", value)
+	}
+
+	s := fmt.Sprint(value)
+
+	if p.Pattern != "" {
+		re, err := regexp.Compile("^(?:" + p.Pattern + ")$")
+		if err != nil {
+			return nil, err
+		}
+
+		if !re.MatchString(s) {
+			return nil, fmt.Errorf("must match pattern %s, got %q", p.Pattern, s)
+		}
+	}
+
+	return s, nil
+}
+
+func isParameterType(typ ParameterType) bool {
+	for _, t := range parameterTypes {
+		if t == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeParameters returns the parameters of both lists. Parameters of other
+// replace parameters with the same name in params, keeping their position.
+func mergeParameters(params, other []*Parameter) []*Parameter {
+	merged := make([]*Parameter, 0, len(params)+len(other))
+	merged = append(merged, params...)
+
+	indexes := make(map[string]int, len(merged))
+	for i, param := range merged {
+		indexes[param.Name] = i
+	}
+
+	for _, param := range other {
+		if i, ok := indexes[param.Name]; ok {
+			merged[i] = param
+			continue
+		}
+
+		indexes[param.Name] = len(merged)
+		merged = append(merged, param)
+	}
+
+	return merged
+}
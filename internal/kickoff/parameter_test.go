@@ -0,0 +1,176 @@
+package kickoff
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestParameter_Validate(t *testing.T) {
+	testCases := []validatorTestCase{
+		{
+			name: "string parameter with templated default",
+			v:    &Parameter{Name: "image.name", Default: "{{.Project.Name}}", Pattern: "[a-z-]+"},
+		},
+		{
+			name: "int parameter with range",
+			v:    &Parameter{Name: "port", Type: ParameterInt, Default: float64(8080), Min: intPtr(1), Max: intPtr(65535)},
+		},
+		{
+			name: "invalid name",
+			v:    &Parameter{Name: "foo..bar"},
+			err:  newSkeletonConfigError(`parameter name "foo..bar" does not match pattern: %s`, parameterNameRegexp),
+		},
+		{
+			name: "invalid type",
+			v:    &Parameter{Name: "foo", Type: "float"},
+			err:  newSkeletonConfigError(`invalid type "float" for parameter "foo", must be one of [string bool int enum list]`),
+		},
+		{
+			name: "enum without options",
+			v:    &Parameter{Name: "db", Type: ParameterEnum},
+			err:  newSkeletonConfigError(`enum parameter "db" must have options`),
+		},
+		{
+			name: "options on string parameter",
+			v:    &Parameter{Name: "db", Options: []string{"postgres"}},
+			err:  newSkeletonConfigError(`options are only allowed for enum parameters, found them on "db"`),
+		},
+		{
+			name: "pattern on bool parameter",
+			v:    &Parameter{Name: "docker", Type: ParameterBool, Pattern: "true"},
+			err:  newSkeletonConfigError(`pattern is only allowed for string and list parameters, found it on "docker"`),
+		},
+		{
+			name: "invalid pattern",
+			v:    &Parameter{Name: "foo", Pattern: "["},
+			err:  newSkeletonConfigError("invalid pattern for parameter \"foo\": %w", errors.New("error parsing regexp: missing closing ]: `[`")),
+		},
+		{
+			name: "min greater than max",
+			v:    &Parameter{Name: "port", Type: ParameterInt, Min: intPtr(10), Max: intPtr(1)},
+			err:  newSkeletonConfigError(`min of parameter "port" must not be greater than max`),
+		},
+		{
+			name: "default out of range",
+			v:    &Parameter{Name: "port", Type: ParameterInt, Default: 0, Min: intPtr(1)},
+			err:  newSkeletonConfigError(`invalid default for parameter "port": must be at least 1, got 0`),
+		},
+		{
+			name: "default not an option",
+			v:    &Parameter{Name: "db", Type: ParameterEnum, Options: []string{"postgres", "mysql"}, Default: "sqlite"},
+			err:  newSkeletonConfigError(`invalid default for parameter "db": must be one of [postgres mysql], got "sqlite"`),
+		},
+	}
+
+	runValidatorTests(t, testCases)
+}
+
+func TestParameter_ConvertValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		param       *Parameter
+		value       interface{}
+		expected    interface{}
+		expectedErr string
+	}{
+		{
+			name:     "string",
+			param:    &Parameter{Name: "foo"},
+			value:    42,
+			expected: "42",
+		},
+		{
+			name:        "string not matching pattern",
+			param:       &Parameter{Name: "foo", Pattern: "[a-z]+"},
+			value:       "abc1",
+			expectedErr: `must match pattern [a-z]+, got "abc1"`,
+		},
+		{
+			name:     "bool from string",
+			param:    &Parameter{Name: "foo", Type: ParameterBool},
+			value:    "true",
+			expected: true,
+		},
+		{
+			name:        "invalid bool",
+			param:       &Parameter{Name: "foo", Type: ParameterBool},
+			value:       "yes please",
+			expectedErr: `expected a bool, got "yes please"`,
+		},
+		{
+			name:     "int from int64",
+			param:    &Parameter{Name: "foo", Type: ParameterInt},
+			value:    int64(8080),
+			expected: 8080,
+		},
+		{
+			name:     "int from string",
+			param:    &Parameter{Name: "foo", Type: ParameterInt},
+			value:    " 8080",
+			expected: 8080,
+		},
+		{
+			name:        "int from fractional float",
+			param:       &Parameter{Name: "foo", Type: ParameterInt},
+			value:       1.5,
+			expectedErr: "expected an integer, got 1.5",
+		},
+		{
+			name:        "int above max",
+			param:       &Parameter{Name: "foo", Type: ParameterInt, Max: intPtr(10)},
+			value:       11,
+			expectedErr: "must be at most 10, got 11",
+		},
+		{
+			name:     "enum",
+			param:    &Parameter{Name: "foo", Type: ParameterEnum, Options: []string{"a", "b"}},
+			value:    "b",
+			expected: "b",
+		},
+		{
+			name:     "list from string",
+			param:    &Parameter{Name: "foo", Type: ParameterList},
+			value:    "a, b,,c",
+			expected: []interface{}{"a", "b", "c"},
+		},
+		{
+			name:        "list item not matching pattern",
+			param:       &Parameter{Name: "foo", Type: ParameterList, Pattern: "[a-z]+"},
+			value:       []interface{}{"a", "B"},
+			expectedErr: `item #2 must match pattern [a-z]+, got "B"`,
+		},
+		{
+			name:        "list from map",
+			param:       &Parameter{Name: "foo", Type: ParameterList},
+			value:       map[string]interface{}{},
+			expectedErr: "expected a list, got map[string]interface {}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := test.param.ConvertValue(test.value)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, value)
+		})
+	}
+}
+
+func TestMergeParameters(t *testing.T) {
+	a := &Parameter{Name: "a"}
+	b := &Parameter{Name: "b"}
+	b2 := &Parameter{Name: "b", Type: ParameterInt}
+	c := &Parameter{Name: "c"}
+
+	assert.Equal(t, []*Parameter{a, b2, c}, mergeParameters([]*Parameter{a, b}, []*Parameter{c, b2}))
+}
@@ -2,8 +2,10 @@ package kickoff
 
 import (
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"path/filepath"
 	"regexp"
 
@@ -115,6 +117,44 @@ func (r *RepoRef) SkeletonPath(name string) string {
 	return filepath.Join(r.SkeletonsPath(), name)
 }
 
+// RepositoryConfig describes the schema of the optional repository.yaml in
+// the root of a skeleton repository.
+type RepositoryConfig struct {
+	// Requires holds the requirements for using any skeleton of the
+	// repository.
+	Requires Requirements `json:"requires,omitempty"`
+}
+
+// Validate implements the Validator interface.
+func (c *RepositoryConfig) Validate() error {
+	if err := c.Requires.validate(); err != nil {
+		return newRepositoryConfigError("invalid kickoff version constraint %q: %w", c.Requires.Kickoff, err)
+	}
+
+	return nil
+}
+
+// LoadConfig loads the repository config. Returns an empty config if the
+// repository does not have a config file.
+func (r *RepoRef) LoadConfig() (*RepositoryConfig, error) {
+	var config RepositoryConfig
+
+	configPath := filepath.Join(r.LocalPath(), RepositoryConfigFileName)
+
+	err := Load(configPath, &config)
+	if errors.Is(err, os.ErrNotExist) {
+		return &config, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load repository config: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
 // ParseRepoRef parses a raw repository url and returns a repository ref
 // describing a local or remote skeleton repository. The rawurl parameter must
 // be either a local path or a remote url to a git repository. Remote url may
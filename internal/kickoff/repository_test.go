@@ -203,3 +203,25 @@ func TestRepoRef_SkeletonPath(t *testing.T) {
 	assert.Equal(t, ref.SkeletonsPath(), filepath.Join(LocalRepositoryCacheDir, "4c76fb4fd87cd5b1dca9d94fa35751b06f507109b75bd3a4bc35012ed33cecfb", "skeletons"))
 	assert.Equal(t, ref.SkeletonPath("bar"), filepath.Join(LocalRepositoryCacheDir, "4c76fb4fd87cd5b1dca9d94fa35751b06f507109b75bd3a4bc35012ed33cecfb", "skeletons", "bar"))
 }
+
+func TestRepoRef_LoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	ref := &RepoRef{Path: dir}
+
+	config, err := ref.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, &RepositoryConfig{}, config)
+
+	configPath := filepath.Join(dir, RepositoryConfigFileName)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("requires:\n  kickoff: '>=0.6.0'\n"), 0644))
+
+	config, err = ref.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, &RepositoryConfig{Requires: Requirements{Kickoff: ">=0.6.0"}}, config)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("requires:\n  kickoff: 'latest'\n"), 0644))
+
+	_, err = ref.LoadConfig()
+	require.EqualError(t, err, `invalid repository config: invalid kickoff version constraint "latest": improper constraint: latest`)
+}
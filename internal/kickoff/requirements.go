@@ -0,0 +1,60 @@
+package kickoff
+
+import (
+	"github.com/Masterminds/semver"
+)
+
+// Requirements describes what is needed to use a skeleton or repository.
+type Requirements struct {
+	// Kickoff is a semver constraint the kickoff version must satisfy, e.g.
+	// `>=0.6.0`.
+	Kickoff string `json:"kickoff,omitempty"`
+}
+
+// Check checks version against the kickoff version constraint. Returns a
+// *VersionMismatchError if version does not satisfy it. Development builds
+// and versions that are not valid semver are never rejected. The pre-release
+// part of version is ignored, so that release candidates satisfy constraints
+// for the version they precede.
+func (r *Requirements) Check(version string) error {
+	if r.Kickoff == "" {
+		return nil
+	}
+
+	constraint, err := semver.NewConstraint(r.Kickoff)
+	if err != nil {
+		return err
+	}
+
+	v, err := semver.NewVersion(version)
+	if err != nil || isDevelopmentVersion(v) {
+		return nil
+	}
+
+	release, err := v.SetPrerelease("")
+	if err != nil {
+		return err
+	}
+
+	if !constraint.Check(&release) {
+		return &VersionMismatchError{Constraint: r.Kickoff, Version: version}
+	}
+
+	return nil
+}
+
+func (r *Requirements) validate() error {
+	if r.Kickoff == "" {
+		return nil
+	}
+
+	_, err := semver.NewConstraint(r.Kickoff)
+
+	return err
+}
+
+// isDevelopmentVersion returns true for versions of kickoff binaries that
+// were not built from a release tag, e.g. `v0.0.0-master`.
+func isDevelopmentVersion(v *semver.Version) bool {
+	return v.Major() == 0 && v.Minor() == 0 && v.Patch() == 0
+}
@@ -0,0 +1,66 @@
+package kickoff
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequirements_Check(t *testing.T) {
+	tests := []struct {
+		name        string
+		constraint  string
+		version     string
+		expectedErr string
+	}{
+		{
+			name:    "no constraint",
+			version: "v0.1.0",
+		},
+		{
+			name:       "satisfied constraint",
+			constraint: ">=0.6.0",
+			version:    "v0.6.1",
+		},
+		{
+			name:        "unsatisfied constraint",
+			constraint:  ">=0.6.0",
+			version:     "v0.5.2",
+			expectedErr: "kickoff >=0.6.0 is required, but this is kickoff v0.5.2, please upgrade kickoff",
+		},
+		{
+			name:       "release candidate",
+			constraint: ">=0.6.0",
+			version:    "v0.6.0-rc.1",
+		},
+		{
+			name:       "development build",
+			constraint: ">=0.6.0",
+			version:    "v0.0.0-master",
+		},
+		{
+			name:       "invalid version",
+			constraint: ">=0.6.0",
+			version:    "unknown",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &Requirements{Kickoff: test.constraint}
+
+			err := r.Check(test.version)
+			if test.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.EqualError(t, err, test.expectedErr)
+
+			var mismatchErr *VersionMismatchError
+			assert.True(t, errors.As(err, &mismatchErr))
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package kickoff
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 
+	"github.com/martinohmann/kickoff/internal/jsonschema"
 	"github.com/martinohmann/kickoff/internal/template"
 )
 
@@ -17,11 +20,84 @@ type Skeleton struct {
 	// skeleton. May be nil if the skeleton is the merged result of composing
 	// multiple skeletons.
 	Ref *SkeletonRef `json:"ref,omitempty"`
+	// Extends holds the names of the skeletons from the skeleton's metadata
+	// that the skeleton is based on.
+	Extends []string `json:"extends,omitempty"`
 	// The Files slice contains a sorted list of files that are present in the
 	// skeleton.
 	Files []*BufferedFile `json:"files,omitempty"`
+	// IgnoredFiles contains the sorted paths of files and directories that
+	// were excluded from the skeleton by .kickoffignore patterns. Paths of
+	// directories end with a slash. Not set for the result of composing
+	// multiple skeletons.
+	IgnoredFiles []string `json:"ignoredFiles,omitempty"`
 	// Values are the template values from the skeleton's metadata.
 	Values template.Values `json:"values,omitempty"`
+	// Hooks holds the hook commands from the skeleton's metadata. When
+	// skeletons are composed, the hooks of all skeletons are kept in order.
+	Hooks []*Hook `json:"hooks,omitempty"`
+	// FileRules holds the rules for conditionally including files from the
+	// skeleton's metadata. When skeletons are composed, the rules of all
+	// skeletons are kept in order.
+	FileRules []*FileRule `json:"fileRules,omitempty"`
+	// Parameters holds the parameters from the skeleton's metadata. When
+	// skeletons are composed, parameters are kept in order and a parameter
+	// replaces the parameter with the same name of a skeleton composed
+	// before it.
+	Parameters []*Parameter `json:"parameters,omitempty"`
+	// ValuesSchemas holds the JSON schemas that the template values must
+	// conform to. When skeletons are composed, the schemas of all skeletons
+	// are kept in order.
+	ValuesSchemas []*ValuesSchema `json:"valuesSchemas,omitempty"`
+	// MaxFileSize is the maximum size in bytes of files that are combined
+	// into merged files, as they need to be loaded into memory for rendering.
+	// Zero means no limit. When skeletons are composed, the smaller limit is
+	// kept.
+	MaxFileSize int64 `json:"-"`
+}
+
+// ValuesSchema is the JSON schema for template values shipped with a
+// skeleton.
+type ValuesSchema struct {
+	*jsonschema.Schema
+	// SkeletonRef contains the ref to the skeleton that defined the schema.
+	SkeletonRef *SkeletonRef `json:"-"`
+}
+
+// HookStage defines when a hook is run.
+type HookStage string
+
+const (
+	// HookPreCreate hooks are run before the project files are written.
+	HookPreCreate HookStage = "preCreate"
+	// HookPostCreate hooks are run after the project files were written.
+	HookPostCreate HookStage = "postCreate"
+)
+
+// Hook is a command that is run in the project directory before or after
+// project creation.
+type Hook struct {
+	// Stage defines when the hook is run.
+	Stage HookStage `json:"stage"`
+	// Command is the command template.
+	Command string `json:"command"`
+	// SkeletonRef contains the ref to the skeleton that defined the hook.
+	SkeletonRef *SkeletonRef `json:"-"`
+}
+
+// NewHooks creates the hooks from config. The hooks are attached to ref.
+func NewHooks(config HookConfig, ref *SkeletonRef) []*Hook {
+	hooks := make([]*Hook, 0, len(config.PreCreate)+len(config.PostCreate))
+
+	for _, command := range config.PreCreate {
+		hooks = append(hooks, &Hook{Stage: HookPreCreate, Command: command, SkeletonRef: ref})
+	}
+
+	for _, command := range config.PostCreate {
+		hooks = append(hooks, &Hook{Stage: HookPostCreate, Command: command, SkeletonRef: ref})
+	}
+
+	return hooks
 }
 
 // String implements fmt.Stringer.
@@ -67,6 +143,26 @@ func (r *SkeletonRef) Validate() error {
 	return nil
 }
 
+// LoadValuesSchema loads the values schema of the skeleton. Returns nil if the
+// skeleton does not have one.
+func (r *SkeletonRef) LoadValuesSchema() (*ValuesSchema, error) {
+	schemaPath := filepath.Join(r.Path, ValuesSchemaFileName)
+
+	buf, err := os.ReadFile(schemaPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	schema, err := jsonschema.Compile(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", schemaPath, err)
+	}
+
+	return &ValuesSchema{Schema: schema, SkeletonRef: r}, nil
+}
+
 // LoadConfig loads the skeleton config for the info.
 func (r *SkeletonRef) LoadConfig() (*SkeletonConfig, error) {
 	configPath := filepath.Join(r.Path, SkeletonConfigFileName)
@@ -109,16 +205,58 @@ func MergeSkeletons(skeletons ...*Skeleton) (*Skeleton, error) {
 // taking preference over already existing values. Template values are
 // recursively merged and may cause errors on type mismatch. The original
 // skeletons are not altered.
+//
+// Files present in both are combined if a file rule of either skeleton
+// declares a merge strategy for them. Hooks, file rules and values schemas of
+// other are appended to those of s, parameters of other replace those of s
+// with the same name. Returns an error if a file combined into a merged file
+// is larger than MaxFileSize.
 func (s *Skeleton) Merge(other *Skeleton) (*Skeleton, error) {
 	values, err := template.MergeValues(s.Values, other.Values)
 	if err != nil {
 		return nil, fmt.Errorf("failed to merge skeleton %s and %s: %w", s.Ref, other.Ref, err)
 	}
 
+	hooks := make([]*Hook, 0, len(s.Hooks)+len(other.Hooks))
+	hooks = append(hooks, s.Hooks...)
+	hooks = append(hooks, other.Hooks...)
+
+	fileRules := make([]*FileRule, 0, len(s.FileRules)+len(other.FileRules))
+	fileRules = append(fileRules, s.FileRules...)
+	fileRules = append(fileRules, other.FileRules...)
+
+	schemas := make([]*ValuesSchema, 0, len(s.ValuesSchemas)+len(other.ValuesSchemas))
+	schemas = append(schemas, s.ValuesSchemas...)
+	schemas = append(schemas, other.ValuesSchemas...)
+
+	maxFileSize := minFileSize(s.MaxFileSize, other.MaxFileSize)
+
+	files := mergeFiles(s.Files, other.Files, fileRules)
+
+	if err := checkPartSizes(files, maxFileSize); err != nil {
+		return nil, fmt.Errorf("failed to merge skeleton %s and %s: %w", s.Ref, other.Ref, err)
+	}
+
 	return &Skeleton{
-		Values:      values,
-		Files:       MergeFiles(s.Files, other.Files),
-		Description: other.Description,
-		Ref:         other.Ref,
+		Values:        values,
+		Files:         files,
+		Hooks:         hooks,
+		FileRules:     fileRules,
+		Parameters:    mergeParameters(s.Parameters, other.Parameters),
+		ValuesSchemas: schemas,
+		Description:   other.Description,
+		Extends:       other.Extends,
+		Ref:           other.Ref,
+		MaxFileSize:   maxFileSize,
 	}, nil
 }
+
+// minFileSize returns the smaller of the file size limits a and b. Zero means
+// no limit.
+func minFileSize(a, b int64) int64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+
+	return a
+}
@@ -2,6 +2,8 @@ package kickoff
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/martinohmann/kickoff/internal/template"
 )
@@ -13,8 +15,170 @@ type SkeletonConfig struct {
 	// user-defined hints on the skeleton usage, e.g. interesting values to
 	// tweak.
 	Description string `json:"description,omitempty"`
+	// Requires holds the requirements for using the skeleton.
+	Requires Requirements `json:"requires,omitempty"`
+	// Extends holds the names of the skeletons this skeleton is based on.
+	// They are composed before the skeleton in the given order. Names
+	// without repository prefix refer to skeletons in the same repository.
+	Extends []string `json:"extends,omitempty"`
 	// Values holds user-defined values available in .skel templates.
 	Values template.Values `json:"values,omitempty"`
+	// Hooks holds commands that are run before and after project creation.
+	Hooks HookConfig `json:"hooks,omitempty"`
+	// Files holds rules for conditionally including skeleton files.
+	Files []*FileRule `json:"files,omitempty"`
+	// Parameters holds the values the user is asked for on project creation
+	// in the order they are prompted.
+	Parameters []*Parameter `json:"parameters,omitempty"`
+}
+
+// MergeStrategy defines how the content of a skeleton file is combined with
+// the file at the same path of a skeleton that was composed before it.
+type MergeStrategy string
+
+const (
+	// MergeReplace replaces the content of the file. This is the default.
+	MergeReplace MergeStrategy = "replace"
+	// MergeAppend appends the content to the existing content.
+	MergeAppend MergeStrategy = "append"
+	// MergePrepend prepends the content to the existing content.
+	MergePrepend MergeStrategy = "prepend"
+	// MergeAppendUniqueLines appends all lines that are not already present
+	// in the existing content, e.g. for .gitignore files.
+	MergeAppendUniqueLines MergeStrategy = "append-unique-lines"
+	// MergeStructured deep-merges YAML, JSON or TOML documents after each of them
+	// was rendered.
+	MergeStructured MergeStrategy = "structured"
+)
+
+var mergeStrategies = []MergeStrategy{MergeReplace, MergeAppend, MergePrepend, MergeAppendUniqueLines, MergeStructured}
+
+// FileRule conditionally includes the skeleton files matching a path,
+// renders them once for every item of a list or defines how they are merged
+// when skeletons are composed.
+type FileRule struct {
+	// Path is a path or glob pattern relative to the skeleton root. The
+	// .skel extension of templates may be omitted. If a directory matches,
+	// all files within it are matched as well.
+	Path string `json:"path"`
+	// If is a template expression that is evaluated against the template
+	// values, e.g. `.Values.docker` or `{{ .Values.docker }}`. Matching files
+	// are skipped if it evaluates to false.
+	If string `json:"if,omitempty"`
+	// ForEach is a template expression that must evaluate to a list, e.g.
+	// `.Values.endpoints`. Matching files are rendered once per list item.
+	// The current item is available as `.Item` in the file content and
+	// filename. Directories are not matched by ForEach.
+	ForEach string `json:"forEach,omitempty"`
+	// Merge is the merge strategy for matching files. It is applied if a
+	// skeleton that is composed later has a regular file at the same path.
+	// Directories, symlinks and binary files are always replaced.
+	Merge MergeStrategy `json:"merge,omitempty"`
+	// SkeletonRef contains the ref to the skeleton that defined the rule.
+	SkeletonRef *SkeletonRef `json:"-"`
+}
+
+// Validate implements the Validator interface.
+func (r *FileRule) Validate() error {
+	if r.Path == "" {
+		return newSkeletonConfigError("path of file rule must not be empty")
+	}
+
+	if filepath.IsAbs(r.Path) {
+		return newSkeletonConfigError("path of file rule must be relative, got %q", r.Path)
+	}
+
+	if _, err := filepath.Match(r.Path, ""); err != nil {
+		return newSkeletonConfigError("invalid path pattern %q in file rule: %v", r.Path, err)
+	}
+
+	if r.Merge != "" && !isMergeStrategy(r.Merge) {
+		return newSkeletonConfigError("invalid merge strategy %q for path %q, must be one of %v", r.Merge, r.Path, mergeStrategies)
+	}
+
+	if strings.TrimSpace(r.If) == "" && strings.TrimSpace(r.ForEach) == "" && r.Merge == "" {
+		return newSkeletonConfigError("file rule for path %q must have an if or forEach expression or a merge strategy", r.Path)
+	}
+
+	return nil
+}
+
+// MatchPath returns true if the rule's path pattern matches path or any of
+// its parent dirs. The .skel extension of template files is optional in the
+// pattern.
+func (r *FileRule) MatchPath(path string) bool {
+	pattern := filepath.Clean(r.Path)
+
+	for _, p := range []string{path, strings.TrimSuffix(path, SkeletonTemplateExtension)} {
+		for {
+			if ok, _ := filepath.Match(pattern, p); ok {
+				return true
+			}
+
+			if p = filepath.Dir(p); p == "." || p == "/" {
+				break
+			}
+		}
+	}
+
+	return false
+}
+
+func isMergeStrategy(strategy MergeStrategy) bool {
+	for _, s := range mergeStrategies {
+		if s == strategy {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate implements the Validator interface.
+func (c *SkeletonConfig) Validate() error {
+	if err := c.Requires.validate(); err != nil {
+		return newSkeletonConfigError("invalid kickoff version constraint %q: %w", c.Requires.Kickoff, err)
+	}
+
+	for _, name := range c.Extends {
+		if strings.TrimSpace(name) == "" {
+			return newSkeletonConfigError("extends must not contain empty skeleton names")
+		}
+	}
+
+	for _, rule := range c.Files {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+
+	names := make(map[string]bool, len(c.Parameters))
+
+	for _, param := range c.Parameters {
+		if err := param.Validate(); err != nil {
+			return err
+		}
+
+		if names[param.Name] {
+			return newSkeletonConfigError("duplicate parameter %q", param.Name)
+		}
+
+		names[param.Name] = true
+	}
+
+	return nil
+}
+
+// HookConfig holds the hook commands of a skeleton. The commands are
+// templated with the same values as .skel files and executed in the project
+// directory.
+type HookConfig struct {
+	// PreCreate holds commands that are run before the project files are
+	// written.
+	PreCreate []string `json:"preCreate,omitempty"`
+	// PostCreate holds commands that are run after the project files were
+	// written, e.g. `go mod tidy`.
+	PostCreate []string `json:"postCreate,omitempty"`
 }
 
 // LoadSkeletonConfig loads the skeleton config from path and returns it.
@@ -26,5 +190,9 @@ func LoadSkeletonConfig(path string) (*SkeletonConfig, error) {
 		return nil, fmt.Errorf("failed to load skeleton config: %w", err)
 	}
 
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
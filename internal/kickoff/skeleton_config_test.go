@@ -1,9 +1,11 @@
 package kickoff
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/martinohmann/kickoff/internal/template"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestLoadSkeletonConfig(t *testing.T) {
@@ -26,3 +28,80 @@ func TestLoadSkeletonConfig(t *testing.T) {
 		return LoadSkeletonConfig(path)
 	})
 }
+
+func TestSkeletonConfig_Validate(t *testing.T) {
+	testCases := []validatorTestCase{
+		{
+			name: "config with parameters",
+			v: &SkeletonConfig{
+				Parameters: []*Parameter{{Name: "foo"}, {Name: "bar", Type: ParameterBool}},
+			},
+		},
+		{
+			name: "config with invalid parameter",
+			v: &SkeletonConfig{
+				Parameters: []*Parameter{{Name: ""}},
+			},
+			err: newSkeletonConfigError(`parameter name "" does not match pattern: %s`, parameterNameRegexp),
+		},
+		{
+			name: "config with duplicate parameters",
+			v: &SkeletonConfig{
+				Parameters: []*Parameter{{Name: "foo"}, {Name: "foo"}},
+			},
+			err: newSkeletonConfigError(`duplicate parameter "foo"`),
+		},
+		{
+			name: "config with kickoff version constraint",
+			v:    &SkeletonConfig{Requires: Requirements{Kickoff: ">=0.6.0, <1.0.0"}},
+		},
+		{
+			name: "config with invalid kickoff version constraint",
+			v:    &SkeletonConfig{Requires: Requirements{Kickoff: ">=zero"}},
+			err:  newSkeletonConfigError(`invalid kickoff version constraint ">=zero": %w`, errors.New("improper constraint: >=zero")),
+		},
+		{
+			name: "config with extends",
+			v:    &SkeletonConfig{Extends: []string{"repo:base", "go"}},
+		},
+		{
+			name: "config with empty extends",
+			v:    &SkeletonConfig{Extends: []string{"go", " "}},
+			err:  newSkeletonConfigError("extends must not contain empty skeleton names"),
+		},
+	}
+
+	runValidatorTests(t, testCases)
+}
+
+func TestFileRule_Validate(t *testing.T) {
+	assert.NoError(t, (&FileRule{Path: "docs/*.md", If: ".Values.docs"}).Validate())
+	assert.EqualError(t, (&FileRule{If: ".Values.docs"}).Validate(), "invalid skeleton config: path of file rule must not be empty")
+	assert.EqualError(t, (&FileRule{Path: "/docs", If: ".Values.docs"}).Validate(), `invalid skeleton config: path of file rule must be relative, got "/docs"`)
+	assert.Error(t, (&FileRule{Path: "docs/[", If: ".Values.docs"}).Validate())
+	assert.Error(t, (&FileRule{Path: "docs", If: " "}).Validate())
+	assert.NoError(t, (&FileRule{Path: ".gitignore", Merge: MergeAppendUniqueLines}).Validate())
+	assert.EqualError(t, (&FileRule{Path: "Makefile", Merge: "concat"}).Validate(),
+		`invalid skeleton config: invalid merge strategy "concat" for path "Makefile", must be one of [replace append prepend append-unique-lines structured]`)
+}
+
+func TestFileRule_MatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		expected      bool
+	}{
+		{"Dockerfile", "Dockerfile", true},
+		{"Dockerfile", "Dockerfile.skel", true},
+		{"Dockerfile.skel", "Dockerfile.skel", true},
+		{"docs", "docs/index.md", true},
+		{"docs/*.md", "docs/index.md", true},
+		{"./docs/", "docs/index.md", true},
+		{"*.md", "docs/index.md", false},
+		{"doc", "docs/index.md", false},
+	}
+
+	for _, test := range tests {
+		rule := &FileRule{Path: test.pattern}
+		assert.Equal(t, test.expected, rule.MatchPath(test.path), "pattern %q, path %q", test.pattern, test.path)
+	}
+}
@@ -1,6 +1,7 @@
 package kickoff
 
 import (
+	"os"
 	"testing"
 
 	"github.com/martinohmann/kickoff/internal/template"
@@ -123,4 +124,128 @@ func TestMergeSkeletons(t *testing.T) {
 
 		assert.Equal(t, expectedFiles, s.Files)
 	})
+
+	t.Run("appends skeleton hooks in order", func(t *testing.T) {
+		ref0 := &SkeletonRef{Name: "s0"}
+		ref1 := &SkeletonRef{Name: "s1"}
+
+		s0 := &Skeleton{Hooks: NewHooks(HookConfig{PreCreate: []string{"pre0"}, PostCreate: []string{"post0"}}, ref0)}
+		s1 := &Skeleton{Hooks: NewHooks(HookConfig{PostCreate: []string{"post1a", "post1b"}}, ref1)}
+
+		s, err := MergeSkeletons(s0, s1)
+		require.NoError(t, err)
+
+		expectedHooks := []*Hook{
+			{Stage: HookPreCreate, Command: "pre0", SkeletonRef: ref0},
+			{Stage: HookPostCreate, Command: "post0", SkeletonRef: ref0},
+			{Stage: HookPostCreate, Command: "post1a", SkeletonRef: ref1},
+			{Stage: HookPostCreate, Command: "post1b", SkeletonRef: ref1},
+		}
+
+		assert.Equal(t, expectedHooks, s.Hooks)
+		assert.Len(t, s0.Hooks, 2)
+	})
+	t.Run("combines files according to merge strategy", func(t *testing.T) {
+		ref0 := &SkeletonRef{Name: "s0"}
+		ref1 := &SkeletonRef{Name: "s1"}
+		ref2 := &SkeletonRef{Name: "s2"}
+
+		gitignore0 := &BufferedFile{RelPath: ".gitignore", Content: []byte("bin/\n"), SkeletonRef: ref0}
+		gitignore1 := &BufferedFile{RelPath: ".gitignore", Content: []byte(".env\n"), SkeletonRef: ref1}
+		makefile0 := &BufferedFile{RelPath: "Makefile", Content: []byte("build:\n"), SkeletonRef: ref0}
+		makefile1 := &BufferedFile{RelPath: "Makefile", Content: []byte("docker:\n"), SkeletonRef: ref1}
+		makefile2 := &BufferedFile{RelPath: "Makefile", Content: []byte("include common.mk\n"), SkeletonRef: ref2}
+		readme0 := &BufferedFile{RelPath: "README.md", Content: []byte("readme"), SkeletonRef: ref0}
+		readme1 := &BufferedFile{RelPath: "README.md", Content: []byte("other readme"), SkeletonRef: ref1}
+
+		s0 := &Skeleton{
+			Files: []*BufferedFile{gitignore0, makefile0, readme0},
+			FileRules: []*FileRule{
+				{Path: ".gitignore", Merge: MergeAppendUniqueLines, SkeletonRef: ref0},
+				{Path: "Makefile", Merge: MergeAppend, SkeletonRef: ref0},
+			},
+		}
+		s1 := &Skeleton{Files: []*BufferedFile{gitignore1, makefile1, readme1}}
+		s2 := &Skeleton{
+			Files:     []*BufferedFile{makefile2},
+			FileRules: []*FileRule{{Path: "Makefile", Merge: MergePrepend, SkeletonRef: ref2}},
+		}
+
+		s, err := MergeSkeletons(s0, s1, s2)
+		require.NoError(t, err)
+		require.Len(t, s.Files, 3)
+
+		gitignore := s.Files[0]
+		assert.Equal(t, MergeAppendUniqueLines, gitignore.Merge)
+		assert.Equal(t, []*BufferedFile{gitignore0, gitignore1}, gitignore.Parts)
+		assert.Equal(t, []*SkeletonRef{ref0, ref1}, gitignore.MergedFrom)
+		assert.Nil(t, gitignore.Opener)
+		assert.Nil(t, gitignore.Content)
+
+		makefile := s.Files[1]
+		assert.Equal(t, MergePrepend, makefile.Merge)
+		require.Len(t, makefile.Parts, 2)
+		assert.Equal(t, MergeAppend, makefile.Parts[0].Merge)
+		assert.Equal(t, []*BufferedFile{makefile0, makefile1}, makefile.Parts[0].Parts)
+		assert.Same(t, makefile2, makefile.Parts[1])
+		assert.Equal(t, []*SkeletonRef{ref0, ref1, ref2}, makefile.MergedFrom)
+
+		assert.Same(t, readme1, s.Files[2])
+	})
+
+	t.Run("applies patch files to files of previous skeletons", func(t *testing.T) {
+		ref0 := &SkeletonRef{Name: "s0"}
+		ref1 := &SkeletonRef{Name: "s1"}
+
+		makefile := &BufferedFile{RelPath: "Makefile.skel", Content: []byte("build:\n"), SkeletonRef: ref0}
+		patch := &BufferedFile{RelPath: "Makefile.patch.skel", Content: []byte("@@ -1 +1 @@"), SkeletonRef: ref1}
+		orphan := &BufferedFile{RelPath: "fix.patch", Content: []byte("@@ -1 +1 @@"), SkeletonRef: ref1}
+		dir := &BufferedFile{RelPath: "docs", Mode: os.ModeDir, SkeletonRef: ref0}
+		dirPatch := &BufferedFile{RelPath: "docs.patch", Content: []byte("@@ -1 +1 @@"), SkeletonRef: ref1}
+
+		s0 := &Skeleton{Files: []*BufferedFile{makefile, dir}}
+		s1 := &Skeleton{Files: []*BufferedFile{patch, orphan, dirPatch}}
+
+		s, err := MergeSkeletons(s0, s1)
+		require.NoError(t, err)
+		require.Len(t, s.Files, 4)
+
+		patched := s.Files[0]
+		assert.Equal(t, "Makefile.skel", patched.RelPath)
+		assert.Equal(t, []byte("build:\n"), patched.Content)
+		assert.Equal(t, []*BufferedFile{patch}, patched.Patches)
+		assert.Equal(t, []*SkeletonRef{ref0, ref1}, patched.MergedFrom)
+		assert.Same(t, ref0, patched.SkeletonRef)
+		assert.Nil(t, makefile.Patches)
+
+		// Patches without a regular file to apply to are kept as is.
+		assert.Same(t, dir, s.Files[1])
+		assert.Same(t, dirPatch, s.Files[2])
+		assert.Same(t, orphan, s.Files[3])
+	})
+
+	t.Run("rejects merged files with parts larger than the limit", func(t *testing.T) {
+		ref0 := &SkeletonRef{Name: "s0"}
+		ref1 := &SkeletonRef{Name: "s1"}
+
+		s0 := &Skeleton{
+			Files:       []*BufferedFile{{RelPath: ".gitignore", Size: 5, SkeletonRef: ref0}},
+			FileRules:   []*FileRule{{Path: ".gitignore", Merge: MergeAppend, SkeletonRef: ref0}},
+			MaxFileSize: 10,
+		}
+		s1 := &Skeleton{
+			Files:       []*BufferedFile{{RelPath: ".gitignore", Size: 16, SkeletonRef: ref1}},
+			MaxFileSize: 10,
+		}
+
+		_, err := MergeSkeletons(s0, s1)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "file .gitignore of skeleton s1 too large: refusing to merge files larger than 10 bytes")
+
+		s1.FileRules = []*FileRule{{Path: ".gitignore", Merge: MergeReplace, SkeletonRef: ref1}}
+
+		s, err := MergeSkeletons(s0, s1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), s.MaxFileSize)
+	})
 }
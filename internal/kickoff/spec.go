@@ -0,0 +1,201 @@
+package kickoff
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/martinohmann/kickoff/internal/homedir"
+	"github.com/martinohmann/kickoff/internal/template"
+)
+
+// ProjectSpec describes the schema of a project spec file which declares all
+// options for creating a project non-interactively. Flags passed on project
+// creation take precedence over the fields of the spec.
+type ProjectSpec struct {
+	// Name is the project name.
+	Name string `json:"name"`
+	// Dir is the project directory. Defaults to $PWD/<name> if empty.
+	// Relative paths are resolved against the directory of the spec file.
+	Dir string `json:"dir,omitempty"`
+	// Host is the project host, e.g. github.com. Defaults to the host from
+	// the kickoff config if empty.
+	Host string `json:"host,omitempty"`
+	// Owner is the project owner, e.g. SCM username. Defaults to the owner
+	// from the kickoff config if empty.
+	Owner string `json:"owner,omitempty"`
+	// License holds the key of the open source license, if any.
+	License string `json:"license,omitempty"`
+	// Gitignore holds the comma-separated list of gitignore templates, if
+	// any.
+	Gitignore string `json:"gitignore,omitempty"`
+	// Repositories holds a map of additional repositories to search for the
+	// skeletons. They are added to the repositories from the kickoff config.
+	Repositories map[string]string `json:"repositories,omitempty"`
+	// Skeletons holds the names of the skeletons the project is created from
+	// in composition order, optionally prefixed with a repository name, e.g.
+	// 'myrepo:myskeleton'.
+	Skeletons []string `json:"skeletons"`
+	// Values holds values that are merged on top of the skeleton values and
+	// the values from the kickoff config.
+	Values template.Values `json:"values,omitempty"`
+	// Overwrite overwrites files that are already present in the project
+	// directory if true.
+	Overwrite bool `json:"overwrite,omitempty"`
+	// OverwriteFiles holds paths relative to the project directory of files
+	// that should be overwritten if present.
+	OverwriteFiles []string `json:"overwriteFiles,omitempty"`
+	// SkipFiles holds paths relative to the project directory of files that
+	// should not be written.
+	SkipFiles []string `json:"skipFiles,omitempty"`
+	// Monorepo creates the project as a sub-project of the git repository
+	// that encloses Dir if true. See the --monorepo flag of the create
+	// command.
+	Monorepo bool `json:"monorepo,omitempty"`
+	// Git configures the git repository of the project. Ignored if Monorepo
+	// is true.
+	Git GitSpec `json:"git,omitempty"`
+}
+
+// GitSpec configures the git repository of a project created from a
+// ProjectSpec.
+type GitSpec struct {
+	GitConfig
+	// Init initializes git in the project directory. Defaults to true if
+	// nil.
+	Init *bool `json:"init,omitempty"`
+}
+
+// Validate implements the Validator interface.
+func (s *ProjectSpec) Validate() error {
+	if s.Name == "" {
+		return newProjectSpecError("name must not be empty")
+	}
+
+	if len(s.Skeletons) == 0 {
+		return newProjectSpecError("skeletons must not be empty")
+	}
+
+	for _, name := range s.Skeletons {
+		if strings.TrimSpace(name) == "" {
+			return newProjectSpecError("skeleton names must not be empty")
+		}
+	}
+
+	if s.Host != "" {
+		if _, err := url.Parse(s.Host); err != nil {
+			return newProjectSpecError("invalid host: %w", err)
+		}
+	}
+
+	return validateSpecRepositories(s.Repositories, newProjectSpecError)
+}
+
+func validateSpecRepositories(repos map[string]string, newError func(string, ...interface{}) *ValidationError) error {
+	for name, repoURL := range repos {
+		if !repoNameRegexp.MatchString(name) {
+			return newError("repository name %q does not match pattern: %s", name, repoNameRegexp)
+		}
+
+		if repoURL == "" {
+			return newError("URL of repository %q must not be empty", name)
+		}
+
+		if _, err := url.Parse(repoURL); err != nil {
+			return newError("invalid URL of repository %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// resolveDir resolves a relative Dir against baseDir. A leading `~` is
+// expanded to the home directory.
+func (s *ProjectSpec) resolveDir(baseDir string) {
+	if s.Dir == "" {
+		return
+	}
+
+	dir := homedir.Expand(s.Dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(baseDir, dir)
+	}
+
+	s.Dir = dir
+}
+
+// LoadProjectSpec loads the project spec from path and returns it. A relative
+// Dir is resolved against the directory of path. The spec is not validated as
+// the caller may override its fields first, see (*ProjectSpec).Validate.
+func LoadProjectSpec(path string) (*ProjectSpec, error) {
+	var spec ProjectSpec
+
+	if err := Load(path, &spec); err != nil {
+		return nil, fmt.Errorf("failed to load project spec: %w", err)
+	}
+
+	spec.resolveDir(filepath.Dir(path))
+
+	return &spec, nil
+}
+
+// ProjectManifest describes the schema of a manifest file which declares
+// multiple projects that are created in one batch.
+type ProjectManifest struct {
+	// Repositories holds a map of additional repositories to search for the
+	// skeletons of all projects. They are added to the repositories from the
+	// kickoff config.
+	Repositories map[string]string `json:"repositories,omitempty"`
+	// Projects holds the specs of the projects to create.
+	Projects []*ProjectSpec `json:"projects"`
+}
+
+// Validate implements the Validator interface.
+func (m *ProjectManifest) Validate() error {
+	if len(m.Projects) == 0 {
+		return newProjectManifestError("projects must not be empty")
+	}
+
+	if err := validateSpecRepositories(m.Repositories, newProjectManifestError); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(m.Projects))
+
+	for i, spec := range m.Projects {
+		if err := spec.Validate(); err != nil {
+			return newProjectManifestError("project #%d: %w", i+1, err)
+		}
+
+		if names[spec.Name] {
+			return newProjectManifestError("duplicate project name %q", spec.Name)
+		}
+
+		names[spec.Name] = true
+	}
+
+	return nil
+}
+
+// LoadProjectManifest loads the project manifest from path and returns it.
+// Relative project dirs are resolved against the directory of path.
+func LoadProjectManifest(path string) (*ProjectManifest, error) {
+	var manifest ProjectManifest
+
+	if err := Load(path, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to load project manifest: %w", err)
+	}
+
+	for _, spec := range manifest.Projects {
+		if spec != nil {
+			spec.resolveDir(filepath.Dir(path))
+		}
+	}
+
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
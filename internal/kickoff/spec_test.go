@@ -0,0 +1,144 @@
+package kickoff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/martinohmann/kickoff/internal/template"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectSpec_Validate(t *testing.T) {
+	testCases := []validatorTestCase{
+		{
+			name: "empty name is invalid",
+			v:    &ProjectSpec{},
+			err:  newProjectSpecError("name must not be empty"),
+		},
+		{
+			name: "empty skeletons are invalid",
+			v:    &ProjectSpec{Name: "myproject"},
+			err:  newProjectSpecError("skeletons must not be empty"),
+		},
+		{
+			name: "empty skeleton name is invalid",
+			v:    &ProjectSpec{Name: "myproject", Skeletons: []string{"default", " "}},
+			err:  newProjectSpecError("skeleton names must not be empty"),
+		},
+		{
+			name: "invalid repository name",
+			v: &ProjectSpec{
+				Name:         "myproject",
+				Skeletons:    []string{"default"},
+				Repositories: map[string]string{"invalid:": "/tmp/foo"},
+			},
+			err: newProjectSpecError(`repository name "invalid:" does not match pattern: ^[a-zA-Z0-9_/.+-]+$`),
+		},
+		{
+			name: "empty repository URL",
+			v: &ProjectSpec{
+				Name:         "myproject",
+				Skeletons:    []string{"default"},
+				Repositories: map[string]string{"myrepo": ""},
+			},
+			err: newProjectSpecError(`URL of repository "myrepo" must not be empty`),
+		},
+		{
+			name: "valid spec",
+			v: &ProjectSpec{
+				Name:         "myproject",
+				Skeletons:    []string{"myrepo:default"},
+				Repositories: map[string]string{"myrepo": "/tmp/foo"},
+			},
+		},
+	}
+
+	runValidatorTests(t, testCases)
+}
+
+func TestLoadProjectSpec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "project.yaml")
+
+	require.NoError(t, os.WriteFile(path, []byte(`name: myproject
+dir: services/myproject
+skeletons:
+- myrepo:default
+values:
+  foo: bar
+git:
+  init: false
+  branch: main
+`), 0644))
+
+	init := false
+
+	expected := &ProjectSpec{
+		Name:      "myproject",
+		Dir:       filepath.Join(filepath.Dir(path), "services/myproject"),
+		Skeletons: []string{"myrepo:default"},
+		Values:    template.Values{"foo": "bar"},
+		Git: GitSpec{
+			GitConfig: GitConfig{Branch: "main"},
+			Init:      &init,
+		},
+	}
+
+	spec, err := LoadProjectSpec(path)
+	require.NoError(t, err)
+	require.Equal(t, expected, spec)
+
+	_, err = LoadProjectSpec(filepath.Join(t.TempDir(), "nonexistent.yaml"))
+	require.Error(t, err)
+
+	// Validation is up to the caller as name and skeletons may be provided
+	// via arguments.
+	require.NoError(t, os.WriteFile(path, []byte("dir: /tmp/myproject\n"), 0644))
+
+	spec, err = LoadProjectSpec(path)
+	require.NoError(t, err)
+	require.Equal(t, &ProjectSpec{Dir: "/tmp/myproject"}, spec)
+}
+
+func TestProjectManifest_Validate(t *testing.T) {
+	testCases := []validatorTestCase{
+		{
+			name: "empty projects are invalid",
+			v:    &ProjectManifest{},
+			err:  newProjectManifestError("projects must not be empty"),
+		},
+		{
+			name: "invalid project spec",
+			v:    &ProjectManifest{Projects: []*ProjectSpec{{Name: "myproject", Skeletons: []string{"default"}}, {}}},
+			err:  newProjectManifestError("project #2: invalid project spec: name must not be empty"),
+		},
+		{
+			name: "duplicate project name",
+			v: &ProjectManifest{Projects: []*ProjectSpec{
+				{Name: "myproject", Skeletons: []string{"default"}},
+				{Name: "myproject", Skeletons: []string{"other"}},
+			}},
+			err: newProjectManifestError(`duplicate project name "myproject"`),
+		},
+		{
+			name: "invalid repository",
+			v: &ProjectManifest{
+				Repositories: map[string]string{"myrepo": ""},
+				Projects:     []*ProjectSpec{{Name: "myproject", Skeletons: []string{"default"}}},
+			},
+			err: newProjectManifestError(`URL of repository "myrepo" must not be empty`),
+		},
+		{
+			name: "valid manifest",
+			v: &ProjectManifest{
+				Repositories: map[string]string{"myrepo": "/tmp/foo"},
+				Projects: []*ProjectSpec{
+					{Name: "myproject", Skeletons: []string{"myrepo:default"}},
+					{Name: "otherproject", Skeletons: []string{"myrepo:default"}},
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, testCases)
+}
@@ -0,0 +1,390 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Apply applies the plan. It will write all necessary project files to the
+// target directory.
+//
+// Applying the plan is transactional: all files are first written to a
+// staging directory. Only if that succeeded, they are moved into the target
+// directory. Files that are overwritten are backed up beforehand. If an error
+// occurs or ctx is cancelled while moving the files, all changes to the
+// target directory are rolled back.
+func (p *Plan) Apply(ctx context.Context) (err error) {
+	tx, err := newTransaction(p.projectDir)
+	if err != nil {
+		return err
+	}
+	defer tx.cleanup()
+
+	if err := p.stage(ctx, tx); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		if rerr := tx.rollback(); rerr != nil {
+			err = fmt.Errorf("%w; additionally, rolling back changes failed: %v", err, rerr)
+		}
+	}()
+
+	for _, op := range p.Operations {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := tx.commit(op); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stage writes the content of all files that need to be created or changed
+// into the staging directory of tx. Content that is not buffered in memory is
+// streamed from the source files.
+func (p *Plan) stage(ctx context.Context, tx *transaction) error {
+	for _, op := range p.Operations {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if !op.writesFile() {
+			continue
+		}
+
+		path := txPath(tx.stagingDir, op.Dest)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		if op.Source.IsSymlink() {
+			if err := os.Symlink(string(op.Content), path); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := writeFile(path, op); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeFile writes the content of op to path.
+func writeFile(path string, op *Operation) error {
+	r, err := op.open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, op.Source.Mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// change is a change to the target directory that was made while committing
+// a transaction.
+type change struct {
+	// path is the absolute path of the created or replaced file or directory.
+	path string
+	// backup is the path to the backup of the replaced file. Empty if path
+	// was newly created.
+	backup string
+}
+
+// transaction keeps track of the changes made to the target directory so
+// that they can be rolled back.
+type transaction struct {
+	stagingDir string
+	backupDir  string
+	changes    []change
+	// keepBackups is set if a backup could not be restored during rollback.
+	keepBackups bool
+}
+
+// newTransaction creates the staging and backup directories for a
+// transaction targeting dir. Both are preferably created next to dir so that
+// files can be moved into dir atomically via rename. If that fails, e.g.
+// because the parent of dir is read-only, they are created inside of dir if
+// it exists, or in the system's temporary directory as a last resort.
+func newTransaction(dir string) (*transaction, error) {
+	parent, err := existingAncestor(filepath.Dir(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []string{parent}
+
+	if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+		candidates = append(candidates, dir)
+	}
+
+	candidates = append(candidates, os.TempDir())
+
+	var tx *transaction
+
+	for _, baseDir := range candidates {
+		tx, err = newTransactionIn(baseDir)
+		if err == nil {
+			return tx, nil
+		}
+
+		log.WithError(err).WithField("path", baseDir).Debug("failed to create transaction directories")
+	}
+
+	return nil, err
+}
+
+// newTransactionIn creates the staging and backup directories of a new
+// transaction in baseDir.
+func newTransactionIn(baseDir string) (*transaction, error) {
+	stagingDir, err := os.MkdirTemp(baseDir, ".kickoff-staging-")
+	if err != nil {
+		return nil, err
+	}
+
+	backupDir, err := os.MkdirTemp(baseDir, ".kickoff-backup-")
+	if err != nil {
+		os.RemoveAll(stagingDir)
+		return nil, err
+	}
+
+	return &transaction{stagingDir: stagingDir, backupDir: backupDir}, nil
+}
+
+// commit moves the staged file or symlink of op into the target directory or
+// creates the destination directory. Existing files are moved to the backup
+// directory first.
+func (tx *transaction) commit(op *Operation) error {
+	if op.skips() {
+		return nil
+	}
+
+	dest := op.Dest.AbsPath()
+
+	if op.Source.Mode.IsDir() {
+		return tx.mkdirAll(dest, op.Source.Mode)
+	}
+
+	if err := tx.mkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	c := change{path: dest}
+
+	if _, err := os.Lstat(dest); err == nil {
+		c.backup = txPath(tx.backupDir, op.Dest)
+
+		if err := os.MkdirAll(filepath.Dir(c.backup), 0755); err != nil {
+			return err
+		}
+
+		if err := rename(dest, c.backup); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	// Record the change before moving the staged file so that the backup
+	// is restored on rollback even if the rename fails.
+	tx.changes = append(tx.changes, c)
+
+	return rename(txPath(tx.stagingDir, op.Dest), dest)
+}
+
+// rename moves the file or symlink at oldpath to newpath. If both paths are
+// located on different filesystems, oldpath is copied to newpath and removed
+// afterwards.
+func rename(oldpath, newpath string) error {
+	err := os.Rename(oldpath, newpath)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	fi, lerr := os.Lstat(oldpath)
+	if lerr != nil || fi.IsDir() {
+		return err
+	}
+
+	if err := copyFile(oldpath, newpath, fi); err != nil {
+		return err
+	}
+
+	return os.Remove(oldpath)
+}
+
+// copyFile copies the file or symlink at src to dst. fi must describe src.
+func copyFile(src, dst string, fi os.FileInfo) error {
+	if fi.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+
+		return os.Symlink(target, dst)
+	}
+
+	r, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// txPath returns the path for dest within dir, which is either the staging
+// or the backup directory of a transaction. Destinations are keyed by their
+// absolute path as they are not necessarily located inside of the project
+// directory.
+func txPath(dir string, dest *Destination) string {
+	path, err := filepath.Abs(dest.AbsPath())
+	if err != nil {
+		path = dest.AbsPath()
+	}
+
+	return filepath.Join(dir, strings.TrimPrefix(path, filepath.VolumeName(path)))
+}
+
+// mkdirAll is like os.MkdirAll, but records every directory it creates.
+// Directories that already exist or are created concurrently by someone else
+// are not recorded.
+func (tx *transaction) mkdirAll(path string, mode os.FileMode) error {
+	fi, err := os.Stat(path)
+	if err == nil {
+		if !fi.IsDir() {
+			return fmt.Errorf("%s exists but is not a directory", path)
+		}
+
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := tx.mkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if err := os.Mkdir(path, mode); errors.Is(err, fs.ErrExist) {
+		// The directory was created concurrently, e.g. by another project of
+		// the same batch. It is not ours to remove on rollback.
+		return tx.mkdirAll(path, mode)
+	} else if err != nil {
+		return err
+	}
+
+	tx.changes = append(tx.changes, change{path: path})
+
+	return nil
+}
+
+// rollback reverts all changes in reverse order: created files and
+// directories are removed and backups are restored. If a backup cannot be
+// restored, the backup directory is kept so that the file can be recovered by
+// hand and the error contains the path of the backup.
+func (tx *transaction) rollback() error {
+	var firstErr error
+
+	for i := len(tx.changes) - 1; i >= 0; i-- {
+		c := tx.changes[i]
+
+		log.WithField("path", c.path).Debug("rolling back change")
+
+		var err error
+		if c.backup != "" {
+			if err = rename(c.backup, c.path); err != nil {
+				tx.keepBackups = true
+				err = fmt.Errorf("failed to restore %s, a backup of the original file is available at %s: %w", c.path, c.backup, err)
+			}
+		} else {
+			err = os.Remove(c.path)
+			if errors.Is(err, os.ErrNotExist) {
+				err = nil
+			}
+		}
+
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// cleanup removes the staging and backup directories. The backup directory
+// is kept if restoring a backup failed during rollback.
+func (tx *transaction) cleanup() {
+	dirs := []string{tx.stagingDir}
+
+	if tx.keepBackups {
+		log.WithField("path", tx.backupDir).Warn("keeping backups of files that could not be restored")
+	} else {
+		dirs = append(dirs, tx.backupDir)
+	}
+
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			log.WithError(err).WithField("path", dir).Warn("failed to remove temporary directory")
+		}
+	}
+}
+
+// existingAncestor returns path or its closest ancestor that exists.
+func existingAncestor(path string) (string, error) {
+	for {
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+
+		path = parent
+	}
+}
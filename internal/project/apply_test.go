@@ -0,0 +1,287 @@
+package project
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan_Apply(t *testing.T) {
+	ref := &kickoff.SkeletonRef{Name: "default"}
+
+	t.Run("nothing is written if context is cancelled", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "myproject")
+
+		plan, err := MakePlan(&Config{
+			Name:       "myproject",
+			ProjectDir: dir,
+			Skeleton: &kickoff.Skeleton{
+				Files: []*kickoff.BufferedFile{
+					{RelPath: "a.txt", Content: []byte("a"), Mode: 0644, SkeletonRef: ref},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.ErrorIs(t, plan.Apply(ctx), context.Canceled)
+		assert.NoDirExists(t, dir)
+		assertNoTempDirs(t, filepath.Dir(dir))
+	})
+
+	t.Run("changes are rolled back on failure", func(t *testing.T) {
+		dir := t.TempDir()
+		tester := &dirTester{T: t, dir: dir}
+
+		tester.mustWriteFile("a.txt", "old")
+		// A file where the skeleton expects a directory makes the last
+		// operation fail after the others were already committed.
+		tester.mustWriteFile("z", "not a directory")
+
+		plan, err := MakePlan(&Config{
+			Name:       "myproject",
+			ProjectDir: dir,
+			Overwrite:  true,
+			Skeleton: &kickoff.Skeleton{
+				Files: []*kickoff.BufferedFile{
+					{RelPath: "a.txt", Content: []byte("new"), Mode: 0644, SkeletonRef: ref},
+					{RelPath: "b/b.txt", Content: []byte("b"), Mode: 0644, SkeletonRef: ref},
+					{RelPath: "z/file.txt", Content: []byte("z"), Mode: 0644, SkeletonRef: ref},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		require.Error(t, plan.Apply(context.Background()))
+
+		tester.assertFileContains("a.txt", "old")
+		tester.assertFileContains("z", "not a directory")
+		tester.assertFileAbsent("b")
+		assertNoTempDirs(t, filepath.Dir(dir))
+	})
+
+	t.Run("overwrites files on success", func(t *testing.T) {
+		dir := t.TempDir()
+		tester := &dirTester{T: t, dir: dir}
+
+		tester.mustWriteFile("a.txt", "old")
+
+		plan, err := MakePlan(&Config{
+			Name:       "myproject",
+			ProjectDir: dir,
+			Overwrite:  true,
+			Skeleton: &kickoff.Skeleton{
+				Files: []*kickoff.BufferedFile{
+					{RelPath: "a.txt", Content: []byte("new"), Mode: 0644, SkeletonRef: ref},
+					{RelPath: "b/b.txt", Content: []byte("b"), Mode: 0644, SkeletonRef: ref},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, plan.Apply(context.Background()))
+
+		tester.assertFileContains("a.txt", "new")
+		tester.assertFileContains("b/b.txt", "b")
+		assertNoTempDirs(t, filepath.Dir(dir))
+	})
+}
+
+func TestPlan_Apply_ReadOnlyParent(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "myproject")
+	tester := &dirTester{T: t, dir: dir}
+
+	tester.mustWriteFile("a.txt", "old")
+
+	require.NoError(t, os.Chmod(parent, 0555))
+	t.Cleanup(func() { os.Chmod(parent, 0755) })
+
+	if f, err := os.CreateTemp(parent, "probe-"); err == nil {
+		f.Close()
+		os.Remove(f.Name())
+		t.Skip("directory permissions are not enforced, e.g. when running as root")
+	}
+
+	ref := &kickoff.SkeletonRef{Name: "default"}
+
+	plan, err := MakePlan(&Config{
+		Name:       "myproject",
+		ProjectDir: dir,
+		Overwrite:  true,
+		Skeleton: &kickoff.Skeleton{
+			Files: []*kickoff.BufferedFile{
+				{RelPath: "a.txt", Content: []byte("new"), Mode: 0644, SkeletonRef: ref},
+				{RelPath: "b/b.txt", Content: []byte("b"), Mode: 0644, SkeletonRef: ref},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, plan.Apply(context.Background()))
+
+	tester.assertFileContains("a.txt", "new")
+	tester.assertFileContains("b/b.txt", "b")
+	assertNoTempDirs(t, dir)
+}
+
+func TestTransaction_RollbackKeepsBackupOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	tester := &dirTester{T: t, dir: dir}
+
+	tester.mustWriteFile("a.txt", "old")
+
+	plan, err := MakePlan(&Config{
+		Name:       "myproject",
+		ProjectDir: dir,
+		Overwrite:  true,
+		Skeleton: &kickoff.Skeleton{
+			Files: []*kickoff.BufferedFile{
+				{RelPath: "a.txt", Content: []byte("new"), Mode: 0644, SkeletonRef: &kickoff.SkeletonRef{Name: "default"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	tx, err := newTransaction(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, plan.stage(context.Background(), tx))
+	require.NoError(t, tx.commit(plan.Operations[0]))
+
+	// A non-empty directory in place of the committed file makes restoring
+	// the backup fail.
+	require.NoError(t, os.Remove(filepath.Join(dir, "a.txt")))
+	tester.mustWriteFile("a.txt/other", "other")
+
+	backup := txPath(tx.backupDir, plan.Operations[0].Dest)
+
+	err = tx.rollback()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), backup)
+
+	tx.cleanup()
+
+	content, err := os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(content))
+	require.NoDirExists(t, tx.stagingDir)
+
+	require.NoError(t, os.RemoveAll(tx.backupDir))
+}
+
+func TestTransaction_MkdirAll(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "services")
+
+	tx1, err := newTransaction(dir)
+	require.NoError(t, err)
+	defer tx1.cleanup()
+
+	tx2, err := newTransaction(dir)
+	require.NoError(t, err)
+	defer tx2.cleanup()
+
+	require.NoError(t, tx1.mkdirAll(filepath.Join(dir, "a"), 0755))
+	require.NoError(t, tx2.mkdirAll(filepath.Join(dir, "b"), 0755))
+
+	// Only directories created by the transaction itself are recorded, the
+	// shared parent belongs to the first transaction.
+	assert.Len(t, tx1.changes, 2)
+	assert.Len(t, tx2.changes, 1)
+
+	require.NoError(t, tx2.rollback())
+	assert.DirExists(t, filepath.Join(dir, "a"))
+	assert.NoDirExists(t, filepath.Join(dir, "b"))
+}
+
+func assertNoTempDirs(t *testing.T, dir string) {
+	matches, err := filepath.Glob(filepath.Join(dir, ".kickoff-*"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestPlan_Apply_Symlinks(t *testing.T) {
+	dir := t.TempDir()
+	tester := &dirTester{T: t, dir: dir}
+
+	makeConfig := func(target string) *Config {
+		return &Config{
+			Name:       "myproject",
+			ProjectDir: dir,
+			Overwrite:  true,
+			Values:     template.Values{"readme": "README.md"},
+			Skeleton: &kickoff.Skeleton{
+				Files: []*kickoff.BufferedFile{
+					{RelPath: "AGENTS.md", Mode: 0777 | os.ModeSymlink, LinkTarget: target},
+					{RelPath: "README.md", Content: []byte("readme"), Mode: 0644},
+				},
+			},
+		}
+	}
+
+	plan, err := MakePlan(makeConfig("{{.Values.readme}}"))
+	require.NoError(t, err)
+	require.NoError(t, plan.Apply(context.Background()))
+
+	target, err := os.Readlink(tester.path("AGENTS.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "README.md", target)
+	tester.assertFileContains("AGENTS.md", "readme")
+
+	plan, err = MakePlan(makeConfig("{{.Values.readme}}"))
+	require.NoError(t, err)
+	assert.Equal(t, OpOverwrite, plan.Operations[0].Type)
+	assert.Equal(t, "link:README.md", plan.Operations[0].DestState)
+	require.NoError(t, plan.Apply(context.Background()))
+
+	_, err = MakePlan(makeConfig("../{{.Values.readme}}"))
+	require.EqualError(t, err, `failed to render template AGENTS.md: symlink target "../README.md" escapes the root directory`)
+}
+
+func TestPlan_Apply_StreamsContent(t *testing.T) {
+	dir := t.TempDir()
+	tester := &dirTester{T: t, dir: dir}
+
+	open := func(content string) func() (io.ReadCloser, error) {
+		return func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(content)), nil
+		}
+	}
+
+	plan, err := MakePlan(&Config{
+		Name:       "myproject",
+		ProjectDir: dir,
+		Skeleton: &kickoff.Skeleton{
+			Files: []*kickoff.BufferedFile{
+				{RelPath: "asset.bin.skel", Opener: open("{{.Project.Name}}\x00"), Binary: true, Mode: 0644},
+				{RelPath: "large.txt", Opener: open("streamed"), Mode: 0644},
+				{RelPath: "README.md.skel", Opener: open("# {{.Project.Name}}"), Mode: 0644},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	for _, op := range plan.Operations {
+		if op.Source.RelPath == "README.md.skel" {
+			assert.Equal(t, "# myproject", string(op.Content))
+		} else {
+			assert.Nil(t, op.Content, op.Source.RelPath)
+		}
+	}
+
+	require.NoError(t, plan.Apply(context.Background()))
+
+	tester.assertFileContains("asset.bin", "{{.Project.Name}}\x00")
+	tester.assertFileContains("large.txt", "streamed")
+	tester.assertFileContains("README.md", "# myproject")
+}
@@ -0,0 +1,358 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/martinohmann/kickoff/internal/kickoff"
+)
+
+// planVersion is the version of the serialized plan format. It must be
+// incremented on incompatible changes.
+const planVersion = 1
+
+// destStateDir is the destination state of directories.
+const destStateDir = "dir"
+
+// destStateLinkPrefix prefixes the link target in the destination state of
+// symlinks.
+const destStateLinkPrefix = "link:"
+
+var opTypeNames = map[OpType]string{
+	OpCreate:       "create",
+	OpSkipExisting: "skip-existing",
+	OpSkipUser:     "skip-user",
+	OpOverwrite:    "overwrite",
+	OpMerge:        "merge",
+	OpSkipSkeleton: "skip-skeleton",
+}
+
+// String implements fmt.Stringer.
+func (t OpType) String() string {
+	if name, ok := opTypeNames[t]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("OpType(%d)", t)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t OpType) MarshalText() ([]byte, error) {
+	if _, ok := opTypeNames[t]; !ok {
+		return nil, fmt.Errorf("invalid operation type %d", t)
+	}
+
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *OpType) UnmarshalText(text []byte) error {
+	for opType, name := range opTypeNames {
+		if name == string(text) {
+			*t = opType
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid operation type %q", string(text))
+}
+
+type planJSON struct {
+	Version    int          `json:"version"`
+	ProjectDir string       `json:"projectDir"`
+	RepoRoot   string       `json:"repoRoot,omitempty"`
+	URL        string       `json:"url,omitempty"`
+	Operations []*Operation `json:"operations"`
+	Hooks      []*hookJSON  `json:"hooks,omitempty"`
+}
+
+type hookJSON struct {
+	Stage       kickoff.HookStage    `json:"stage"`
+	Command     string               `json:"command"`
+	SkeletonRef *kickoff.SkeletonRef `json:"skeletonRef,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p *Plan) MarshalJSON() ([]byte, error) {
+	v := &planJSON{
+		Version:    planVersion,
+		ProjectDir: p.projectDir,
+		RepoRoot:   p.repoRoot,
+		URL:        p.url,
+		Operations: p.Operations,
+	}
+
+	for _, hook := range p.Hooks {
+		v.Hooks = append(v.Hooks, &hookJSON{
+			Stage:       hook.Stage,
+			Command:     hook.Command,
+			SkeletonRef: hook.SkeletonRef,
+		})
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Only the information that is
+// needed to verify and apply the plan is restored.
+func (p *Plan) UnmarshalJSON(data []byte) error {
+	var v planJSON
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	if v.Version != planVersion {
+		return fmt.Errorf("unsupported plan version %d, expected %d", v.Version, planVersion)
+	}
+
+	if v.ProjectDir == "" {
+		return errors.New("project directory of plan must not be empty")
+	}
+
+	p.projectDir = v.ProjectDir
+	p.repoRoot = v.RepoRoot
+	p.url = v.URL
+	p.Operations = v.Operations
+	p.OpCounts = make(map[OpType]int)
+
+	for _, op := range p.Operations {
+		p.OpCounts[op.Type]++
+	}
+
+	if err := p.checkDestinations(); err != nil {
+		return err
+	}
+
+	p.Hooks = nil
+
+	for _, hook := range v.Hooks {
+		if hook.Stage != kickoff.HookPreCreate && hook.Stage != kickoff.HookPostCreate {
+			return fmt.Errorf("invalid hook stage %q", hook.Stage)
+		}
+
+		p.Hooks = append(p.Hooks, &kickoff.Hook{
+			Stage:       hook.Stage,
+			Command:     hook.Command,
+			SkeletonRef: hook.SkeletonRef,
+		})
+	}
+
+	return nil
+}
+
+// checkDestinations guards against tampered plans by ensuring that the
+// destinations of all operations are located inside of the project directory.
+// In monorepo mode, the .gitignore in the repository root is allowed as well.
+func (p *Plan) checkDestinations() error {
+	var rootGitignore string
+
+	if p.repoRoot != "" {
+		if !isWithin(p.repoRoot, p.projectDir) {
+			return fmt.Errorf("project directory %s is not located inside of the repository root %s", p.projectDir, p.repoRoot)
+		}
+
+		rootGitignore = filepath.Join(p.repoRoot, ".gitignore")
+	}
+
+	for _, op := range p.Operations {
+		path := op.Dest.AbsPath()
+
+		if path == rootGitignore || isWithin(p.projectDir, path) {
+			continue
+		}
+
+		return fmt.Errorf("destination %s is outside of the project directory %s", path, p.projectDir)
+	}
+
+	return nil
+}
+
+// isWithin returns true if path is dir or is located inside of dir.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+type sourceJSON struct {
+	RelPath     string                 `json:"relPath"`
+	Mode        os.FileMode            `json:"mode"`
+	SkeletonRef *kickoff.SkeletonRef   `json:"skeletonRef,omitempty"`
+	MergedFrom  []*kickoff.SkeletonRef `json:"mergedFrom,omitempty"`
+}
+
+type operationJSON struct {
+	Type      OpType       `json:"type"`
+	Source    *sourceJSON  `json:"source"`
+	Dest      *Destination `json:"dest"`
+	DestState string       `json:"destState,omitempty"`
+	Content   []byte       `json:"content,omitempty"`
+	Digest    string       `json:"digest,omitempty"`
+	Conflicts int          `json:"conflicts,omitempty"`
+	Reason    string       `json:"reason,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. Only the path, mode and skeleton
+// refs of the source file are included. The content that will be written to
+// the destination is included together with its digest. Content that is not
+// buffered in memory is read from the source file.
+func (op *Operation) MarshalJSON() ([]byte, error) {
+	v := &operationJSON{
+		Type: op.Type,
+		Source: &sourceJSON{
+			RelPath:     op.Source.RelPath,
+			Mode:        op.Source.Mode,
+			SkeletonRef: op.Source.SkeletonRef,
+			MergedFrom:  op.Source.MergedFrom,
+		},
+		Dest:      op.Dest,
+		DestState: op.DestState,
+		Conflicts: op.Conflicts,
+		Reason:    op.Reason,
+	}
+
+	if op.writesFile() {
+		content, err := op.ReadContent()
+		if err != nil {
+			return nil, err
+		}
+
+		v.Content = content
+		v.Digest = digest(content)
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Returns an error if the content
+// does not match its digest.
+func (op *Operation) UnmarshalJSON(data []byte) error {
+	var v operationJSON
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	if v.Source == nil || v.Dest == nil {
+		return errors.New("operation source and dest must not be empty")
+	}
+
+	*op = Operation{
+		Type: v.Type,
+		Source: &kickoff.BufferedFile{
+			RelPath:     v.Source.RelPath,
+			Mode:        v.Source.Mode,
+			SkeletonRef: v.Source.SkeletonRef,
+			MergedFrom:  v.Source.MergedFrom,
+		},
+		Dest:      v.Dest,
+		DestState: v.DestState,
+		Content:   v.Content,
+		Conflicts: v.Conflicts,
+		Reason:    v.Reason,
+	}
+
+	if op.writesFile() && digest(op.Content) != v.Digest {
+		return fmt.Errorf("content of %s does not match its digest", op.Dest.RelPath())
+	}
+
+	return nil
+}
+
+// LoadPlan loads a plan that was serialized to JSON or YAML from path.
+func LoadPlan(path string) (*Plan, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan Plan
+
+	if err := yaml.Unmarshal(buf, &plan); err != nil {
+		return nil, fmt.Errorf("failed to load plan: %w", err)
+	}
+
+	return &plan, nil
+}
+
+// OutdatedError is returned by (*Plan).Verify if the project directory was
+// changed after the plan was made.
+type OutdatedError struct {
+	// Paths contains the destination paths that changed.
+	Paths []string
+}
+
+// Error implements the error interface.
+func (e *OutdatedError) Error() string {
+	return fmt.Sprintf("project directory changed since the plan was made: %s", strings.Join(e.Paths, ", "))
+}
+
+// Verify checks that the destinations of all operations are in the same
+// state as when the plan was made. Returns an *OutdatedError otherwise.
+func (p *Plan) Verify() error {
+	var paths []string
+
+	for _, op := range p.Operations {
+		state, err := destState(op.Dest)
+		if err != nil {
+			return err
+		}
+
+		if state != op.DestState {
+			paths = append(paths, op.Dest.RelPath())
+		}
+	}
+
+	if len(paths) > 0 {
+		return &OutdatedError{Paths: paths}
+	}
+
+	return nil
+}
+
+// destState returns the state of dest. It is empty if dest does not exist,
+// destStateDir for directories, the link target prefixed with
+// destStateLinkPrefix for symlinks and the digest of the content for files.
+func destState(dest *Destination) (string, error) {
+	fi, err := os.Lstat(dest.AbsPath())
+	if err != nil {
+		// Consistent with (Destination).Exists.
+		return "", nil
+	}
+
+	if fi.IsDir() {
+		return destStateDir, nil
+	}
+
+	if fi.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(dest.AbsPath())
+		if err != nil {
+			return "", err
+		}
+
+		return destStateLinkPrefix + target, nil
+	}
+
+	content, err := os.ReadFile(dest.AbsPath())
+	if err != nil {
+		return "", err
+	}
+
+	return digest(content), nil
+}
+
+func digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,183 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestPlan(t *testing.T, dir string) *Plan {
+	ref := &kickoff.SkeletonRef{Name: "default", Repo: &kickoff.RepoRef{Name: "repo", Path: "/tmp/repo"}}
+
+	plan, err := MakePlan(&Config{
+		Name:       "myproject",
+		ProjectDir: dir,
+		Overwrite:  true,
+		Values:     template.Values{"greeting": "hello"},
+		Skeleton: &kickoff.Skeleton{
+			Files: []*kickoff.BufferedFile{
+				{RelPath: "README.md.skel", Content: []byte("{{.Values.greeting}} {{.Project.Name}}\n"), Mode: 0644, SkeletonRef: ref},
+				{RelPath: "existing.txt", Content: []byte("new"), Mode: 0644, SkeletonRef: ref},
+				{RelPath: "pkg", Mode: 0755 | os.ModeDir, SkeletonRef: ref},
+			},
+			Hooks: kickoff.NewHooks(kickoff.HookConfig{PostCreate: []string{"echo {{.Project.Name}}"}}, ref),
+		},
+	})
+	require.NoError(t, err)
+
+	return plan
+}
+
+func TestPlan_MarshalJSON(t *testing.T) {
+	dir := t.TempDir()
+	tester := &dirTester{T: t, dir: dir}
+	tester.mustWriteFile("existing.txt", "old")
+
+	plan := makeTestPlan(t, dir)
+
+	for name, marshal := range map[string]func(interface{}) ([]byte, error){
+		"json": json.Marshal,
+		"yaml": yaml.Marshal,
+	} {
+		t.Run(name, func(t *testing.T) {
+			buf, err := marshal(plan)
+			require.NoError(t, err)
+
+			var loaded Plan
+			require.NoError(t, yaml.Unmarshal(buf, &loaded))
+
+			assert.Equal(t, plan.OpCounts, loaded.OpCounts)
+			assert.Equal(t, plan.Hooks, loaded.Hooks)
+			require.Len(t, loaded.Operations, len(plan.Operations))
+
+			for i, op := range loaded.Operations {
+				expected := plan.Operations[i]
+
+				assert.Equal(t, expected.Type, op.Type)
+				assert.Equal(t, expected.Dest, op.Dest)
+				assert.Equal(t, expected.DestState, op.DestState)
+				assert.Equal(t, expected.Content, op.Content)
+				assert.Equal(t, expected.Source.RelPath, op.Source.RelPath)
+				assert.Equal(t, expected.Source.Mode, op.Source.Mode)
+				assert.Equal(t, expected.Source.SkeletonRef, op.Source.SkeletonRef)
+			}
+		})
+	}
+
+	t.Run("includes digest and op type names", func(t *testing.T) {
+		buf, err := json.Marshal(plan)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(buf), `"type":"overwrite"`)
+		assert.Contains(t, string(buf), `"digest":"sha256:`)
+	})
+}
+
+func TestPlan_UnmarshalJSON(t *testing.T) {
+	plan := makeTestPlan(t, t.TempDir())
+
+	buf, err := json.Marshal(plan)
+	require.NoError(t, err)
+
+	t.Run("rejects tampered content", func(t *testing.T) {
+		tampered := strings.Replace(string(buf), `"content":"`, `"content":"AAAA`, 1)
+
+		var loaded Plan
+		require.Error(t, json.Unmarshal([]byte(tampered), &loaded))
+	})
+
+	t.Run("rejects unknown versions", func(t *testing.T) {
+		var loaded Plan
+		require.Error(t, json.Unmarshal([]byte(`{"version":42,"projectDir":"/tmp"}`), &loaded))
+	})
+
+	t.Run("rejects destinations outside of the project directory", func(t *testing.T) {
+		tampered := strings.Replace(string(buf), `"path":"README.md"`, `"path":"../README.md"`, 1)
+		require.NotEqual(t, string(buf), tampered)
+
+		var loaded Plan
+		err := json.Unmarshal([]byte(tampered), &loaded)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "outside of the project directory")
+	})
+
+	t.Run("rejects destinations with tampered base", func(t *testing.T) {
+		tampered := strings.Replace(string(buf), `"base":"`, `"base":"/etc`, 1)
+		require.NotEqual(t, string(buf), tampered)
+
+		var loaded Plan
+		require.Error(t, json.Unmarshal([]byte(tampered), &loaded))
+	})
+
+	t.Run("allows the .gitignore in the repository root", func(t *testing.T) {
+		repoRoot := t.TempDir()
+		projectDir := filepath.Join(repoRoot, "services", "myproject")
+
+		loaded := &Plan{
+			projectDir: projectDir,
+			repoRoot:   repoRoot,
+			Operations: []*Operation{
+				{Dest: &Destination{Base: projectDir, Path: "../../.gitignore"}},
+				{Dest: &Destination{Base: projectDir, Path: "README.md"}},
+			},
+		}
+		require.NoError(t, loaded.checkDestinations())
+
+		loaded.Operations = append(loaded.Operations, &Operation{Dest: &Destination{Base: projectDir, Path: "../other/.gitignore"}})
+		require.Error(t, loaded.checkDestinations())
+
+		loaded.Operations = loaded.Operations[:2]
+		loaded.repoRoot = t.TempDir()
+		require.Error(t, loaded.checkDestinations())
+	})
+
+	t.Run("rejects invalid operation types", func(t *testing.T) {
+		var op Operation
+		require.Error(t, json.Unmarshal([]byte(`{"type":"explode","source":{},"dest":{}}`), &op))
+	})
+}
+
+func TestPlan_Verify(t *testing.T) {
+	dir := t.TempDir()
+	tester := &dirTester{T: t, dir: dir}
+	tester.mustWriteFile("existing.txt", "old")
+
+	plan := makeTestPlan(t, dir)
+
+	buf, err := json.Marshal(plan)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	require.NoError(t, os.WriteFile(path, buf, 0644))
+
+	loaded, err := LoadPlan(path)
+	require.NoError(t, err)
+	require.NoError(t, loaded.Verify())
+
+	tester.mustWriteFile("existing.txt", "changed")
+	tester.mustWriteFile("README.md", "created")
+
+	err = loaded.Verify()
+
+	var outdatedErr *OutdatedError
+	require.True(t, errors.As(err, &outdatedErr))
+	assert.Equal(t, []string{"README.md", "existing.txt"}, outdatedErr.Paths)
+
+	tester.mustRemoveFile("README.md")
+	tester.mustWriteFile("existing.txt", "old")
+
+	require.NoError(t, loaded.Apply(context.Background()))
+
+	tester.assertFileContains("README.md", "hello myproject\n")
+	tester.assertFileContains("existing.txt", "new")
+}
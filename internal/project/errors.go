@@ -0,0 +1,151 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/template"
+)
+
+// RenderError is the error for a skeleton file or hook that failed to
+// render.
+type RenderError struct {
+	// Path is the path of the file relative to the skeleton root. For hooks
+	// it describes the location of the hook in the skeleton config, e.g.
+	// `hooks.postCreate[0]`.
+	Path string
+	// SkeletonRef references the skeleton the file or hook belongs to. Nil
+	// for files generated by kickoff.
+	SkeletonRef *kickoff.SkeletonRef
+	// Line is the 1-based line of the error in the template. Zero if unknown.
+	Line int
+	// Column is the 1-based column of the error in the template. Zero if
+	// unknown.
+	Column int
+	// Err is the underlying error.
+	Err error
+}
+
+func newRenderError(path string, ref *kickoff.SkeletonRef, err error) *RenderError {
+	var renderErr *RenderError
+	if errors.As(err, &renderErr) {
+		// Errors for parts of merged files already reference the skeleton
+		// that contributed the part.
+		return renderErr
+	}
+
+	e := &RenderError{Path: path, SkeletonRef: ref, Err: err}
+
+	var tplErr *template.Error
+	if errors.As(err, &tplErr) {
+		e.Line = tplErr.Line
+		e.Column = tplErr.Column
+		e.Err = errors.New(tplErr.Message)
+	}
+
+	return e
+}
+
+// Error implements the error interface.
+func (e *RenderError) Error() string {
+	var sb strings.Builder
+
+	sb.WriteString(e.Path)
+
+	if e.Line > 0 {
+		fmt.Fprintf(&sb, ":%d", e.Line)
+	}
+
+	if e.Column > 0 {
+		fmt.Fprintf(&sb, ":%d", e.Column)
+	}
+
+	if e.SkeletonRef != nil {
+		fmt.Fprintf(&sb, " (skeleton %s)", e.SkeletonRef)
+	}
+
+	fmt.Fprintf(&sb, ": %v", e.Err)
+
+	return sb.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// RenderErrors is returned when making a plan if one or more skeleton files
+// failed to render.
+type RenderErrors []*RenderError
+
+// Error implements the error interface.
+func (e RenderErrors) Error() string {
+	if len(e) == 1 {
+		return fmt.Sprintf("failed to render template %v", e[0])
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "failed to render %d templates:", len(e))
+
+	for _, err := range e {
+		fmt.Fprintf(&sb, "\n  %v", err)
+	}
+
+	return sb.String()
+}
+
+// collectRenderErrors appends err to errs if it is a RenderErrors so that
+// rendering can continue with the next file. Other errors are returned as-is.
+func collectRenderErrors(errs *RenderErrors, err error) error {
+	if renderErrs, ok := err.(RenderErrors); ok {
+		*errs = append(*errs, renderErrs...)
+		return nil
+	}
+
+	return err
+}
+
+// ValuesError describes a template value that does not conform to the values
+// schema of a skeleton.
+type ValuesError struct {
+	// Path is the JSON path of the value, e.g. `$.image.tag`.
+	Path string
+	// SkeletonRef references the skeleton whose values schema was violated.
+	SkeletonRef *kickoff.SkeletonRef
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ValuesError) Error() string {
+	return fmt.Sprintf("%s (skeleton %s): %v", e.Path, e.SkeletonRef, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ValuesError) Unwrap() error {
+	return e.Err
+}
+
+// ValuesErrors is returned when making a plan if the template values do not
+// conform to the values schemas of the skeletons.
+type ValuesErrors []*ValuesError
+
+// Error implements the error interface.
+func (e ValuesErrors) Error() string {
+	if len(e) == 1 {
+		return fmt.Sprintf("invalid template value %v", e[0])
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "found %d invalid template values:", len(e))
+
+	for _, err := range e {
+		fmt.Fprintf(&sb, "\n  %v", err)
+	}
+
+	return sb.String()
+}
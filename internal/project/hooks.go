@@ -0,0 +1,52 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	log "github.com/sirupsen/logrus"
+)
+
+// RunHooks runs the hooks of given stage in the project directory in the
+// order they were defined. The project directory is created if it does not
+// exist yet. The output of the hook commands is written to w. Hooks are not
+// run by (*Plan).Apply, it is up to the caller to run them before and after
+// applying the plan. Changes made by hooks are not rolled back if applying the
+// plan fails.
+func (p *Plan) RunHooks(ctx context.Context, stage kickoff.HookStage, w io.Writer) error {
+	for _, hook := range p.Hooks {
+		if hook.Stage != stage {
+			continue
+		}
+
+		if err := os.MkdirAll(p.projectDir, 0755); err != nil {
+			return err
+		}
+
+		log.WithField("stage", stage).WithField("command", hook.Command).Debug("running hook")
+
+		cmd := shellCommand(ctx, hook.Command)
+		cmd.Dir = p.projectDir
+		cmd.Stdout = w
+		cmd.Stderr = w
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook %q of skeleton %s failed: %w", stage, hook.Command, hook.SkeletonRef, err)
+		}
+	}
+
+	return nil
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
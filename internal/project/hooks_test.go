@@ -0,0 +1,88 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan_RunHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in this test require a POSIX shell")
+	}
+
+	ref := &kickoff.SkeletonRef{Name: "default"}
+
+	skeleton := &kickoff.Skeleton{
+		Files: []*kickoff.BufferedFile{
+			{RelPath: "README.md", Content: []byte("readme"), Mode: 0644, SkeletonRef: ref},
+		},
+		Hooks: kickoff.NewHooks(kickoff.HookConfig{
+			PreCreate:  []string{"test ! -f README.md && echo pre > pre.txt"},
+			PostCreate: []string{"cat README.md > post.txt", "echo {{.Project.Name}} >> post.txt"},
+		}, ref),
+	}
+
+	t.Run("runs hooks in project dir", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "myproject")
+		tester := &dirTester{T: t, dir: dir}
+
+		plan, err := MakePlan(&Config{Name: "myproject", ProjectDir: dir, Skeleton: skeleton})
+		require.NoError(t, err)
+		require.Len(t, plan.Hooks, 3)
+		assert.Equal(t, "echo myproject >> post.txt", plan.Hooks[2].Command)
+
+		ctx := context.Background()
+
+		var out bytes.Buffer
+
+		require.NoError(t, plan.RunHooks(ctx, kickoff.HookPreCreate, &out))
+		require.NoError(t, plan.Apply(ctx))
+		require.NoError(t, plan.RunHooks(ctx, kickoff.HookPostCreate, &out))
+
+		tester.assertFileContains("pre.txt", "pre\n")
+		tester.assertFileContains("post.txt", "readmemyproject\n")
+	})
+
+	t.Run("hooks are omitted with NoHooks", func(t *testing.T) {
+		plan, err := MakePlan(&Config{Name: "myproject", ProjectDir: t.TempDir(), Skeleton: skeleton, NoHooks: true})
+		require.NoError(t, err)
+		assert.Empty(t, plan.Hooks)
+	})
+
+	t.Run("failing hook returns error", func(t *testing.T) {
+		plan, err := MakePlan(&Config{
+			Name:       "myproject",
+			ProjectDir: t.TempDir(),
+			Skeleton: &kickoff.Skeleton{
+				Hooks: kickoff.NewHooks(kickoff.HookConfig{PostCreate: []string{"exit 1"}}, ref),
+			},
+		})
+		require.NoError(t, err)
+
+		err = plan.RunHooks(context.Background(), kickoff.HookPostCreate, &bytes.Buffer{})
+		require.EqualError(t, err, `postCreate hook "exit 1" of skeleton default failed: exit status 1`)
+	})
+
+	t.Run("hook render errors are reported", func(t *testing.T) {
+		_, err := MakePlan(&Config{
+			Name:       "myproject",
+			ProjectDir: t.TempDir(),
+			Skeleton: &kickoff.Skeleton{
+				Hooks: kickoff.NewHooks(kickoff.HookConfig{PostCreate: []string{"ok", "echo {{.Values.missing}}"}}, ref),
+			},
+		})
+
+		var renderErrs RenderErrors
+		require.True(t, errors.As(err, &renderErrs))
+		require.Len(t, renderErrs, 1)
+		assert.Equal(t, "hooks.postCreate[1]", renderErrs[0].Path)
+	})
+}
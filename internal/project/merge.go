@@ -0,0 +1,222 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ghodss/yaml"
+	"github.com/martinohmann/kickoff/internal/diff"
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/template"
+)
+
+// structuredFormat is a file format supported by kickoff.MergeStructured.
+type structuredFormat struct {
+	name      string
+	unmarshal func([]byte, interface{}) error
+	marshal   func(interface{}) ([]byte, error)
+}
+
+var structuredFormats = map[string]*structuredFormat{
+	".yaml": {name: "YAML", unmarshal: yaml.Unmarshal, marshal: yaml.Marshal},
+	".yml":  {name: "YAML", unmarshal: yaml.Unmarshal, marshal: yaml.Marshal},
+	".json": {name: "JSON", unmarshal: json.Unmarshal, marshal: marshalJSON},
+	".toml": {name: "TOML", unmarshal: toml.Unmarshal, marshal: marshalTOML},
+}
+
+func marshalJSON(v interface{}) ([]byte, error) {
+	buf, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(buf, '\n'), nil
+}
+
+func marshalTOML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// renderPart renders part of a merged file or a patch. Errors are returned
+// as *RenderError for part so that they reference the skeleton that
+// contributed it.
+func (p *Plan) renderPart(part *kickoff.BufferedFile, values template.Values) ([]byte, error) {
+	content, err := p.render(part, values)
+	if err == nil && content == nil {
+		content, err = part.ReadContent()
+	}
+
+	if err != nil {
+		return nil, newRenderError(part.RelPath, part.SkeletonRef, err)
+	}
+
+	return content, nil
+}
+
+// renderParts renders all parts of source individually and combines them
+// using the merge strategy of source.
+func (p *Plan) renderParts(source *kickoff.BufferedFile, values template.Values) ([]byte, error) {
+	if source.Merge == kickoff.MergeStructured {
+		return p.renderStructured(source, values)
+	}
+
+	var content []byte
+
+	for i, part := range source.Parts {
+		partContent, err := p.renderPart(part, values)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			content = partContent
+			continue
+		}
+
+		content = mergeContent(content, partContent, source.Merge)
+	}
+
+	if content == nil {
+		// Nil content would be streamed from the source.
+		content = []byte{}
+	}
+
+	return content, nil
+}
+
+// mergeContent combines lhs and rhs using strategy. A newline is inserted
+// between both if the first part does not end with one.
+func mergeContent(lhs, rhs []byte, strategy kickoff.MergeStrategy) []byte {
+	switch strategy {
+	case kickoff.MergePrepend:
+		return joinContent(rhs, lhs)
+	case kickoff.MergeAppendUniqueLines:
+		return joinContent(lhs, uniqueLines(lhs, rhs))
+	default:
+		return joinContent(lhs, rhs)
+	}
+}
+
+func joinContent(first, second []byte) []byte {
+	var buf bytes.Buffer
+
+	buf.Write(first)
+
+	if len(first) > 0 && len(second) > 0 && !bytes.HasSuffix(first, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+
+	buf.Write(second)
+
+	return buf.Bytes()
+}
+
+// uniqueLines returns all lines of content that are neither present in
+// existing nor occur earlier in content.
+func uniqueLines(existing, content []byte) []byte {
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(string(existing), "\n") {
+		seen[strings.TrimRight(line, "\r")] = true
+	}
+
+	var buf bytes.Buffer
+
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		key := strings.TrimRight(line, "\r\n")
+		if line == "" || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		buf.WriteString(line)
+	}
+
+	return buf.Bytes()
+}
+
+// renderStructured renders all parts of source individually and deep-merges
+// the resulting documents using the semantics of template.MergeValues. The
+// result is encoded in the format of the parts. Parse errors are returned as
+// *RenderError for the part that failed to parse.
+func (p *Plan) renderStructured(source *kickoff.BufferedFile, values template.Values) ([]byte, error) {
+	ext := filepath.Ext(strings.TrimSuffix(source.RelPath, kickoff.SkeletonTemplateExtension))
+
+	format, ok := structuredFormats[ext]
+	if !ok {
+		return nil, fmt.Errorf("structured merge is not supported for %q files, only YAML, JSON and TOML files can be merged", ext)
+	}
+
+	docs := make([]template.Values, 0, len(source.Parts))
+
+	for _, part := range source.Parts {
+		content, err := p.renderPart(part, values)
+		if err != nil {
+			return nil, err
+		}
+
+		var doc template.Values
+
+		if err := format.unmarshal(content, &doc); err != nil {
+			err = fmt.Errorf("failed to parse %s for structured merge: %w", format.name, err)
+			return nil, newRenderError(part.RelPath, part.SkeletonRef, err)
+		}
+
+		docs = append(docs, doc)
+	}
+
+	merged, err := template.MergeValues(docs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge %s documents: %w", format.name, err)
+	}
+
+	return format.marshal(merged)
+}
+
+// applyPatches renders the patches of source and applies them in order to
+// content. Errors are returned as *RenderError for the patch that failed.
+func (p *Plan) applyPatches(source *kickoff.BufferedFile, content []byte, values template.Values) ([]byte, error) {
+	if content == nil {
+		var err error
+
+		content, err = source.ReadContent()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	target := source.RelPath
+	if ref := source.SkeletonRef; ref != nil {
+		target = fmt.Sprintf("%s of skeleton %s", target, ref)
+	}
+
+	for _, patch := range source.Patches {
+		patchContent, err := p.renderPart(patch, values)
+		if err != nil {
+			return nil, err
+		}
+
+		patched, err := diff.Apply(string(content), string(patchContent))
+		if err != nil {
+			err = fmt.Errorf("failed to patch %s: %w", target, err)
+			return nil, newRenderError(patch.RelPath, patch.SkeletonRef, err)
+		}
+
+		content = []byte(patched)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,207 @@
+package project
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakePlan_StructuredMerge(t *testing.T) {
+	ref0 := &kickoff.SkeletonRef{Name: "go", Repo: &kickoff.RepoRef{Name: "repo"}}
+	ref1 := &kickoff.SkeletonRef{Name: "docker", Repo: &kickoff.RepoRef{Name: "repo"}}
+
+	makePlan := func(t *testing.T, path string, content0, content1 string) (*Plan, error) {
+		s0 := &kickoff.Skeleton{
+			Files: []*kickoff.BufferedFile{
+				{RelPath: path, Content: []byte(content0), Mode: 0644, SkeletonRef: ref0},
+			},
+			FileRules: []*kickoff.FileRule{
+				{Path: path, Merge: kickoff.MergeStructured, SkeletonRef: ref0},
+			},
+		}
+		s1 := &kickoff.Skeleton{
+			Files: []*kickoff.BufferedFile{
+				{RelPath: path, Content: []byte(content1), Mode: 0644, SkeletonRef: ref1},
+			},
+		}
+
+		skeleton, err := kickoff.MergeSkeletons(s0, s1)
+		require.NoError(t, err)
+
+		return MakePlan(&Config{
+			Name:       "myproject",
+			ProjectDir: t.TempDir(),
+			Values:     template.Values{"image": "alpine"},
+			Skeleton:   skeleton,
+		})
+	}
+
+	t.Run("merges rendered YAML documents", func(t *testing.T) {
+		plan, err := makePlan(t, "compose.yaml.skel",
+			"services:\n  app:\n    build: .\nname: {{.Project.Name}}\n",
+			"services:\n  db:\n    image: {{.Values.image}}\n")
+		require.NoError(t, err)
+		require.Len(t, plan.Operations, 1)
+
+		assert.Equal(t, "name: myproject\nservices:\n  app:\n    build: .\n  db:\n    image: alpine\n", string(plan.Operations[0].Content))
+	})
+
+	t.Run("merges JSON documents", func(t *testing.T) {
+		plan, err := makePlan(t, "package.json",
+			`{"name": "app", "scripts": {"build": "tsc"}}`,
+			`{"scripts": {"test": "jest"}, "private": true}`)
+		require.NoError(t, err)
+
+		assert.Equal(t, `{
+  "name": "app",
+  "private": true,
+  "scripts": {
+    "build": "tsc",
+    "test": "jest"
+  }
+}
+`, string(plan.Operations[0].Content))
+	})
+
+	t.Run("parse errors name the skeleton of the part", func(t *testing.T) {
+		_, err := makePlan(t, "package.json", `{"name": "app"}`, `{"scripts": `)
+		require.Error(t, err)
+
+		var renderErrs RenderErrors
+		require.True(t, errors.As(err, &renderErrs))
+		require.Len(t, renderErrs, 1)
+		assert.Equal(t, ref1, renderErrs[0].SkeletonRef)
+		assert.Contains(t, renderErrs[0].Error(), "package.json (skeleton repo:docker): failed to parse JSON for structured merge")
+	})
+
+	t.Run("merges TOML documents", func(t *testing.T) {
+		plan, err := makePlan(t, "Cargo.toml.skel",
+			"[package]\nname = \"{{.Project.Name}}\"\nversion = \"0.1.0\"\n\n[dependencies]\nserde = \"1.0\"\n",
+			"[package]\nedition = \"2021\"\n\n[dependencies]\ntokio = { version = \"1\", features = [\"full\"] }\n\n[profile.release]\nlto = true\n")
+		require.NoError(t, err)
+		require.Len(t, plan.Operations, 1)
+
+		assert.Equal(t, `[dependencies]
+serde = "1.0"
+[dependencies.tokio]
+features = ["full"]
+version = "1"
+
+[package]
+edition = "2021"
+name = "myproject"
+version = "0.1.0"
+
+[profile]
+[profile.release]
+lto = true
+`, string(plan.Operations[0].Content))
+	})
+
+	t.Run("unsupported formats cause an error", func(t *testing.T) {
+		_, err := makePlan(t, "config.ini", "a = 1", "b = 2")
+		assert.EqualError(t, err, `failed to render template config.ini (skeleton repo:docker): structured merge is not supported for ".ini" files, only YAML, JSON and TOML files can be merged`)
+	})
+}
+
+func TestMakePlan_MergeStrategies(t *testing.T) {
+	ref0 := &kickoff.SkeletonRef{Name: "s0"}
+	ref1 := &kickoff.SkeletonRef{Name: "s1"}
+	ref2 := &kickoff.SkeletonRef{Name: "s2"}
+
+	s0 := &kickoff.Skeleton{
+		Files: []*kickoff.BufferedFile{
+			{RelPath: ".gitignore", Content: []byte("bin/\n*.out\n"), Mode: 0644, SkeletonRef: ref0},
+			{RelPath: "Makefile.skel", Content: []byte("build: {{.Project.Name}}"), Mode: 0644, SkeletonRef: ref0},
+		},
+		FileRules: []*kickoff.FileRule{
+			{Path: ".gitignore", Merge: kickoff.MergeAppendUniqueLines, SkeletonRef: ref0},
+			{Path: "Makefile", Merge: kickoff.MergeAppend, SkeletonRef: ref0},
+		},
+	}
+	s1 := &kickoff.Skeleton{
+		Files: []*kickoff.BufferedFile{
+			{RelPath: ".gitignore", Content: []byte("*.out\n.env\n"), Mode: 0644, SkeletonRef: ref1},
+			{RelPath: "Makefile.skel", Content: []byte("docker:\n"), Mode: 0644, SkeletonRef: ref1},
+		},
+	}
+	s2 := &kickoff.Skeleton{
+		Files: []*kickoff.BufferedFile{
+			{RelPath: "Makefile.skel", Content: []byte("include common.mk\n"), Mode: 0644, SkeletonRef: ref2},
+		},
+		FileRules: []*kickoff.FileRule{
+			{Path: "Makefile", Merge: kickoff.MergePrepend, SkeletonRef: ref2},
+		},
+	}
+
+	skeleton, err := kickoff.MergeSkeletons(s0, s1, s2)
+	require.NoError(t, err)
+
+	plan, err := MakePlan(&Config{Name: "myproject", ProjectDir: t.TempDir(), Skeleton: skeleton})
+	require.NoError(t, err)
+	require.Len(t, plan.Operations, 2)
+
+	assert.Equal(t, "bin/\n*.out\n.env\n", string(plan.Operations[0].Content))
+	assert.Equal(t, "include common.mk\nbuild: myproject\ndocker:\n", string(plan.Operations[1].Content))
+}
+
+func TestMakePlan_Patches(t *testing.T) {
+	ref0 := &kickoff.SkeletonRef{Name: "go", Repo: &kickoff.RepoRef{Name: "repo"}}
+	ref1 := &kickoff.SkeletonRef{Name: "docker", Repo: &kickoff.RepoRef{Name: "repo"}}
+
+	makePlan := func(t *testing.T, patch string) (*Plan, error) {
+		s0 := &kickoff.Skeleton{
+			Files: []*kickoff.BufferedFile{
+				{RelPath: "Makefile.skel", Content: []byte("build:\n\tgo build -o {{.Project.Name}}\n\ntest:\n\tgo test ./...\n"), Mode: 0644, SkeletonRef: ref0},
+			},
+		}
+		s1 := &kickoff.Skeleton{
+			Files: []*kickoff.BufferedFile{
+				{RelPath: "Makefile.patch.skel", Content: []byte(patch), Mode: 0644, SkeletonRef: ref1},
+			},
+		}
+
+		skeleton, err := kickoff.MergeSkeletons(s0, s1)
+		require.NoError(t, err)
+
+		return MakePlan(&Config{
+			Name:       "myproject",
+			ProjectDir: t.TempDir(),
+			Values:     template.Values{"image": "alpine"},
+			Skeleton:   skeleton,
+		})
+	}
+
+	t.Run("applies rendered patch to rendered file", func(t *testing.T) {
+		plan, err := makePlan(t, `--- a/Makefile
++++ b/Makefile
+@@ -2,3 +2,6 @@
+ 	go build -o {{.Project.Name}}
+ 
++image:
++	docker build -t {{.Project.Name}}:{{.Values.image}} .
++
+ test:
+`)
+		require.NoError(t, err)
+		require.Len(t, plan.Operations, 1)
+
+		op := plan.Operations[0]
+		assert.Equal(t, "Makefile", op.Dest.RelPath())
+		assert.Equal(t, "build:\n\tgo build -o myproject\n\nimage:\n\tdocker build -t myproject:alpine .\n\ntest:\n\tgo test ./...\n", string(op.Content))
+	})
+
+	t.Run("hunks that do not apply name both skeletons", func(t *testing.T) {
+		_, err := makePlan(t, `@@ -1,2 +1,2 @@
+ build:
+-	go build
++	go build -v
+`)
+		assert.EqualError(t, err, "failed to render template Makefile.patch.skel (skeleton repo:docker): "+
+			"failed to patch Makefile.skel of skeleton repo:go: hunk #1 @@ -1,2 +1,2 @@ does not apply")
+	})
+}
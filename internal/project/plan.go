@@ -1,13 +1,19 @@
 package project
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"time"
 
+	"github.com/ghodss/yaml"
 	"github.com/martinohmann/kickoff/internal/gitignore"
 	"github.com/martinohmann/kickoff/internal/kickoff"
 	"github.com/martinohmann/kickoff/internal/license"
@@ -24,11 +30,22 @@ type Config struct {
 	Owner string
 	// ProjectDir is the directory where project files should be written.
 	ProjectDir string
+	// RepoRoot is the root directory of the git repository that contains
+	// ProjectDir if the project is created as a sub-project of an existing
+	// repository (monorepo mode). Empty if the project is the root of its own
+	// repository.
+	RepoRoot string
+	// RepoName is the name of the repository in monorepo mode, usually taken
+	// from the origin remote of the repository in RepoRoot. If empty, the
+	// name of the RepoRoot directory is used instead.
+	RepoName string
 	// Gitignore template to use for creating .gitignore. If nil, no .gitignore
-	// is created.
+	// is created. In monorepo mode, missing lines are appended to the
+	// .gitignore in RepoRoot instead.
 	Gitignore *gitignore.Template
 	// License info for the open source license to use. If nil, no LICENSE file
-	// is created.
+	// is created. In monorepo mode, no LICENSE file is created and the license
+	// is only made available to templates.
 	License *license.Info
 	// If Overwrite is true, existing file in the target directory that matches
 	// the name of one of the skeleton files is overwritten.
@@ -44,6 +61,57 @@ type Config struct {
 	// Values are user defined values that are merged on top of values from the
 	// project skeleton.
 	Values template.Values
+	// If NoHooks is true, the hooks of the skeleton are not added to the
+	// plan.
+	NoHooks bool
+	// Year is filled into the license text. If zero, the current year is
+	// used.
+	Year int
+	// Lock is written to the project directory if non-nil. It records the
+	// skeletons and values the project was created from so that the project
+	// can be updated later.
+	Lock *kickoff.Lock
+}
+
+// URL returns the URL of the project repository.
+func (c *Config) URL() string {
+	return fmt.Sprintf("https://%s/%s/%s", c.Host, c.Owner, c.repoName())
+}
+
+// GoPackagePath returns the Go package path of the project. In monorepo mode
+// it includes the path of the project directory within the repository.
+func (c *Config) GoPackagePath() string {
+	return path.Join(c.Host, c.Owner, c.repoName(), c.SubPath())
+}
+
+// SubPath returns the slash-separated path of the project directory relative
+// to RepoRoot. Returns an empty string if RepoRoot is empty.
+func (c *Config) SubPath() string {
+	if c.RepoRoot == "" {
+		return ""
+	}
+
+	rel, err := filepath.Rel(c.RepoRoot, c.ProjectDir)
+	if err != nil {
+		return ""
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// repoName returns the name of the project repository. In monorepo mode this
+// is RepoName or the name of the RepoRoot directory, otherwise the project
+// name.
+func (c *Config) repoName() string {
+	if c.RepoRoot == "" {
+		return c.Name
+	}
+
+	if c.RepoName != "" {
+		return c.RepoName
+	}
+
+	return filepath.Base(c.RepoRoot)
 }
 
 // OpType defines the type of operation that should be performed for a given
@@ -55,14 +123,16 @@ const (
 	OpSkipExisting
 	OpSkipUser
 	OpOverwrite
+	OpMerge
+	OpSkipSkeleton
 )
 
 // Destination describes the destination a project file should be written to.
 type Destination struct {
 	// Base is the base dir of the project.
-	Base string
+	Base string `json:"base"`
 	// Path is the path relative to the base dir.
-	Path string
+	Path string `json:"path"`
 }
 
 // RelPath returns the path relative to the project root.
@@ -75,9 +145,10 @@ func (d Destination) AbsPath() string {
 	return filepath.Join(d.Base, d.Path)
 }
 
-// Exists returns true if the destination already exists.
+// Exists returns true if the destination already exists. Symlinks are not
+// followed.
 func (d Destination) Exists() bool {
-	_, err := os.Stat(d.AbsPath())
+	_, err := os.Lstat(d.AbsPath())
 	return err == nil
 }
 
@@ -87,6 +158,66 @@ type Operation struct {
 	Type   OpType
 	Source *kickoff.BufferedFile
 	Dest   *Destination
+	// DestState records the state of the destination at the time the plan
+	// was made. It is empty if the destination did not exist, "dir" for
+	// directories, "link:" followed by the target for symlinks and the SHA256
+	// digest of the content for files. See (*Plan).Verify.
+	DestState string
+	// Content holds the content that is written to the destination. For
+	// template files this is the rendered template, for operations of type
+	// OpMerge it is the result of the three-way merge. For symlinks it holds
+	// the rendered link target. Nil for directories, operations that skip
+	// the destination and files whose content is streamed from the source
+	// file as-is. Use ReadContent to access the content in all cases.
+	Content []byte
+	// Conflicts is the number of conflicting hunks in Content that were
+	// marked with conflict markers.
+	Conflicts int
+	// Reason describes why the destination is skipped for operations of type
+	// OpSkipSkeleton.
+	Reason string
+
+	// values are the template values of the source. Needed to render the
+	// content of skipped operations on demand, see (*Plan).Overwrite.
+	values template.Values
+	// sharedGitignore is true for the operation on the .gitignore in the
+	// repository root in monorepo mode, which other plans may write to as
+	// well. See CombineSharedGitignores.
+	sharedGitignore bool
+}
+
+// skips returns true if op does not touch its destination.
+func (op *Operation) skips() bool {
+	switch op.Type {
+	case OpSkipExisting, OpSkipUser, OpSkipSkeleton:
+		return true
+	default:
+		return false
+	}
+}
+
+// ReadContent returns the content that is written to the destination. If
+// Content is nil, the content of the source is read.
+func (op *Operation) ReadContent() ([]byte, error) {
+	if op.Content != nil {
+		return op.Content, nil
+	}
+
+	return op.Source.ReadContent()
+}
+
+// open opens the content that is written to the destination for reading.
+func (op *Operation) open() (io.ReadCloser, error) {
+	if op.Content != nil {
+		return io.NopCloser(bytes.NewReader(op.Content)), nil
+	}
+
+	return op.Source.Open()
+}
+
+// writesFile returns true if op writes a file to its destination.
+func (op *Operation) writesFile() bool {
+	return !op.skips() && !op.Source.Mode.IsDir()
 }
 
 // Plan holds the operations to create a new project. A plan is created from a
@@ -94,8 +225,15 @@ type Operation struct {
 type Plan struct {
 	OpCounts   map[OpType]int
 	Operations []*Operation
+	// Hooks holds the hooks with rendered commands in the order they must be
+	// run. See (*Plan).RunHooks.
+	Hooks []*kickoff.Hook
 
+	projectDir    string
+	repoRoot      string
+	url           string
 	values        template.Values
+	fileRules     []*fileRule
 	dirRewriteMap map[string]string
 	skipMap       map[string]bool
 	overwriteMap  map[string]bool
@@ -103,8 +241,72 @@ type Plan struct {
 
 // MakePlan creates a creation plan for the given config.
 func MakePlan(config *Config) (*Plan, error) {
+	p, err := newPlan(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var renderErrs RenderErrors
+
+	err = p.makeOperations(config)
+	if errs, ok := err.(RenderErrors); ok {
+		renderErrs = errs
+	} else if err != nil {
+		return nil, err
+	}
+
+	if !config.NoHooks {
+		renderErrs = append(renderErrs, p.makeHooks(config.Skeleton)...)
+	}
+
+	if len(renderErrs) > 0 {
+		return nil, renderErrs
+	}
+
+	return p, nil
+}
+
+// makeHooks renders the hook commands of skeleton and adds them to the plan.
+func (p *Plan) makeHooks(skeleton *kickoff.Skeleton) RenderErrors {
+	var renderErrs RenderErrors
+
+	type hookKey struct {
+		ref   *kickoff.SkeletonRef
+		stage kickoff.HookStage
+	}
+
+	// The index of a hook within the hook list of the skeleton that defined
+	// it is only needed for error reporting.
+	indexes := make(map[hookKey]int)
+
+	for _, hook := range skeleton.Hooks {
+		key := hookKey{hook.SkeletonRef, hook.Stage}
+		index := indexes[key]
+		indexes[key]++
+
+		command, err := template.Render(hook.Command, p.values)
+		if err != nil {
+			path := fmt.Sprintf("hooks.%s[%d]", hook.Stage, index)
+			renderErrs = append(renderErrs, newRenderError(path, hook.SkeletonRef, err))
+			continue
+		}
+
+		p.Hooks = append(p.Hooks, &kickoff.Hook{
+			Stage:       hook.Stage,
+			Command:     command,
+			SkeletonRef: hook.SkeletonRef,
+		})
+	}
+
+	return renderErrs
+}
+
+func newPlan(config *Config) (*Plan, error) {
 	p := &Plan{
 		OpCounts:      make(map[OpType]int),
+		projectDir:    config.ProjectDir,
+		repoRoot:      config.RepoRoot,
+		url:           config.URL(),
 		dirRewriteMap: make(map[string]string),
 		skipMap:       make(map[string]bool),
 		overwriteMap:  make(map[string]bool),
@@ -125,75 +327,131 @@ func MakePlan(config *Config) (*Plan, error) {
 		return nil, err
 	}
 
-	err = p.makeOperations(config)
-	if err != nil {
-		return nil, err
+	if renderErrs := p.evalFileRules(config.Skeleton.FileRules); len(renderErrs) > 0 {
+		return nil, renderErrs
 	}
 
 	return p, nil
 }
 
+// ProjectDir returns the directory the plan writes the project files to.
+func (p *Plan) ProjectDir() string {
+	return p.projectDir
+}
+
+// RepoRoot returns the root directory of the repository that contains the
+// project in monorepo mode. Empty otherwise.
+func (p *Plan) RepoRoot() string {
+	return p.repoRoot
+}
+
+// URL returns the URL of the project repository, see (*Config).URL.
+func (p *Plan) URL() string {
+	return p.url
+}
+
 // SkipsExisting returns true if the plan skips some existing files.
 func (p *Plan) SkipsExisting() bool {
 	return p.OpCounts[OpSkipExisting] > 0
 }
 
 // IsNoOp returns true if the plan does not contain any operations or if there
-// are solely skip operations.
+// are solely skip operations. Operations on the lock file are ignored as
+// writing it alone does not change the project.
 func (p *Plan) IsNoOp() bool {
-	return p.OpCounts[OpCreate] == 0 && p.OpCounts[OpOverwrite] == 0
+	for _, op := range p.Operations {
+		if op.Dest.RelPath() == kickoff.LockFileName {
+			continue
+		}
+
+		switch op.Type {
+		case OpCreate, OpOverwrite, OpMerge:
+			return false
+		}
+	}
+
+	return true
 }
 
-// Apply applies the plan. It will write all necessary project files to the
-// target directory.
-func (p *Plan) Apply() error {
+// Conflicts returns the operations whose merged content contains conflict
+// markers.
+func (p *Plan) Conflicts() []*Operation {
+	var ops []*Operation
+
 	for _, op := range p.Operations {
-		if err := p.executeOperation(op); err != nil {
-			return err
+		if op.Type == OpMerge && op.Conflicts > 0 {
+			ops = append(ops, op)
 		}
 	}
 
-	return nil
+	return ops
 }
 
 // Create is a convenience wrapper to make a plan and immediately apply it.
-func Create(config *Config) error {
+func Create(ctx context.Context, config *Config) error {
 	plan, err := MakePlan(config)
 	if err != nil {
 		return err
 	}
 
-	return plan.Apply()
+	return plan.Apply(ctx)
 }
 
-func (p *Plan) executeOperation(op *Operation) error {
-	if op.Type == OpSkipUser || op.Type == OpSkipExisting {
-		return nil
+// render returns the content of source. The content of template files is
+// rendered using values. For symlinks the rendered link target is returned.
+// The parts of merged files are rendered and combined and patches are
+// applied. Other files are not read. Their buffered content is returned,
+// which is nil if the content should be streamed from the source.
+func (p *Plan) render(source *kickoff.BufferedFile, values template.Values) ([]byte, error) {
+	if source.IsSymlink() {
+		return renderLinkTarget(source, values)
 	}
 
-	source := op.Source
-	dest := op.Dest
+	content, err := p.renderContent(source, values)
+	if err != nil || source.Patches == nil {
+		return content, err
+	}
 
-	if source.Mode.IsDir() {
-		return os.MkdirAll(dest.AbsPath(), source.Mode)
+	return p.applyPatches(source, content, values)
+}
+
+// renderContent returns the content of the regular file source without
+// applying its patches.
+func (p *Plan) renderContent(source *kickoff.BufferedFile, values template.Values) ([]byte, error) {
+	if source.Parts != nil {
+		return p.renderParts(source, values)
 	}
 
-	if err := os.MkdirAll(filepath.Dir(dest.AbsPath()), 0755); err != nil {
-		return err
+	if source.Binary || filepath.Ext(source.RelPath) != kickoff.SkeletonTemplateExtension {
+		return source.Content, nil
 	}
 
-	content := source.Content
+	content, err := source.ReadContent()
+	if err != nil {
+		return nil, err
+	}
 
-	if filepath.Ext(source.RelPath) == kickoff.SkeletonTemplateExtension {
-		rendered, err := template.Render(string(content), p.values)
-		if err != nil {
-			return err
-		}
+	rendered, err := template.Render(string(content), values)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(rendered), nil
+}
 
-		content = []byte(rendered)
+// renderLinkTarget renders the link target of the symlink source and ensures
+// that it does not point outside of the project directory.
+func renderLinkTarget(source *kickoff.BufferedFile, values template.Values) ([]byte, error) {
+	target, err := template.Render(source.LinkTarget, values)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := kickoff.ValidateLinkTarget(source.RelPath, target); err != nil {
+		return nil, err
 	}
 
-	return os.WriteFile(dest.AbsPath(), content, source.Mode)
+	return []byte(target), nil
 }
 
 func (p *Plan) makeTemplateValues(config *Config, skeleton *kickoff.Skeleton) error {
@@ -202,6 +460,10 @@ func (p *Plan) makeTemplateValues(config *Config, skeleton *kickoff.Skeleton) er
 		return err
 	}
 
+	if err := validateValues(values, skeleton.ValuesSchemas); err != nil {
+		return err
+	}
+
 	var (
 		licenseName    string
 		gitignoreQuery string
@@ -222,8 +484,10 @@ func (p *Plan) makeTemplateValues(config *Config, skeleton *kickoff.Skeleton) er
 			"Owner":         config.Owner,
 			"License":       licenseName,
 			"Gitignore":     gitignoreQuery,
-			"URL":           fmt.Sprintf("https://%s/%s/%s", config.Host, config.Owner, config.Name),
-			"GoPackagePath": fmt.Sprintf("%s/%s/%s", config.Host, config.Owner, config.Name),
+			"URL":           config.URL(),
+			"GoPackagePath": config.GoPackagePath(),
+			"RepoRoot":      config.RepoRoot,
+			"SubPath":       config.SubPath(),
 		},
 		"Values":  values,
 		"License": config.License,
@@ -232,14 +496,62 @@ func (p *Plan) makeTemplateValues(config *Config, skeleton *kickoff.Skeleton) er
 	return nil
 }
 
-func makeSources(config *Config) []*kickoff.BufferedFile {
+// validateValues validates values against the values schemas of all composed
+// skeletons. All violations are collected and returned as ValuesErrors.
+func validateValues(values template.Values, schemas []*kickoff.ValuesSchema) error {
+	var valuesErrs ValuesErrors
+
+	for _, schema := range schemas {
+		violations, err := schema.Validate(values)
+		if err != nil {
+			return fmt.Errorf("failed to validate template values: %w", err)
+		}
+
+		for _, v := range violations {
+			valuesErrs = append(valuesErrs, &ValuesError{
+				Path:        v.Path,
+				SkeletonRef: schema.SkeletonRef,
+				Err:         errors.New(v.Message),
+			})
+		}
+	}
+
+	if len(valuesErrs) > 0 {
+		return valuesErrs
+	}
+
+	return nil
+}
+
+func makeSources(config *Config) ([]*kickoff.BufferedFile, error) {
 	var extraFiles []*kickoff.BufferedFile
 
-	if config.License != nil {
+	if config.Lock != nil {
+		buf, err := yaml.Marshal(config.Lock)
+		if err != nil {
+			return nil, err
+		}
+
+		extraFiles = append(extraFiles, &kickoff.BufferedFile{
+			RelPath: kickoff.LockFileName,
+			Content: buf,
+			Mode:    0644,
+		})
+	}
+
+	// In monorepo mode the repository already has a license and the gitignore
+	// lines are added to the .gitignore in the repository root, see
+	// (*Plan).makeRootGitignoreOperation.
+	if config.License != nil && config.RepoRoot == "" {
+		year := config.Year
+		if year == 0 {
+			year = time.Now().Year()
+		}
+
 		text := license.ResolvePlaceholders(config.License.Body, license.FieldMap{
 			"project": config.Name,
 			"author":  config.Owner,
-			"year":    strconv.Itoa(time.Now().Year()),
+			"year":    strconv.Itoa(year),
 		})
 
 		extraFiles = append(extraFiles, &kickoff.BufferedFile{
@@ -249,7 +561,7 @@ func makeSources(config *Config) []*kickoff.BufferedFile {
 		})
 	}
 
-	if config.Gitignore != nil {
+	if config.Gitignore != nil && config.RepoRoot == "" {
 		extraFiles = append(extraFiles, &kickoff.BufferedFile{
 			RelPath: ".gitignore",
 			Content: config.Gitignore.Content,
@@ -267,47 +579,175 @@ func makeSources(config *Config) []*kickoff.BufferedFile {
 		return sources[i].RelPath < sources[j].RelPath
 	})
 
-	return sources
+	return sources, nil
 }
 
 func (p *Plan) makeOperations(config *Config) error {
 	p.Operations = make([]*Operation, 0)
 
-	sources := makeSources(config)
+	sources, err := makeSources(config)
+	if err != nil {
+		return err
+	}
+
+	var renderErrs RenderErrors
 
 	for _, source := range sources {
-		dest, err := p.makeDestination(config.ProjectDir, source)
+		err := p.forEachDestination(config.ProjectDir, source, func(dest *Destination, values template.Values) (err error) {
+			reason := p.skipReason(source)
+
+			var opType OpType
+			switch {
+			case reason != "":
+				opType = OpSkipSkeleton
+			case dest.Exists() && !config.Overwrite && !matchPathPrefix(p.overwriteMap, dest.RelPath()):
+				opType = OpSkipExisting
+			case matchPathPrefix(p.skipMap, dest.RelPath()):
+				opType = OpSkipUser
+			case dest.Exists():
+				opType = OpOverwrite
+			}
+
+			op := &Operation{
+				Type:   opType,
+				Source: source,
+				Dest:   dest,
+				Reason: reason,
+				values: values,
+			}
+
+			op.DestState, err = destState(dest)
+			if err != nil {
+				return err
+			}
+
+			// Files that are skipped are rendered as well so that all errors
+			// are reported at once instead of one by one once these files are
+			// written. Only files excluded by a file rule are not rendered as
+			// their templates may depend on the values the rule checks for.
+			if opType != OpSkipSkeleton {
+				content, err := p.render(source, values)
+				if err != nil {
+					renderErrs = append(renderErrs, newRenderError(source.RelPath, source.SkeletonRef, err))
+				} else if op.writesFile() {
+					op.Content = content
+				}
+			}
+
+			p.Operations = append(p.Operations, op)
+			p.OpCounts[opType]++
+
+			return nil
+		})
+		if err := collectRenderErrors(&renderErrs, err); err != nil {
+			return err
+		}
+	}
+
+	if len(renderErrs) > 0 {
+		return renderErrs
+	}
+
+	return p.makeRootGitignoreOperation(config)
+}
+
+// makeRootGitignoreOperation adds an operation that appends the lines of the
+// gitignore template which are not present yet to the .gitignore in the
+// repository root. Does nothing outside of monorepo mode or if there are no
+// new lines.
+func (p *Plan) makeRootGitignoreOperation(config *Config) error {
+	if config.RepoRoot == "" || config.Gitignore == nil {
+		return nil
+	}
+
+	relPath, err := filepath.Rel(config.ProjectDir, filepath.Join(config.RepoRoot, ".gitignore"))
+	if err != nil {
+		return err
+	}
+
+	op := &Operation{
+		Type: OpCreate,
+		Source: &kickoff.BufferedFile{
+			RelPath: ".gitignore",
+			Content: config.Gitignore.Content,
+			Mode:    0644,
+		},
+		Dest:    &Destination{Base: config.ProjectDir, Path: relPath},
+		Content: config.Gitignore.Content,
+
+		sharedGitignore: true,
+	}
+
+	if op.Dest.Exists() {
+		existing, err := os.ReadFile(op.Dest.AbsPath())
 		if err != nil {
 			return err
 		}
 
-		var opType OpType
-		switch {
-		case dest.Exists() && !config.Overwrite && !matchPathPrefix(p.overwriteMap, dest.RelPath()):
-			opType = OpSkipExisting
-		case matchPathPrefix(p.skipMap, dest.RelPath()):
-			opType = OpSkipUser
-		case dest.Exists():
-			opType = OpOverwrite
+		lines := uniqueLines(existing, config.Gitignore.Content)
+		if len(lines) == 0 {
+			return nil
 		}
 
-		p.Operations = append(p.Operations, &Operation{
-			Type:   opType,
-			Source: source,
-			Dest:   dest,
-		})
-		p.OpCounts[opType]++
+		op.Type = OpMerge
+		op.Content = joinContent(existing, lines)
 	}
 
+	op.DestState, err = destState(op.Dest)
+	if err != nil {
+		return err
+	}
+
+	p.Operations = append(p.Operations, op)
+	p.OpCounts[op.Type]++
+
 	return nil
 }
 
-func (p *Plan) makeDestination(targetDir string, f *kickoff.BufferedFile) (*Destination, error) {
+// CombineSharedGitignores combines the operations of plans that add lines to
+// the same .gitignore in a repository root into the operation of the first
+// plan and removes them from the other plans. This is required if the plans
+// are applied concurrently, as they would otherwise overwrite each other's
+// lines and restore outdated content on rollback. Note that the lines of all
+// plans are only written if the first plan is applied successfully.
+func CombineSharedGitignores(plans ...*Plan) {
+	owners := make(map[string]*Operation)
+
+	for _, p := range plans {
+		ops := p.Operations[:0]
+
+		for _, op := range p.Operations {
+			if !op.sharedGitignore {
+				ops = append(ops, op)
+				continue
+			}
+
+			path := op.Dest.AbsPath()
+
+			owner, ok := owners[path]
+			if !ok {
+				owners[path] = op
+				ops = append(ops, op)
+				continue
+			}
+
+			if lines := uniqueLines(owner.Content, op.Source.Content); len(lines) > 0 {
+				owner.Content = joinContent(owner.Content, lines)
+			}
+
+			p.OpCounts[op.Type]--
+		}
+
+		p.Operations = ops
+	}
+}
+
+func (p *Plan) makeDestination(targetDir string, f *kickoff.BufferedFile, values template.Values) (*Destination, error) {
 	relPath := f.RelPath
 	srcFilename := filepath.Base(relPath)
 	srcRelDir := filepath.Dir(relPath)
 
-	targetFilename, err := template.Render(srcFilename, p.values)
+	targetFilename, err := template.Render(srcFilename, values)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve templated filename %q: %w", srcFilename, err)
 	}
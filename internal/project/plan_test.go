@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/martinohmann/kickoff/internal/gitignore"
+	"github.com/martinohmann/kickoff/internal/jsonschema"
+	"github.com/martinohmann/kickoff/internal/kickoff"
 	"github.com/martinohmann/kickoff/internal/license"
 	"github.com/martinohmann/kickoff/internal/repository"
 	"github.com/martinohmann/kickoff/internal/template"
@@ -75,14 +77,14 @@ func TestCreate(t *testing.T) {
 			config: &Config{
 				Values: template.Values{"filename": "../../"},
 			},
-			expectedErr: errors.New(`templated filename "{{.Values.filename}}" injected illegal directory traversal: ../../`),
+			expectedErr: errors.New(`failed to render template {{.Values.filename}} (skeleton advanced): templated filename "{{.Values.filename}}" injected illegal directory traversal: ../../`),
 		},
 		{
 			name: "rendering empty filename fails",
 			config: &Config{
 				Values: template.Values{"filename": ""},
 			},
-			expectedErr: errors.New(`templated filename "{{.Values.filename}}" resolved to an empty string`),
+			expectedErr: errors.New(`failed to render template {{.Values.filename}} (skeleton advanced): templated filename "{{.Values.filename}}" resolved to an empty string`),
 		},
 		{
 			name: "does not overwrite existing files",
@@ -154,14 +156,18 @@ func TestCreate(t *testing.T) {
 			config: &Config{
 				Values: template.Values{"travis": "invalid"},
 			},
-			expectedErr: errors.New(`failed to render template: template: :4:13: executing "" at <.Values.travis.enabled>: can't evaluate field enabled in type interface {}`),
+			expectedErr: errors.New(`failed to render template README.md.skel:4:14 (skeleton advanced): executing "" at <.Values.travis.enabled>: can't evaluate field enabled in type interface {}`),
+			validate: func(t *dirTester) {
+				t.assertFileAbsent("README.md")
+				t.assertFileAbsent("foobar")
+			},
 		},
 		{
 			name: "errors while resolving templated filenames are returned",
 			config: &Config{
 				Values: template.Values{"filename": func() {}},
 			},
-			expectedErr: errors.New(`failed to resolve templated filename "{{.Values.filename}}": failed to render template: template: :1:2: executing "" at <{{.Values.filename}}>: can't print {{.Values.filename}} of type func()`),
+			expectedErr: errors.New(`failed to render template {{.Values.filename}} (skeleton advanced): failed to resolve templated filename "{{.Values.filename}}": failed to render template: template: :1:2: executing "" at <{{.Values.filename}}>: can't print {{.Values.filename}} of type func()`),
 		},
 	}
 
@@ -184,7 +190,7 @@ func TestCreate(t *testing.T) {
 			test.config.Skeleton = skeleton
 			test.config.ProjectDir = tmpdir
 
-			err = Create(test.config)
+			err = Create(context.Background(), test.config)
 			if test.expectedErr != nil {
 				require.Error(t, err)
 				assert.EqualError(t, err, test.expectedErr.Error())
@@ -199,6 +205,53 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestCreate_Monorepo(t *testing.T) {
+	repoRoot := filepath.Join(t.TempDir(), "platform")
+	projectDir := filepath.Join(repoRoot, "services", "myservice")
+
+	root := &dirTester{T: t, dir: repoRoot}
+	root.mustWriteFile(".gitignore", "node_modules\n")
+
+	config := &Config{
+		Name:       "myservice",
+		Host:       "github.com",
+		Owner:      "acme",
+		ProjectDir: projectDir,
+		RepoRoot:   repoRoot,
+		Gitignore:  &gitignore.Template{Content: []byte("node_modules\n*.log\n")},
+		License:    &license.Info{Name: "MIT License", Body: "some license"},
+		Skeleton: &kickoff.Skeleton{
+			Files: []*kickoff.BufferedFile{
+				{
+					RelPath: "README.md.skel",
+					Content: []byte("{{.Project.URL}} {{.Project.GoPackagePath}} {{.Project.SubPath}} {{.Project.License}}"),
+					Mode:    0644,
+				},
+			},
+		},
+	}
+
+	plan, err := MakePlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.Operations, 2)
+	assert.Equal(t, OpMerge, plan.Operations[1].Type)
+	assert.Equal(t, filepath.Join("..", "..", ".gitignore"), plan.Operations[1].Dest.RelPath())
+
+	require.NoError(t, plan.Apply(context.Background()))
+
+	root.assertFileContains(".gitignore", "node_modules\n*.log\n")
+	root.assertFileContains("services/myservice/README.md",
+		"https://github.com/acme/platform github.com/acme/platform/services/myservice services/myservice MIT License")
+	root.assertFileAbsent("services/myservice/LICENSE")
+	root.assertFileAbsent("services/myservice/.gitignore")
+
+	// All gitignore lines are present now, so the root .gitignore is not
+	// touched again.
+	plan, err = MakePlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.Operations, 1)
+}
+
 type dirTester struct {
 	*testing.T
 	dir string
@@ -229,6 +282,10 @@ func (t *dirTester) assertFileAbsent(file string) {
 	assert.True(t, os.IsNotExist(err))
 }
 
+func (t *dirTester) mustRemoveFile(file string) {
+	require.NoError(t, os.Remove(t.path(file)))
+}
+
 func (t *dirTester) mustWriteFile(file, content string) {
 	path := t.path(file)
 
@@ -238,3 +295,96 @@ func (t *dirTester) mustWriteFile(file, content string) {
 	err = os.WriteFile(path, []byte(content), 0644)
 	require.NoError(t, err)
 }
+
+func TestMakePlan_RenderErrors(t *testing.T) {
+	ref := &kickoff.SkeletonRef{Name: "default", Repo: &kickoff.RepoRef{Name: "repo"}}
+
+	dir := t.TempDir()
+
+	// Existing files are skipped, but errors are reported nonetheless.
+	tester := &dirTester{T: t, dir: dir}
+	tester.mustWriteFile("c.txt", "existing")
+
+	_, err := MakePlan(&Config{
+		Name:       "myproject",
+		ProjectDir: dir,
+		Skeleton: &kickoff.Skeleton{
+			Files: []*kickoff.BufferedFile{
+				{RelPath: "a.txt.skel", Content: []byte("{{.Project.Name}}"), Mode: 0644, SkeletonRef: ref},
+				{RelPath: "b.txt.skel", Content: []byte("line1\n{{ .Values.missing }}"), Mode: 0644, SkeletonRef: ref},
+				{RelPath: "c.txt.skel", Content: []byte("{{ invalid }}"), Mode: 0644, SkeletonRef: ref},
+				{RelPath: "{{.Values.missing}}.txt", Content: []byte("content"), Mode: 0644, SkeletonRef: ref},
+			},
+		},
+	})
+	require.Error(t, err)
+
+	var renderErrs RenderErrors
+	require.True(t, errors.As(err, &renderErrs))
+	require.Len(t, renderErrs, 3)
+
+	assert.Equal(t, "b.txt.skel", renderErrs[0].Path)
+	assert.Equal(t, ref, renderErrs[0].SkeletonRef)
+	assert.Equal(t, 2, renderErrs[0].Line)
+	assert.Equal(t, 11, renderErrs[0].Column)
+
+	assert.Equal(t, "c.txt.skel", renderErrs[1].Path)
+	assert.Equal(t, 1, renderErrs[1].Line)
+	assert.Equal(t, 0, renderErrs[1].Column)
+
+	assert.Equal(t, "{{.Values.missing}}.txt", renderErrs[2].Path)
+	assert.Equal(t, ref, renderErrs[2].SkeletonRef)
+
+	assert.Contains(t, err.Error(), `failed to render 3 templates:
+  b.txt.skel:2:11 (skeleton repo:default): executing "" at <.Values.missing>: map has no entry for key "missing"
+  c.txt.skel:1 (skeleton repo:default): function "invalid" not defined
+  {{.Values.missing}}.txt (skeleton repo:default): failed to resolve templated filename "{{.Values.missing}}.txt"`)
+}
+
+func TestMakePlan_ValuesErrors(t *testing.T) {
+	ref := &kickoff.SkeletonRef{Name: "default", Repo: &kickoff.RepoRef{Name: "repo"}}
+	otherRef := &kickoff.SkeletonRef{Name: "other", Repo: &kickoff.RepoRef{Name: "repo"}}
+
+	schema, err := jsonschema.Compile([]byte(`{
+  "type": "object",
+  "required": ["image"],
+  "properties": {"replicas": {"type": "integer", "minimum": 1}}
+}`))
+	require.NoError(t, err)
+
+	otherSchema, err := jsonschema.Compile([]byte(`{"properties": {"replicas": {"maximum": 3}}}`))
+	require.NoError(t, err)
+
+	config := &Config{
+		Name:       "myproject",
+		ProjectDir: t.TempDir(),
+		Values:     template.Values{"replicas": 0.5},
+		Skeleton: &kickoff.Skeleton{
+			Values: template.Values{"replicas": 1},
+			ValuesSchemas: []*kickoff.ValuesSchema{
+				{Schema: schema, SkeletonRef: ref},
+				{Schema: otherSchema, SkeletonRef: otherRef},
+			},
+		},
+	}
+
+	_, err = MakePlan(config)
+	require.Error(t, err)
+
+	var valuesErrs ValuesErrors
+	require.True(t, errors.As(err, &valuesErrs))
+
+	assert.EqualError(t, err, `found 2 invalid template values:
+  $.image (skeleton repo:default): required value is missing
+  $.replicas (skeleton repo:default): expected integer, got number`)
+
+	config.Values = template.Values{"image": "nginx", "replicas": 5}
+
+	_, err = MakePlan(config)
+	assert.EqualError(t, err, `invalid template value $.replicas (skeleton repo:other): must be <= 3, got 5`)
+
+	config.Values = template.Values{"image": "nginx"}
+
+	_, err = MakePlan(config)
+	assert.NoError(t, err)
+}
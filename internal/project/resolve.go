@@ -0,0 +1,68 @@
+package project
+
+// Existing returns the operations whose destination is an existing file,
+// i.e. all non-directory operations of type OpSkipExisting or OpOverwrite.
+// Their type can be changed via Keep and Overwrite before the plan is
+// applied.
+func (p *Plan) Existing() []*Operation {
+	var ops []*Operation
+
+	for _, op := range p.Operations {
+		if op.Source.Mode.IsDir() {
+			continue
+		}
+
+		if op.Type == OpSkipExisting || op.Type == OpOverwrite {
+			ops = append(ops, op)
+		}
+	}
+
+	return ops
+}
+
+// NewContent returns the content op writes to its destination if it is
+// overwritten. Unlike (*Operation).ReadContent this also renders the content
+// of operations that keep the existing destination.
+func (p *Plan) NewContent(op *Operation) ([]byte, error) {
+	if op.Type == OpSkipExisting {
+		content, err := p.render(op.Source, op.values)
+		if err != nil || content != nil {
+			return content, err
+		}
+	}
+
+	return op.ReadContent()
+}
+
+// Keep changes op to keep its existing destination.
+func (p *Plan) Keep(op *Operation) {
+	p.setType(op, OpSkipExisting)
+	op.Content = nil
+}
+
+// Overwrite changes op to overwrite its existing destination with content.
+// If content is nil, the content of the skeleton file is written.
+func (p *Plan) Overwrite(op *Operation, content []byte) error {
+	if content == nil && op.Type == OpSkipExisting {
+		var err error
+
+		content, err = p.render(op.Source, op.values)
+		if err != nil {
+			return newRenderError(op.Source.RelPath, op.Source.SkeletonRef, err)
+		}
+	}
+
+	if content != nil {
+		op.Content = content
+	}
+
+	p.setType(op, OpOverwrite)
+
+	return nil
+}
+
+func (p *Plan) setType(op *Operation, opType OpType) {
+	p.OpCounts[op.Type]--
+	op.Type = opType
+	p.OpCounts[opType]++
+}
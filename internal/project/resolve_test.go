@@ -0,0 +1,64 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan_Resolve(t *testing.T) {
+	ref := &kickoff.SkeletonRef{Name: "default", Repo: &kickoff.RepoRef{Name: "repo"}}
+	projectDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "README.md"), []byte("old readme"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "Makefile"), []byte("old makefile"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(projectDir, "ci"), 0755))
+
+	plan, err := MakePlan(&Config{
+		Name:       "myproject",
+		ProjectDir: projectDir,
+		Values:     template.Values{"target": "all"},
+		Skeleton: &kickoff.Skeleton{
+			Files: []*kickoff.BufferedFile{
+				{RelPath: "README.md.skel", Content: []byte("# {{.Project.Name}}"), Mode: 0644, SkeletonRef: ref},
+				{RelPath: "Makefile.skel", Content: []byte("{{.Values.target}}:"), Mode: 0644, SkeletonRef: ref},
+				{RelPath: "ci", Mode: 0755 | os.ModeDir, SkeletonRef: ref},
+				{RelPath: "main.go", Content: []byte("package main"), Mode: 0644, SkeletonRef: ref},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	existing := plan.Existing()
+	require.Len(t, existing, 2)
+	assert.Equal(t, "Makefile", existing[0].Dest.RelPath())
+	assert.Equal(t, "README.md", existing[1].Dest.RelPath())
+
+	content, err := plan.NewContent(existing[1])
+	require.NoError(t, err)
+	assert.Equal(t, "# myproject", string(content))
+
+	require.NoError(t, plan.Overwrite(existing[0], []byte("merged:")))
+	require.NoError(t, plan.Overwrite(existing[1], nil))
+
+	assert.Equal(t, OpOverwrite, existing[0].Type)
+	assert.Equal(t, OpOverwrite, existing[1].Type)
+	assert.Equal(t, 2, plan.OpCounts[OpOverwrite])
+	// The existing ci directory is always skipped.
+	assert.Equal(t, 1, plan.OpCounts[OpSkipExisting])
+
+	content, err = existing[0].ReadContent()
+	require.NoError(t, err)
+	assert.Equal(t, "merged:", string(content))
+
+	plan.Keep(existing[0])
+
+	assert.Equal(t, OpSkipExisting, existing[0].Type)
+	assert.Equal(t, 1, plan.OpCounts[OpOverwrite])
+	assert.Equal(t, 2, plan.OpCounts[OpSkipExisting])
+}
@@ -0,0 +1,169 @@
+package project
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/template"
+)
+
+// fileRule is a file rule of the skeleton whose expressions were already
+// evaluated.
+type fileRule struct {
+	*kickoff.FileRule
+	include bool
+	items   []interface{}
+}
+
+// evalFileRules evaluates the conditions and lists of all file rules against
+// the plan's template values.
+func (p *Plan) evalFileRules(rules []*kickoff.FileRule) RenderErrors {
+	var renderErrs RenderErrors
+
+	// The index of a rule within the rule list of the skeleton that defined
+	// it is only needed for error reporting.
+	indexes := make(map[*kickoff.SkeletonRef]int)
+
+	for _, rule := range rules {
+		index := indexes[rule.SkeletonRef]
+		indexes[rule.SkeletonRef]++
+
+		r := &fileRule{FileRule: rule, include: true}
+
+		var err error
+
+		if rule.If != "" {
+			r.include, err = template.EvaluateCondition(rule.If, p.values)
+			if err != nil {
+				path := fmt.Sprintf("files[%d].if", index)
+				renderErrs = append(renderErrs, newRenderError(path, rule.SkeletonRef, err))
+				continue
+			}
+		}
+
+		if rule.ForEach != "" {
+			r.items, err = evalList(rule.ForEach, p.values)
+			if err != nil {
+				path := fmt.Sprintf("files[%d].forEach", index)
+				renderErrs = append(renderErrs, newRenderError(path, rule.SkeletonRef, err))
+				continue
+			}
+		}
+
+		p.fileRules = append(p.fileRules, r)
+	}
+
+	return renderErrs
+}
+
+// evalList evaluates the template expression expr which must yield a list
+// or nil.
+func evalList(expr string, values template.Values) ([]interface{}, error) {
+	expr = strings.TrimSpace(expr)
+	expr = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(expr, "{{"), "}}"))
+
+	result, err := template.Evaluate(expr, values)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	v := reflect.ValueOf(result)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expression must evaluate to a list, got %T", result)
+	}
+
+	items := make([]interface{}, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+
+	return items, nil
+}
+
+// sourceValues returns the template values for each destination source is
+// rendered to. If source matches a file rule with a forEach expression, the
+// values contain the list item as `.Item`. Otherwise the plan's values are
+// returned as the only element.
+func (p *Plan) sourceValues(source *kickoff.BufferedFile) []template.Values {
+	if source.Mode.IsDir() {
+		return []template.Values{p.values}
+	}
+
+	for _, rule := range p.fileRules {
+		if rule.ForEach == "" || !rule.MatchPath(source.RelPath) {
+			continue
+		}
+
+		valuesList := make([]template.Values, 0, len(rule.items))
+
+		for _, item := range rule.items {
+			values := make(template.Values, len(p.values)+1)
+			for k, v := range p.values {
+				values[k] = v
+			}
+
+			values["Item"] = item
+
+			valuesList = append(valuesList, values)
+		}
+
+		return valuesList
+	}
+
+	return []template.Values{p.values}
+}
+
+// forEachDestination calls fn with the destination and template values for
+// every destination source is rendered to. Returns an error if source
+// renders to the same destination more than once. Destinations whose filename
+// fails to render are skipped and reported as RenderErrors after fn was called
+// for all other destinations.
+func (p *Plan) forEachDestination(targetDir string, source *kickoff.BufferedFile, fn func(*Destination, template.Values) error) error {
+	seen := make(map[string]bool)
+
+	var renderErrs RenderErrors
+
+	for _, values := range p.sourceValues(source) {
+		dest, err := p.makeDestination(targetDir, source, values)
+		if err != nil {
+			renderErrs = append(renderErrs, &RenderError{Path: source.RelPath, SkeletonRef: source.SkeletonRef, Err: err})
+			continue
+		}
+
+		if seen[dest.RelPath()] {
+			return fmt.Errorf("multiple items of forEach render %s to the same destination %s", source.RelPath, dest.RelPath())
+		}
+
+		seen[dest.RelPath()] = true
+
+		if err := fn(dest, values); err != nil {
+			return err
+		}
+	}
+
+	if len(renderErrs) > 0 {
+		return renderErrs
+	}
+
+	return nil
+}
+
+// skipReason returns a non-empty reason if source is excluded by a file rule
+// whose condition evaluated to false.
+func (p *Plan) skipReason(source *kickoff.BufferedFile) string {
+	for _, rule := range p.fileRules {
+		if rule.include || !rule.MatchPath(source.RelPath) {
+			continue
+		}
+
+		return fmt.Sprintf("condition %q is false", strings.TrimSpace(rule.If))
+	}
+
+	return ""
+}
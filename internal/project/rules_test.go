@@ -0,0 +1,158 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakePlan_FileRules(t *testing.T) {
+	ref := &kickoff.SkeletonRef{Name: "default", Repo: &kickoff.RepoRef{Name: "repo"}}
+
+	makeConfig := func(values template.Values, rules ...*kickoff.FileRule) *Config {
+		for _, rule := range rules {
+			rule.SkeletonRef = ref
+		}
+
+		return &Config{
+			Name:       "myproject",
+			ProjectDir: t.TempDir(),
+			Values:     values,
+			Skeleton: &kickoff.Skeleton{
+				Files: []*kickoff.BufferedFile{
+					{RelPath: "Dockerfile.skel", Content: []byte("FROM {{.Values.image}}"), Mode: 0644, SkeletonRef: ref},
+					{RelPath: "README.md", Content: []byte("readme"), Mode: 0644, SkeletonRef: ref},
+					{RelPath: "ci", Mode: 0755 | os.ModeDir, SkeletonRef: ref},
+					{RelPath: "ci/build.yaml", Content: []byte("build"), Mode: 0644, SkeletonRef: ref},
+				},
+				FileRules: rules,
+			},
+		}
+	}
+
+	opTypes := func(plan *Plan) map[string]OpType {
+		types := make(map[string]OpType)
+		for _, op := range plan.Operations {
+			types[op.Source.RelPath] = op.Type
+		}
+		return types
+	}
+
+	t.Run("skips files whose condition is false", func(t *testing.T) {
+		plan, err := MakePlan(makeConfig(
+			template.Values{"docker": false, "ci": false},
+			&kickoff.FileRule{Path: "Dockerfile", If: ".Values.docker"},
+			&kickoff.FileRule{Path: "ci", If: "{{ .Values.ci }}"},
+		))
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]OpType{
+			"Dockerfile.skel": OpSkipSkeleton,
+			"README.md":       OpCreate,
+			"ci":              OpSkipSkeleton,
+			"ci/build.yaml":   OpSkipSkeleton,
+		}, opTypes(plan))
+		assert.Equal(t, 3, plan.OpCounts[OpSkipSkeleton])
+		assert.Equal(t, `condition ".Values.docker" is false`, plan.Operations[0].Reason)
+	})
+
+	t.Run("includes files whose condition is true", func(t *testing.T) {
+		plan, err := MakePlan(makeConfig(
+			template.Values{"docker": true, "image": "alpine"},
+			&kickoff.FileRule{Path: "Dockerfile", If: ".Values.docker"},
+			&kickoff.FileRule{Path: "*.yaml", If: `eq .Values.image "alpine"`},
+		))
+		require.NoError(t, err)
+
+		for path, opType := range opTypes(plan) {
+			assert.Equal(t, OpCreate, opType, path)
+		}
+	})
+
+	t.Run("condition errors are reported", func(t *testing.T) {
+		_, err := MakePlan(makeConfig(
+			nil,
+			&kickoff.FileRule{Path: "Dockerfile", If: ".Values.docker"},
+			&kickoff.FileRule{Path: "README.md", If: "{{ .Project.Name }}"},
+		))
+		require.Error(t, err)
+
+		var renderErrs RenderErrors
+		require.True(t, errors.As(err, &renderErrs))
+		require.Len(t, renderErrs, 2)
+
+		assert.Equal(t, "files[0].if", renderErrs[0].Path)
+		assert.Equal(t, ref, renderErrs[0].SkeletonRef)
+		assert.EqualError(t, renderErrs[1], `files[1].if (skeleton repo:default): expression must evaluate to true or false, got "myproject"`)
+	})
+}
+
+func TestMakePlan_ForEach(t *testing.T) {
+	ref := &kickoff.SkeletonRef{Name: "default", Repo: &kickoff.RepoRef{Name: "repo"}}
+
+	makeConfig := func(dir string, values template.Values, rule *kickoff.FileRule) *Config {
+		rule.SkeletonRef = ref
+
+		return &Config{
+			Name:       "myproject",
+			ProjectDir: dir,
+			Values:     values,
+			Skeleton: &kickoff.Skeleton{
+				Files: []*kickoff.BufferedFile{
+					{RelPath: "handlers", Mode: 0755 | os.ModeDir, SkeletonRef: ref},
+					{RelPath: "handlers/{{.Item.name}}.go.skel", Content: []byte("// {{.Item.name}} of {{.Project.Name}}: {{.Item.path}}\n"), Mode: 0644, SkeletonRef: ref},
+				},
+				FileRules: []*kickoff.FileRule{rule},
+			},
+		}
+	}
+
+	endpoints := []interface{}{
+		map[string]interface{}{"name": "users", "path": "/users"},
+		map[string]interface{}{"name": "orders", "path": "/orders"},
+	}
+
+	t.Run("renders one file per item", func(t *testing.T) {
+		dir := t.TempDir()
+
+		plan, err := MakePlan(makeConfig(dir, template.Values{"endpoints": endpoints},
+			&kickoff.FileRule{Path: "handlers/*.go", ForEach: ".Values.endpoints"}))
+		require.NoError(t, err)
+		require.Len(t, plan.Operations, 3)
+		assert.Equal(t, 3, plan.OpCounts[OpCreate])
+
+		require.NoError(t, plan.Apply(context.Background()))
+
+		tester := &dirTester{T: t, dir: dir}
+		tester.assertFileContains("handlers/users.go", "// users of myproject: /users\n")
+		tester.assertFileContains("handlers/orders.go", "// orders of myproject: /orders\n")
+	})
+
+	t.Run("empty lists render no files", func(t *testing.T) {
+		plan, err := MakePlan(makeConfig(t.TempDir(), template.Values{"endpoints": []interface{}{}},
+			&kickoff.FileRule{Path: "handlers/*.go", ForEach: "{{ .Values.endpoints }}"}))
+		require.NoError(t, err)
+		require.Len(t, plan.Operations, 1)
+		assert.Equal(t, "handlers", plan.Operations[0].Dest.RelPath())
+	})
+
+	t.Run("duplicate destinations cause an error", func(t *testing.T) {
+		duplicates := []interface{}{endpoints[0], endpoints[0]}
+
+		_, err := MakePlan(makeConfig(t.TempDir(), template.Values{"endpoints": duplicates},
+			&kickoff.FileRule{Path: "handlers", ForEach: ".Values.endpoints"}))
+		require.EqualError(t, err, "multiple items of forEach render handlers/{{.Item.name}}.go.skel to the same destination handlers/users.go")
+	})
+
+	t.Run("non-list values cause an error", func(t *testing.T) {
+		_, err := MakePlan(makeConfig(t.TempDir(), template.Values{"endpoints": "users"},
+			&kickoff.FileRule{Path: "handlers/*.go", ForEach: ".Values.endpoints"}))
+		require.EqualError(t, err, "failed to render template files[0].forEach (skeleton repo:default): expression must evaluate to a list, got string")
+	})
+}
@@ -0,0 +1,268 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"os"
+
+	"github.com/martinohmann/kickoff/internal/diff"
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/template"
+)
+
+var mergeLabels = diff.Labels{Ours: "current", Theirs: "skeleton"}
+
+// MakeUpdatePlan creates a plan for updating the existing project in
+// config.ProjectDir with the files rendered from config. The base config must
+// describe the skeletons and values the project was originally created from.
+// The files rendered from base serve as the common ancestor of a three-way
+// merge between the files currently present in the project directory and the
+// files rendered from config.
+func MakeUpdatePlan(config, base *Config) (*Plan, error) {
+	baseContents, err := renderContents(base)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := newPlan(config)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Operations = make([]*Operation, 0)
+
+	sources, err := makeSources(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var renderErrs RenderErrors
+
+	for _, source := range sources {
+		err := p.forEachDestination(config.ProjectDir, source, func(dest *Destination, values template.Values) (err error) {
+			var content []byte
+
+			if !source.Mode.IsDir() && p.skipReason(source) == "" {
+				content, err = p.render(source, values)
+				if err != nil {
+					renderErrs = append(renderErrs, newRenderError(source.RelPath, source.SkeletonRef, err))
+					return nil
+				}
+
+				if content == nil {
+					content, err = source.ReadContent()
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			op, err := p.makeUpdateOperation(source, dest, content, baseContents)
+			if err != nil {
+				return err
+			}
+
+			op.DestState, err = destState(dest)
+			if err != nil {
+				return err
+			}
+
+			p.Operations = append(p.Operations, op)
+			p.OpCounts[op.Type]++
+
+			return nil
+		})
+		if err := collectRenderErrors(&renderErrs, err); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(renderErrs) > 0 {
+		return nil, renderErrs
+	}
+
+	return p, nil
+}
+
+// Update is a convenience wrapper to make an update plan and immediately
+// apply it.
+func Update(ctx context.Context, config, base *Config) error {
+	plan, err := MakeUpdatePlan(config, base)
+	if err != nil {
+		return err
+	}
+
+	return plan.Apply(ctx)
+}
+
+func (p *Plan) makeUpdateOperation(source *kickoff.BufferedFile, dest *Destination, content []byte, baseContents map[string][]byte) (*Operation, error) {
+	op := &Operation{Source: source, Dest: dest}
+
+	if reason := p.skipReason(source); reason != "" {
+		op.Type = OpSkipSkeleton
+		op.Reason = reason
+		return op, nil
+	}
+
+	if matchPathPrefix(p.skipMap, dest.RelPath()) {
+		op.Type = OpSkipUser
+		return op, nil
+	}
+
+	if source.Mode.IsDir() {
+		if dest.Exists() {
+			op.Type = OpSkipExisting
+		}
+
+		return op, nil
+	}
+
+	base, inBase := baseContents[dest.RelPath()]
+
+	if source.IsSymlink() {
+		return makeUpdateLinkOperation(op, content, base, inBase), nil
+	}
+
+	if source.Binary {
+		return makeUpdateBinaryOperation(op, content, base, inBase)
+	}
+
+	current, err := os.ReadFile(dest.AbsPath())
+	if os.IsNotExist(err) {
+		if inBase {
+			// The file was part of the project once, so the user
+			// deliberately removed it. Do not bring it back.
+			op.Type = OpSkipUser
+		} else {
+			op.Content = content
+		}
+
+		return op, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	result := diff.Merge(string(base), string(current), string(content), mergeLabels)
+
+	if bytes.Equal(current, []byte(result.Text)) {
+		op.Type = OpSkipExisting
+		return op, nil
+	}
+
+	op.Type = OpMerge
+	op.Content = []byte(result.Text)
+	op.Conflicts = result.Conflicts
+
+	return op, nil
+}
+
+// makeUpdateLinkOperation finishes op for a symlink. The link is only
+// changed if the user did not touch it since it was created from base.
+func makeUpdateLinkOperation(op *Operation, target, base []byte, inBase bool) *Operation {
+	current, err := os.Readlink(op.Dest.AbsPath())
+
+	switch {
+	case err == nil && current == string(target):
+		op.Type = OpSkipExisting
+	case err == nil && inBase && current == string(base):
+		op.Type = OpMerge
+		op.Content = target
+	case os.IsNotExist(err) && !inBase:
+		op.Content = target
+	case os.IsNotExist(err):
+		// The link was part of the project once, so the user
+		// deliberately removed it. Do not bring it back.
+		op.Type = OpSkipUser
+	default:
+		// The user changed the link target or replaced the link with
+		// something else.
+		op.Type = OpSkipExisting
+	}
+
+	return op
+}
+
+// makeUpdateBinaryOperation finishes op for a binary file. Binary files cannot
+// be merged, so the file is only replaced if the user did not change it since
+// it was created from base.
+func makeUpdateBinaryOperation(op *Operation, content, base []byte, inBase bool) (*Operation, error) {
+	current, err := os.ReadFile(op.Dest.AbsPath())
+	if os.IsNotExist(err) {
+		if inBase {
+			// The file was part of the project once, so the user
+			// deliberately removed it. Do not bring it back.
+			op.Type = OpSkipUser
+		} else {
+			op.Content = content
+		}
+
+		return op, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case bytes.Equal(current, content):
+		op.Type = OpSkipExisting
+	case inBase && bytes.Equal(current, base):
+		op.Type = OpMerge
+		op.Content = content
+	default:
+		// The user changed the file.
+		op.Type = OpSkipExisting
+	}
+
+	return op, nil
+}
+
+// renderContents renders all files described by config and returns a map of
+// destination paths relative to the project dir to rendered contents.
+func renderContents(config *Config) (map[string][]byte, error) {
+	p, err := newPlan(config)
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := makeSources(config)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make(map[string][]byte, len(sources))
+
+	var renderErrs RenderErrors
+
+	for _, source := range sources {
+		err := p.forEachDestination(config.ProjectDir, source, func(dest *Destination, values template.Values) error {
+			if source.Mode.IsDir() || p.skipReason(source) != "" {
+				return nil
+			}
+
+			content, err := p.render(source, values)
+			if err != nil {
+				renderErrs = append(renderErrs, newRenderError(source.RelPath, source.SkeletonRef, err))
+				return nil
+			}
+
+			if content == nil {
+				content, err = source.ReadContent()
+				if err != nil {
+					return err
+				}
+			}
+
+			contents[dest.RelPath()] = content
+
+			return nil
+		})
+		if err := collectRenderErrors(&renderErrs, err); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(renderErrs) > 0 {
+		return nil, renderErrs
+	}
+
+	return contents, nil
+}
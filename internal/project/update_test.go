@@ -0,0 +1,149 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdate(t *testing.T) {
+	ref := &kickoff.SkeletonRef{Name: "default"}
+
+	base := &kickoff.Skeleton{
+		Ref: ref,
+		Files: []*kickoff.BufferedFile{
+			{RelPath: "README.md.skel", Content: []byte("# {{.Project.Name}}\n\nsome text\n"), Mode: 0644, SkeletonRef: ref},
+			{RelPath: "conflict.txt", Content: []byte("a\nb\nc\n"), Mode: 0644, SkeletonRef: ref},
+			{RelPath: "deleted.txt", Content: []byte("deleted"), Mode: 0644, SkeletonRef: ref},
+			{RelPath: "unchanged.txt", Content: []byte("unchanged\n"), Mode: 0644, SkeletonRef: ref},
+			{RelPath: "untouched.txt", Content: []byte("old\n"), Mode: 0644, SkeletonRef: ref},
+		},
+	}
+
+	skeleton := &kickoff.Skeleton{
+		Ref: ref,
+		Files: []*kickoff.BufferedFile{
+			{RelPath: "README.md.skel", Content: []byte("# {{.Project.Name}}\n\nsome text\n\n{{.Values.footer}}\n"), Mode: 0644, SkeletonRef: ref},
+			{RelPath: "conflict.txt", Content: []byte("a\nskeleton\nc\n"), Mode: 0644, SkeletonRef: ref},
+			{RelPath: "deleted.txt", Content: []byte("deleted but changed"), Mode: 0644, SkeletonRef: ref},
+			{RelPath: "new.txt", Content: []byte("new\n"), Mode: 0644, SkeletonRef: ref},
+			{RelPath: "unchanged.txt", Content: []byte("unchanged\n"), Mode: 0644, SkeletonRef: ref},
+			{RelPath: "untouched.txt", Content: []byte("new\n"), Mode: 0644, SkeletonRef: ref},
+		},
+	}
+
+	dir := t.TempDir()
+	tester := &dirTester{T: t, dir: dir}
+
+	require.NoError(t, Create(context.Background(), &Config{Name: "myproject", ProjectDir: dir, Skeleton: base}))
+
+	tester.mustWriteFile("README.md", "# myproject (changed by the user)\n\nsome text\n")
+	tester.mustWriteFile("conflict.txt", "a\ncurrent\nc\n")
+	tester.mustWriteFile("unchanged.txt", "changed by the user\n")
+	tester.mustRemoveFile("deleted.txt")
+
+	plan, err := MakeUpdatePlan(
+		&Config{
+			Name:       "myproject",
+			ProjectDir: dir,
+			Skeleton:   skeleton,
+			Values:     template.Values{"footer": "the footer"},
+		},
+		&Config{
+			Name:       "myproject",
+			ProjectDir: dir,
+			Skeleton:   base,
+		},
+	)
+	require.NoError(t, err)
+
+	opTypes := make(map[string]OpType)
+	for _, op := range plan.Operations {
+		opTypes[op.Dest.RelPath()] = op.Type
+	}
+
+	expectedOpTypes := map[string]OpType{
+		"README.md":     OpMerge,
+		"conflict.txt":  OpMerge,
+		"deleted.txt":   OpSkipUser,
+		"new.txt":       OpCreate,
+		"unchanged.txt": OpSkipExisting,
+		"untouched.txt": OpMerge,
+	}
+
+	assert.Equal(t, expectedOpTypes, opTypes)
+
+	conflicts := plan.Conflicts()
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "conflict.txt", conflicts[0].Dest.RelPath())
+
+	require.NoError(t, plan.Apply(context.Background()))
+
+	tester.assertFileContains("README.md", "# myproject (changed by the user)\n\nsome text\n\nthe footer\n")
+	tester.assertFileContains("conflict.txt", "a\n<<<<<<< current\ncurrent\n=======\nskeleton\n>>>>>>> skeleton\nc\n")
+	tester.assertFileAbsent("deleted.txt")
+	tester.assertFileContains("new.txt", "new\n")
+	tester.assertFileContains("unchanged.txt", "changed by the user\n")
+	tester.assertFileContains("untouched.txt", "new\n")
+}
+
+func TestUpdate_Symlinks(t *testing.T) {
+	link := func(relPath, target string) *kickoff.BufferedFile {
+		return &kickoff.BufferedFile{RelPath: relPath, Mode: 0777 | os.ModeSymlink, LinkTarget: target}
+	}
+
+	base := &kickoff.Skeleton{
+		Files: []*kickoff.BufferedFile{
+			link("changed", "a"),
+			link("retargeted", "a"),
+			link("unchanged", "a"),
+		},
+	}
+
+	skeleton := &kickoff.Skeleton{
+		Files: []*kickoff.BufferedFile{
+			link("changed", "b"),
+			link("new", "b"),
+			link("retargeted", "b"),
+			link("unchanged", "a"),
+		},
+	}
+
+	dir := t.TempDir()
+
+	require.NoError(t, Create(context.Background(), &Config{Name: "myproject", ProjectDir: dir, Skeleton: base}))
+	require.NoError(t, os.Remove(filepath.Join(dir, "retargeted")))
+	require.NoError(t, os.Symlink("c", filepath.Join(dir, "retargeted")))
+
+	plan, err := MakeUpdatePlan(
+		&Config{Name: "myproject", ProjectDir: dir, Skeleton: skeleton},
+		&Config{Name: "myproject", ProjectDir: dir, Skeleton: base},
+	)
+	require.NoError(t, err)
+
+	types := make(map[string]OpType)
+	for _, op := range plan.Operations {
+		types[op.Dest.RelPath()] = op.Type
+	}
+
+	assert.Equal(t, map[string]OpType{
+		"changed":    OpMerge,
+		"new":        OpCreate,
+		"retargeted": OpSkipExisting,
+		"unchanged":  OpSkipExisting,
+	}, types)
+
+	require.NoError(t, plan.Apply(context.Background()))
+
+	for path, expected := range map[string]string{"changed": "b", "new": "b", "retargeted": "c", "unchanged": "a"} {
+		target, err := os.Readlink(filepath.Join(dir, path))
+		require.NoError(t, err)
+		assert.Equal(t, expected, target, path)
+	}
+}
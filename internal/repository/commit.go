@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/martinohmann/kickoff/internal/git"
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	log "github.com/sirupsen/logrus"
+)
+
+var defaultGitClient = git.NewClient()
+
+// ResolveCommit returns the hash of the commit the repository referenced by
+// ref is currently at. For remote repositories this is the commit of the
+// local cache. Returns an empty string if the repository is not a git
+// repository or if it does not have any commits yet.
+func ResolveCommit(ref kickoff.RepoRef) (string, error) {
+	repo, err := defaultGitClient.Open(ref.LocalPath())
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	return head.Hash().String(), nil
+}
+
+// OpenCommit opens the repository referenced by ref at the given commit.
+// Remote repositories are fetched into a separate cache dir with the commit
+// checked out. For local repositories the contents of the commit are exported
+// into a cache dir, leaving the worktree of the local repository untouched.
+func OpenCommit(ctx context.Context, ref kickoff.RepoRef, commit string, opts *Options) (kickoff.Repository, error) {
+	if commit == "" {
+		return nil, fmt.Errorf("cannot open repository %q without commit", ref.String())
+	}
+
+	if ref.IsRemote() {
+		ref.Revision = commit
+		return OpenRef(ctx, ref, opts)
+	}
+
+	if err := ref.Validate(); err != nil {
+		return nil, err
+	}
+
+	localPath := ref.LocalPath()
+	key := fmt.Sprintf("%s@%s", localPath, commit)
+	snapshotPath := filepath.Join(kickoff.LocalRepositoryCacheDir, fmt.Sprintf("%x", sha256.Sum256([]byte(key))))
+
+	if _, err := os.Stat(snapshotPath); os.IsNotExist(err) {
+		if err := exportCommit(localPath, commit, snapshotPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return newRepository(kickoff.RepoRef{Name: ref.Name, Path: snapshotPath}, opts.maxFileSize())
+}
+
+func exportCommit(repoPath, commit, path string) error {
+	repo, err := defaultGitClient.Open(repoPath)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"path":   repoPath,
+		"commit": commit,
+	}).Debug("exporting commit")
+
+	tmpPath := path + ".tmp"
+
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(tmpPath, 0755); err != nil {
+		return err
+	}
+
+	if err := repo.Export(plumbing.NewHash(commit), tmpPath); err != nil {
+		os.RemoveAll(tmpPath)
+		return fmt.Errorf("failed to export commit %s of repository %s: %w", commit, repoPath, err)
+	}
+
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveCommit(t *testing.T) {
+	t.Run("returns empty string for non-git repositories", func(t *testing.T) {
+		commit, err := ResolveCommit(kickoff.RepoRef{Path: "../testdata/repos/repo1"})
+		require.NoError(t, err)
+		assert.Empty(t, commit)
+	})
+
+	t.Run("returns empty string for repositories without commits", func(t *testing.T) {
+		dir := t.TempDir()
+
+		_, err := git.PlainInit(dir, false)
+		require.NoError(t, err)
+
+		commit, err := ResolveCommit(kickoff.RepoRef{Path: dir})
+		require.NoError(t, err)
+		assert.Empty(t, commit)
+	})
+
+	t.Run("returns HEAD commit", func(t *testing.T) {
+		dir, repo := testutil.InitGitRepo(t)
+
+		expected := testutil.CommitFile(t, repo, dir, "skeletons/default/.kickoff.yaml", "")
+
+		commit, err := ResolveCommit(kickoff.RepoRef{Path: dir})
+		require.NoError(t, err)
+		assert.Equal(t, expected, commit)
+	})
+}
+
+func TestOpenCommit(t *testing.T) {
+	defer testutil.MockRepositoryCacheDir(t.TempDir())()
+
+	dir, repo := testutil.InitGitRepo(t)
+
+	testutil.CommitFile(t, repo, dir, "skeletons/default/.kickoff.yaml", "")
+	oldCommit := testutil.CommitFile(t, repo, dir, "skeletons/default/README.md", "old")
+	testutil.CommitFile(t, repo, dir, "skeletons/default/README.md", "new")
+
+	t.Run("opens local repository at commit", func(t *testing.T) {
+		r, err := OpenCommit(context.Background(), kickoff.RepoRef{Name: "local", Path: dir}, oldCommit, nil)
+		require.NoError(t, err)
+
+		skeleton, err := r.LoadSkeleton("default")
+		require.NoError(t, err)
+
+		require.Len(t, skeleton.Files, 1)
+
+		buf, err := skeleton.Files[0].ReadContent()
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(buf))
+		assert.Equal(t, "local", skeleton.Ref.Repo.Name)
+
+		// worktree is untouched
+		content, err := os.ReadFile(filepath.Join(dir, "skeletons/default/README.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(content))
+	})
+
+	t.Run("fetches remote repository at commit", func(t *testing.T) {
+		var fetched kickoff.RepoRef
+
+		_, err := OpenCommit(context.Background(), kickoff.RepoRef{URL: "https://foo.bar/baz/qux"}, oldCommit, &Options{
+			Fetcher: &fakeFetcher{fn: func(ref kickoff.RepoRef) error {
+				fetched = ref
+				return os.MkdirAll(ref.SkeletonsPath(), 0755)
+			}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, oldCommit, fetched.Revision)
+	})
+
+	t.Run("empty commit causes error", func(t *testing.T) {
+		_, err := OpenCommit(context.Background(), kickoff.RepoRef{Path: dir}, "", nil)
+		require.Error(t, err)
+	})
+}
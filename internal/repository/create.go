@@ -35,7 +35,7 @@ func Create(path string) (kickoff.Repository, error) {
 		return nil, fmt.Errorf("failed to create repository in %s: %w", localPath, err)
 	}
 
-	return newRepository(*ref)
+	return newRepository(*ref, kickoff.DefaultMaxFileSize)
 }
 
 func createSkeleton(ref kickoff.RepoRef, name string) error {
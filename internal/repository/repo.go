@@ -2,18 +2,39 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
 	"github.com/martinohmann/kickoff/internal/kickoff"
+	"github.com/martinohmann/kickoff/internal/version"
 )
 
+// kickoffVersion is the version that the requirements of skeletons and
+// repositories are checked against.
+var kickoffVersion = version.Get().GitVersion
+
 // Options for opening a repository.
 type Options struct {
 	// Fetcher is used to fetch remote repositories. If nil a default git
 	// fetcher will be used.
 	Fetcher RemoteFetcher
+	// MaxFileSize is the maximum size in bytes of skeleton files that need to
+	// be loaded into memory, e.g. templates. If zero,
+	// kickoff.DefaultMaxFileSize is used.
+	MaxFileSize int64
+}
+
+func (o *Options) maxFileSize() int64 {
+	if o == nil || o.MaxFileSize == 0 {
+		return kickoff.DefaultMaxFileSize
+	}
+
+	return o.MaxFileSize
 }
 
 // Open opens a repository at url. Returns an error if url is not a valid local
@@ -57,18 +78,19 @@ func OpenRef(ctx context.Context, ref kickoff.RepoRef, opts *Options) (kickoff.R
 		}
 	}
 
-	return newRepository(ref)
+	return newRepository(ref, opts.maxFileSize())
 }
 
 // repository is a local skeleton repository. A local skeleton repository
 // can be any directory on disk that contains a skeletons/ subdirectory.
 type repository struct {
-	ref kickoff.RepoRef
+	ref         kickoff.RepoRef
+	maxFileSize int64
 }
 
 // newRepository creates a kickoff.Repository from ref. Returns an error if
 // resolving the absolute path to the skeleton repository fails.
-func newRepository(ref kickoff.RepoRef) (kickoff.Repository, error) {
+func newRepository(ref kickoff.RepoRef, maxFileSize int64) (kickoff.Repository, error) {
 	dir := ref.SkeletonsPath()
 
 	fi, err := os.Stat(dir)
@@ -76,7 +98,7 @@ func newRepository(ref kickoff.RepoRef) (kickoff.Repository, error) {
 		return nil, InvalidSkeletonRepositoryError{RepoRef: ref}
 	}
 
-	return &repository{ref: ref}, nil
+	return &repository{ref: ref, maxFileSize: maxFileSize}, nil
 }
 
 func (r *repository) GetSkeleton(name string) (*kickoff.SkeletonRef, error) {
@@ -105,7 +127,7 @@ func (r *repository) ListSkeletons() ([]*kickoff.SkeletonRef, error) {
 }
 
 func (r *repository) LoadSkeleton(name string) (*kickoff.Skeleton, error) {
-	return loadSkeleton(r, name)
+	return loadSkeleton(r, name, r.maxFileSize)
 }
 
 func (r *repository) CreateSkeleton(name string) (*kickoff.SkeletonRef, error) {
@@ -176,24 +198,107 @@ func isSkeletonDir(dir string) bool {
 	return fi.Mode().IsRegular()
 }
 
-// LoadSkeletons loads multiple skeletons from given repository. Returns an
-// error if loading of any of the skeletons fails.
+// LoadSkeletons loads multiple skeletons from given repository including the
+// skeletons they extend. The result is in composition order: the skeletons a
+// skeleton extends are resolved recursively and precede it, skeletons that
+// occur multiple times are only included the first time. Returns an error if
+// loading of any of the skeletons fails or if the skeletons extend each other
+// in a cycle.
 func LoadSkeletons(repo kickoff.Repository, names []string) ([]*kickoff.Skeleton, error) {
-	skeletons := make([]*kickoff.Skeleton, len(names))
+	l := &skeletonLoader{
+		repo:    repo,
+		loaded:  make(map[string]bool),
+		loading: make(map[string]bool),
+	}
 
-	for i, name := range names {
-		skeleton, err := repo.LoadSkeleton(name)
-		if err != nil {
+	for _, name := range names {
+		if err := l.load(name); err != nil {
 			return nil, err
 		}
+	}
+
+	return l.skeletons, nil
+}
+
+// skeletonLoader resolves the skeletons that skeletons extend.
+type skeletonLoader struct {
+	repo      kickoff.Repository
+	skeletons []*kickoff.Skeleton
+	// loaded and loading are keyed by skeleton path.
+	loaded  map[string]bool
+	loading map[string]bool
+	// chain holds the refs of the skeletons that are currently being loaded
+	// for reporting cycles.
+	chain []*kickoff.SkeletonRef
+}
+
+func (l *skeletonLoader) load(name string) error {
+	ref, err := l.repo.GetSkeleton(name)
+	if err != nil {
+		return err
+	}
 
-		skeletons[i] = skeleton
+	if l.loaded[ref.Path] {
+		return nil
+	}
+
+	l.chain = append(l.chain, ref)
+	defer func() { l.chain = l.chain[:len(l.chain)-1] }()
+
+	if l.loading[ref.Path] {
+		return newInheritanceCycleError(l.chain, ref)
+	}
+
+	l.loading[ref.Path] = true
+
+	skeleton, err := l.repo.LoadSkeleton(name)
+	if err != nil {
+		return err
 	}
 
-	return skeletons, nil
+	for _, parent := range skeleton.Extends {
+		if err := l.load(qualifyName(parent, ref)); err != nil {
+			return err
+		}
+	}
+
+	l.loaded[ref.Path] = true
+	l.skeletons = append(l.skeletons, skeleton)
+
+	return nil
 }
 
-func loadSkeleton(repo kickoff.Repository, name string) (*kickoff.Skeleton, error) {
+// qualifyName prefixes name with the name of the repository of ref if it does
+// not reference a repository yet, so that skeletons are looked up in the same
+// repository as the skeleton that extends them.
+func qualifyName(name string, ref *kickoff.SkeletonRef) string {
+	repoName, _ := splitName(name)
+	if repoName != "" || ref.Repo == nil || ref.Repo.Name == "" {
+		return name
+	}
+
+	return ref.Repo.Name + ":" + name
+}
+
+func newInheritanceCycleError(chain []*kickoff.SkeletonRef, ref *kickoff.SkeletonRef) error {
+	var start int
+
+	for i, r := range chain {
+		if r.Path == ref.Path {
+			start = i
+			break
+		}
+	}
+
+	names := make([]string, 0, len(chain)-start)
+	for _, r := range chain[start:] {
+		names = append(names, r.String())
+	}
+
+	return fmt.Errorf("skeleton %s extends itself: %s", ref, strings.Join(names, " -> "))
+}
+
+func loadSkeleton(repo kickoff.Repository, name string, maxFileSize int64) (*kickoff.Skeleton, error) {
 	ref, err := repo.GetSkeleton(name)
 	if err != nil {
 		return nil, err
@@ -204,23 +309,121 @@ func loadSkeleton(repo kickoff.Repository, name string) (*kickoff.Skeleton, erro
 		return nil, err
 	}
 
-	files, err := loadSkeletonFiles(ref)
+	if err := checkRequirements(ref, config); err != nil {
+		return nil, err
+	}
+
+	schema, err := ref.LoadValuesSchema()
 	if err != nil {
 		return nil, err
 	}
 
+	patterns, err := loadIgnorePatterns(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	files, ignored, err := loadSkeletonFiles(ref, patterns, maxFileSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range config.Files {
+		rule.SkeletonRef = ref
+	}
+
+	for _, param := range config.Parameters {
+		param.SkeletonRef = ref
+	}
+
 	s := &kickoff.Skeleton{
-		Description: config.Description,
-		Values:      config.Values,
-		Ref:         ref,
-		Files:       files,
+		Description:  config.Description,
+		Extends:      config.Extends,
+		Values:       config.Values,
+		Hooks:        kickoff.NewHooks(config.Hooks, ref),
+		FileRules:    config.Files,
+		Parameters:   config.Parameters,
+		Ref:          ref,
+		Files:        files,
+		IgnoredFiles: ignored,
+		MaxFileSize:  maxFileSize,
+	}
+
+	if schema != nil {
+		s.ValuesSchemas = []*kickoff.ValuesSchema{schema}
 	}
 
 	return s, nil
 }
 
-func loadSkeletonFiles(ref *kickoff.SkeletonRef) ([]*kickoff.BufferedFile, error) {
+// CheckRequirements checks the requirements of the skeleton referenced by ref
+// and of its repository against the version of kickoff. Returns a
+// *kickoff.VersionMismatchError if either of them requires a different
+// version.
+func CheckRequirements(ref *kickoff.SkeletonRef) error {
+	config, err := ref.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	return checkRequirements(ref, config)
+}
+
+func checkRequirements(ref *kickoff.SkeletonRef, config *kickoff.SkeletonConfig) error {
+	repoConfig, err := ref.Repo.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	if err := repoConfig.Requires.Check(kickoffVersion); err != nil {
+		return fmt.Errorf("repository of skeleton %s: %w", ref, err)
+	}
+
+	if err := config.Requires.Check(kickoffVersion); err != nil {
+		return fmt.Errorf("skeleton %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+// loadIgnorePatterns loads the patterns from the .kickoffignore files of the
+// repository and the skeleton referenced by ref. The patterns of the skeleton
+// come last so that they can negate patterns of the repository.
+func loadIgnorePatterns(ref *kickoff.SkeletonRef) ([]gitignore.Pattern, error) {
+	var patterns []gitignore.Pattern
+
+	for _, dir := range []string{ref.Repo.LocalPath(), ref.Path} {
+		buf, err := os.ReadFile(filepath.Join(dir, kickoff.IgnoreFileName))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, line := range strings.Split(string(buf), "\n") {
+			line = strings.TrimSuffix(line, "\r")
+
+			if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+				continue
+			}
+
+			patterns = append(patterns, gitignore.ParsePattern(line, nil))
+		}
+	}
+
+	return patterns, nil
+}
+
+// loadSkeletonFiles loads the files of the skeleton referenced by ref. The
+// content of regular files is not buffered but read from disk on demand.
+// Templates and patch files larger than maxFileSize are rejected as they need
+// to be loaded into memory for rendering. Files and directories matching any of the
+// ignore patterns are skipped and their paths are returned separately.
+func loadSkeletonFiles(ref *kickoff.SkeletonRef, patterns []gitignore.Pattern, maxFileSize int64) ([]*kickoff.BufferedFile, []string, error) {
 	files := make([]*kickoff.BufferedFile, 0)
+	matcher := gitignore.NewMatcher(patterns)
+
+	var ignored []string
 
 	err := filepath.Walk(ref.Path, func(path string, fi os.FileInfo, err error) error {
 		if err != nil {
@@ -237,8 +440,19 @@ func loadSkeletonFiles(ref *kickoff.SkeletonRef) ([]*kickoff.BufferedFile, error
 			return err
 		}
 
-		if relPath == "." {
-			// ignore skeleton dir itself
+		if relPath == "." || relPath == kickoff.ValuesSchemaFileName || relPath == kickoff.IgnoreFileName {
+			// ignore skeleton dir itself, the values schema and the ignore
+			// file
+			return nil
+		}
+
+		if matcher.Match(strings.Split(filepath.ToSlash(relPath), "/"), fi.IsDir()) {
+			if fi.IsDir() {
+				ignored = append(ignored, relPath+string(filepath.Separator))
+				return filepath.SkipDir
+			}
+
+			ignored = append(ignored, relPath)
 			return nil
 		}
 
@@ -256,30 +470,72 @@ func loadSkeletonFiles(ref *kickoff.SkeletonRef) ([]*kickoff.BufferedFile, error
 			return nil
 		}
 
-		if !fi.Mode().IsRegular() {
-			return fmt.Errorf("%s is not a regular file", absPath)
+		if fi.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(absPath)
+			if err != nil {
+				return err
+			}
+
+			if err := kickoff.ValidateLinkTarget(relPath, target); err != nil {
+				return fmt.Errorf("invalid symlink %s: %w", absPath, err)
+			}
+
+			files = append(files, &kickoff.BufferedFile{
+				RelPath:     relPath,
+				Mode:        fi.Mode(),
+				LinkTarget:  target,
+				SkeletonRef: ref,
+			})
+			return nil
 		}
 
-		if fi.Size() > 100*1024*1024 {
-			return fmt.Errorf("file %s too large: refusing to load files larger than 100 MiB", absPath)
+		if !fi.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", absPath)
 		}
 
-		buf, err := os.ReadFile(absPath)
+		binary, err := isBinaryFile(absPath)
 		if err != nil {
 			return err
 		}
 
+		isTemplate := !binary && filepath.Ext(relPath) == kickoff.SkeletonTemplateExtension
+
+		if isTemplate && fi.Size() > maxFileSize {
+			return fmt.Errorf("template %s too large: refusing to load templates larger than %d bytes", absPath, maxFileSize)
+		}
+
+		isPatch := !binary && filepath.Ext(strings.TrimSuffix(relPath, kickoff.SkeletonTemplateExtension)) == kickoff.PatchExtension
+
+		if isPatch && fi.Size() > maxFileSize {
+			return fmt.Errorf("patch %s too large: refusing to load patches larger than %d bytes", absPath, maxFileSize)
+		}
+
 		files = append(files, &kickoff.BufferedFile{
-			RelPath:     relPath,
-			Content:     buf,
+			RelPath: relPath,
+			Opener: func() (io.ReadCloser, error) {
+				return os.Open(absPath)
+			},
+			Size:        fi.Size(),
+			Binary:      binary,
 			Mode:        fi.Mode(),
 			SkeletonRef: ref,
 		})
 		return nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	return files, ignored, nil
+}
+
+// isBinaryFile returns true if the file at path has binary content.
+func isBinaryFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
 	}
+	defer f.Close()
 
-	return files, nil
+	return kickoff.IsBinary(f)
 }
@@ -22,8 +22,9 @@ func OpenMap(ctx context.Context, repoURLMap map[string]string, opts *Options) (
 // repositoryMap is a repository that aggregates multiple repositories and
 // implements the kickoff.Repository interface.
 type repositoryMap struct {
-	repoNames []string
-	repoMap   map[string]kickoff.Repository
+	repoNames   []string
+	repoMap     map[string]kickoff.Repository
+	maxFileSize int64
 }
 
 func newRepositoryMap(ctx context.Context, repoURLMap map[string]string, opts *Options) (*repositoryMap, error) {
@@ -32,8 +33,9 @@ func newRepositoryMap(ctx context.Context, repoURLMap map[string]string, opts *O
 	}
 
 	r := &repositoryMap{
-		repoNames: make([]string, 0, len(repoURLMap)),
-		repoMap:   make(map[string]kickoff.Repository, len(repoURLMap)),
+		repoNames:   make([]string, 0, len(repoURLMap)),
+		repoMap:     make(map[string]kickoff.Repository, len(repoURLMap)),
+		maxFileSize: opts.maxFileSize(),
 	}
 
 	for name, url := range repoURLMap {
@@ -101,7 +103,7 @@ func (r *repositoryMap) ListSkeletons() ([]*kickoff.SkeletonRef, error) {
 }
 
 func (r *repositoryMap) LoadSkeleton(name string) (*kickoff.Skeleton, error) {
-	return loadSkeleton(r, name)
+	return loadSkeleton(r, name, r.maxFileSize)
 }
 
 func (r *repositoryMap) CreateSkeleton(name string) (*kickoff.SkeletonRef, error) {
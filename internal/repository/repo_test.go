@@ -86,7 +86,7 @@ func TestRepository_GetSkeleton(t *testing.T) {
 
 	ref := kickoff.RepoRef{Name: "the-repo", Path: "../testdata/repos/repo1"}
 
-	repo, err := newRepository(ref)
+	repo, err := newRepository(ref, kickoff.DefaultMaxFileSize)
 	require.NoError(t, err)
 
 	abspath, err := filepath.Abs("../testdata/repos/repo1")
@@ -111,7 +111,7 @@ func TestRepository_ListSkeletons(t *testing.T) {
 	t.Run("can list all skeletons", func(t *testing.T) {
 		ref := kickoff.RepoRef{Path: "../testdata/repos/repo1"}
 
-		repo, err := newRepository(ref)
+		repo, err := newRepository(ref, kickoff.DefaultMaxFileSize)
 		require.NoError(t, err)
 
 		refs, err := repo.ListSkeletons()
@@ -234,3 +234,247 @@ func TestIsSkeletonDir(t *testing.T) {
 	assert.False(t, isSkeletonDir("../testdata/repos/repo1/skeletons/"))
 	assert.False(t, isSkeletonDir(".../testdata/repos/repo1/skeletons/minimal/.kickoff.yaml"))
 }
+
+func TestLoadSkeletons_Symlinks(t *testing.T) {
+	repoDir := t.TempDir()
+	skeletonDir := filepath.Join(repoDir, "skeletons", "links")
+
+	require.NoError(t, os.MkdirAll(skeletonDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, kickoff.SkeletonConfigFileName), []byte("{}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, "README.md"), []byte("readme"), 0644))
+	require.NoError(t, os.Symlink("README.md", filepath.Join(skeletonDir, "AGENTS.md")))
+
+	repo, err := OpenRef(context.Background(), kickoff.RepoRef{Path: repoDir}, nil)
+	require.NoError(t, err)
+
+	skeletons, err := LoadSkeletons(repo, []string{"links"})
+	require.NoError(t, err)
+	require.Len(t, skeletons[0].Files, 2)
+
+	link := skeletons[0].Files[0]
+	assert.Equal(t, "AGENTS.md", link.RelPath)
+	assert.True(t, link.IsSymlink())
+	assert.Equal(t, "README.md", link.LinkTarget)
+
+	require.NoError(t, os.Symlink("../../../outside", filepath.Join(skeletonDir, "escape")))
+
+	_, err = LoadSkeletons(repo, []string{"links"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `symlink target "../../../outside" escapes the root directory`)
+}
+
+func TestLoadSkeletons_LazyContent(t *testing.T) {
+	repoDir := t.TempDir()
+	skeletonDir := filepath.Join(repoDir, "skeletons", "assets")
+
+	require.NoError(t, os.MkdirAll(skeletonDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, kickoff.SkeletonConfigFileName), []byte("{}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, "logo.png"), []byte("\x89PNG\x00\x01\x02"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, "large.txt"), []byte("0123456789abcdef"), 0644))
+
+	repo, err := OpenRef(context.Background(), kickoff.RepoRef{Path: repoDir}, &Options{MaxFileSize: 10})
+	require.NoError(t, err)
+
+	skeletons, err := LoadSkeletons(repo, []string{"assets"})
+	require.NoError(t, err)
+	require.Len(t, skeletons[0].Files, 2)
+
+	large, logo := skeletons[0].Files[0], skeletons[0].Files[1]
+
+	assert.Nil(t, large.Content)
+	assert.False(t, large.Binary)
+	assert.Equal(t, int64(16), large.Size)
+
+	assert.Nil(t, logo.Content)
+	assert.True(t, logo.Binary)
+
+	buf, err := logo.ReadContent()
+	require.NoError(t, err)
+	assert.Equal(t, "\x89PNG\x00\x01\x02", string(buf))
+
+	t.Run("templates larger than the limit are rejected", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, "large.txt.skel"), []byte("0123456789abcdef"), 0644))
+
+		_, err := LoadSkeletons(repo, []string{"assets"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refusing to load templates larger than 10 bytes")
+
+		require.NoError(t, os.Remove(filepath.Join(skeletonDir, "large.txt.skel")))
+	})
+
+	t.Run("patches larger than the limit are rejected", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, "large.txt.patch"), []byte("0123456789abcdef"), 0644))
+
+		_, err := LoadSkeletons(repo, []string{"assets"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refusing to load patches larger than 10 bytes")
+	})
+}
+
+func TestLoadSkeletons_ValuesSchema(t *testing.T) {
+	repoDir := t.TempDir()
+	skeletonDir := filepath.Join(repoDir, "skeletons", "schema")
+	schemaPath := filepath.Join(skeletonDir, kickoff.ValuesSchemaFileName)
+
+	require.NoError(t, os.MkdirAll(skeletonDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, kickoff.SkeletonConfigFileName), []byte("{}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, "README.md"), []byte("readme"), 0644))
+	require.NoError(t, os.WriteFile(schemaPath, []byte(`{"type": "object"}`), 0644))
+
+	repo, err := OpenRef(context.Background(), kickoff.RepoRef{Path: repoDir}, nil)
+	require.NoError(t, err)
+
+	skeletons, err := LoadSkeletons(repo, []string{"schema"})
+	require.NoError(t, err)
+
+	skeleton := skeletons[0]
+
+	require.Len(t, skeleton.Files, 1)
+	assert.Equal(t, "README.md", skeleton.Files[0].RelPath)
+
+	require.Len(t, skeleton.ValuesSchemas, 1)
+	assert.Equal(t, skeleton.Ref, skeleton.ValuesSchemas[0].SkeletonRef)
+	assert.Equal(t, "{\n  \"type\": \"object\"\n}", skeleton.ValuesSchemas[0].String())
+
+	t.Run("invalid schemas are rejected", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(schemaPath, []byte(`{"type": "float"}`), 0644))
+
+		_, err := LoadSkeletons(repo, []string{"schema"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `values.schema.json: invalid JSON schema: #/type: invalid type "float"`)
+	})
+}
+
+func TestLoadSkeletons_Extends(t *testing.T) {
+	writeSkeleton := func(t *testing.T, repoDir, name, config string) {
+		skeletonDir := filepath.Join(repoDir, "skeletons", name)
+		require.NoError(t, os.MkdirAll(skeletonDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(skeletonDir, kickoff.SkeletonConfigFileName), []byte(config), 0644))
+	}
+
+	skeletonNames := func(skeletons []*kickoff.Skeleton) []string {
+		names := make([]string, len(skeletons))
+		for i, skeleton := range skeletons {
+			names[i] = skeleton.Ref.String()
+		}
+
+		return names
+	}
+
+	repo1Dir, repo2Dir := t.TempDir(), t.TempDir()
+
+	writeSkeleton(t, repo1Dir, "base", "{}")
+	writeSkeleton(t, repo1Dir, "go", "extends: [base]")
+	writeSkeleton(t, repo1Dir, "docker", "extends: [base]")
+	writeSkeleton(t, repo1Dir, "cycle-a", "extends: [cycle-b]")
+	writeSkeleton(t, repo1Dir, "cycle-b", "extends: [go, cycle-a]")
+	writeSkeleton(t, repo2Dir, "base", "{}")
+	writeSkeleton(t, repo2Dir, "service", "extends: [repo1:go, base]")
+
+	repo, err := OpenMap(context.Background(), map[string]string{"repo1": repo1Dir, "repo2": repo2Dir}, nil)
+	require.NoError(t, err)
+
+	t.Run("parents are composed first without duplicates", func(t *testing.T) {
+		skeletons, err := LoadSkeletons(repo, []string{"repo1:go", "repo1:docker"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"repo1:base", "repo1:go", "repo1:docker"}, skeletonNames(skeletons))
+	})
+
+	t.Run("unqualified names are resolved within the same repository", func(t *testing.T) {
+		skeletons, err := LoadSkeletons(repo, []string{"service"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"repo1:base", "repo1:go", "repo2:base", "repo2:service"}, skeletonNames(skeletons))
+	})
+
+	t.Run("cycles are detected", func(t *testing.T) {
+		_, err := LoadSkeletons(repo, []string{"cycle-a"})
+		require.EqualError(t, err, "skeleton repo1:cycle-a extends itself: repo1:cycle-a -> repo1:cycle-b -> repo1:cycle-a")
+	})
+
+	t.Run("unknown parents are reported", func(t *testing.T) {
+		writeSkeleton(t, repo1Dir, "broken", "extends: [nonexistent]")
+
+		_, err := LoadSkeletons(repo, []string{"broken"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `skeleton "nonexistent" not found`)
+	})
+}
+
+func TestLoadSkeletons_Requirements(t *testing.T) {
+	defer func(v string) { kickoffVersion = v }(kickoffVersion)
+
+	kickoffVersion = "v0.5.0"
+
+	repoDir := t.TempDir()
+	skeletonDir := filepath.Join(repoDir, "skeletons", "modern")
+	skeletonConfigPath := filepath.Join(skeletonDir, kickoff.SkeletonConfigFileName)
+	repoConfigPath := filepath.Join(repoDir, kickoff.RepositoryConfigFileName)
+
+	require.NoError(t, os.MkdirAll(skeletonDir, 0755))
+	require.NoError(t, os.WriteFile(skeletonConfigPath, []byte("requires:\n  kickoff: '>=0.4.0'\n"), 0644))
+
+	repo, err := OpenRef(context.Background(), kickoff.RepoRef{Name: "repo", Path: repoDir}, nil)
+	require.NoError(t, err)
+
+	_, err = LoadSkeletons(repo, []string{"modern"})
+	require.NoError(t, err)
+
+	t.Run("skeleton constraint", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(skeletonConfigPath, []byte("requires:\n  kickoff: '>=0.6.0'\n"), 0644))
+		defer os.WriteFile(skeletonConfigPath, []byte("{}"), 0644)
+
+		_, err := LoadSkeletons(repo, []string{"modern"})
+		require.EqualError(t, err, "skeleton repo:modern: kickoff >=0.6.0 is required, but this is kickoff v0.5.0, please upgrade kickoff")
+
+		var mismatchErr *kickoff.VersionMismatchError
+		assert.True(t, errors.As(err, &mismatchErr))
+
+		ref, err := repo.GetSkeleton("modern")
+		require.NoError(t, err)
+		assert.True(t, errors.As(CheckRequirements(ref), &mismatchErr))
+	})
+
+	t.Run("repository constraint", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(repoConfigPath, []byte("requires:\n  kickoff: '^1.0.0'\n"), 0644))
+		defer os.Remove(repoConfigPath)
+
+		_, err := LoadSkeletons(repo, []string{"modern"})
+		require.EqualError(t, err, "repository of skeleton repo:modern: kickoff ^1.0.0 is required, but this is kickoff v0.5.0, please upgrade kickoff")
+	})
+}
+
+func TestLoadSkeletons_IgnoreFiles(t *testing.T) {
+	repoDir := t.TempDir()
+	skeletonDir := filepath.Join(repoDir, "skeletons", "ignore")
+
+	for path, content := range map[string]string{
+		kickoff.SkeletonConfigFileName: "{}",
+		kickoff.IgnoreFileName:         "# skeleton notes\n/NOTES.md\ntestdata/\n!keep.swp\n",
+		"README.md.skel":               "readme",
+		"NOTES.md":                     "notes",
+		".README.md.skel.swp":          "swap",
+		"keep.swp":                     "not a swap file",
+		"docs/NOTES.md":                "nested notes",
+		"testdata/fixture.yaml":        "fixture",
+	} {
+		path = filepath.Join(skeletonDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, kickoff.IgnoreFileName), []byte("*.swp\r\n.DS_Store\r\n"), 0644))
+
+	repo, err := OpenRef(context.Background(), kickoff.RepoRef{Path: repoDir}, nil)
+	require.NoError(t, err)
+
+	skeletons, err := LoadSkeletons(repo, []string{"ignore"})
+	require.NoError(t, err)
+
+	var paths []string
+	for _, file := range skeletons[0].Files {
+		paths = append(paths, file.RelPath)
+	}
+
+	assert.Equal(t, []string{"README.md.skel", "docs", "docs/NOTES.md", "keep.swp"}, paths)
+	assert.Equal(t, []string{".README.md.skel.swp", "NOTES.md", "testdata/"}, skeletons[0].IgnoredFiles)
+}
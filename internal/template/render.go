@@ -6,6 +6,9 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"regexp"
+	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/Masterminds/sprig/v3"
@@ -15,7 +18,7 @@ import (
 func Render(templateText string, data interface{}) (string, error) {
 	tpl, err := newTemplate("").Parse(templateText)
 	if err != nil {
-		return "", fmt.Errorf("failed to prepare template: %w", err)
+		return "", fmt.Errorf("failed to prepare template: %w", newError(err))
 	}
 
 	return execute(tpl, data)
@@ -31,6 +34,54 @@ func RenderReader(r io.Reader, data interface{}) (string, error) {
 	return Render(string(buf), data)
 }
 
+// Evaluate evaluates the template expression expr, e.g. `.Values.items`,
+// against data and returns the resulting value.
+func Evaluate(expr string, data interface{}) (interface{}, error) {
+	var result interface{}
+
+	capture := func(v interface{}) string {
+		result = v
+		return ""
+	}
+
+	tpl, err := newTemplate("").
+		Funcs(template.FuncMap{"kickoffCapture": capture}).
+		Parse(fmt.Sprintf("{{ kickoffCapture (%s) }}", expr))
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare template: %w", newError(err))
+	}
+
+	if _, err := execute(tpl, data); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// EvaluateCondition evaluates the template expression expr against data.
+// Expressions may be given with or without surrounding template delimiters.
+// Without delimiters the truthiness rules of the `if` template action apply,
+// otherwise the rendered result must be either "true" or "false".
+func EvaluateCondition(expr string, data interface{}) (bool, error) {
+	if !strings.Contains(expr, "{{") {
+		expr = fmt.Sprintf("{{ if %s }}true{{ else }}false{{ end }}", expr)
+	}
+
+	result, err := Render(expr, data)
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.TrimSpace(result) {
+	case "true":
+		return true, nil
+	case "false", "":
+		return false, nil
+	default:
+		return false, fmt.Errorf("expression must evaluate to true or false, got %q", result)
+	}
+}
+
 func newTemplate(name string) *template.Template {
 	return template.New(name).
 		Option("missingkey=error").
@@ -43,8 +94,56 @@ func execute(tpl *template.Template, data interface{}) (string, error) {
 
 	err := tpl.Execute(&buf, data)
 	if err != nil {
-		return "", fmt.Errorf("failed to render template: %w", err)
+		return "", fmt.Errorf("failed to render template: %w", newError(err))
 	}
 
 	return buf.String(), nil
 }
+
+// errorPositionRegexp matches the position prefix of text/template parse and
+// execution errors, e.g. `template: name:3:5: `. The column is only present
+// for execution errors.
+var errorPositionRegexp = regexp.MustCompile(`^template: [^:]*:(\d+)(?::(\d+))?: `)
+
+// Error is a template parse or execution error. It carries the position of
+// the error within the template text.
+type Error struct {
+	// Line is the 1-based line of the error. Zero if unknown.
+	Line int
+	// Column is the 1-based column of the error. Zero if unknown.
+	Column int
+	// Message is the error message without the position information.
+	Message string
+
+	err error
+}
+
+func newError(err error) *Error {
+	e := &Error{Message: err.Error(), err: err}
+
+	match := errorPositionRegexp.FindStringSubmatch(e.Message)
+	if match == nil {
+		return e
+	}
+
+	e.Message = e.Message[len(match[0]):]
+	e.Line, _ = strconv.Atoi(match[1])
+
+	if match[2] != "" {
+		// text/template reports 0-based byte offsets within the line.
+		col, _ := strconv.Atoi(match[2])
+		e.Column = col + 1
+	}
+
+	return e
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying text/template error.
+func (e *Error) Unwrap() error {
+	return e.err
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRenderReader(t *testing.T) {
@@ -70,3 +71,68 @@ type badReader int
 func (badReader) Read(_ []byte) (int, error) {
 	return 0, errors.New("bad reader")
 }
+
+func TestRender_Error(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected Error
+	}{
+		{
+			name:     "parse error",
+			text:     "line1\n{{ invalid }}",
+			expected: Error{Line: 2, Message: `function "invalid" not defined`},
+		},
+		{
+			name:     "execution error",
+			text:     "line1\nfoo {{ .missing }}",
+			expected: Error{Line: 2, Column: 8, Message: `executing "" at <.missing>: map has no entry for key "missing"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Render(tc.text, Values{})
+			require.Error(t, err)
+
+			var tplErr *Error
+			require.True(t, errors.As(err, &tplErr))
+			assert.Equal(t, tc.expected.Line, tplErr.Line)
+			assert.Equal(t, tc.expected.Column, tplErr.Column)
+			assert.Equal(t, tc.expected.Message, tplErr.Message)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	values := Values{"Values": Values{"items": []interface{}{"a", "b"}, "n": 2}}
+
+	v, err := Evaluate(".Values.items", values)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b"}, v)
+
+	v, err = Evaluate("add .Values.n 1", values)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, v)
+
+	_, err = Evaluate(".Values.missing", values)
+	assert.Error(t, err)
+
+	_, err = Evaluate("{{", values)
+	assert.Error(t, err)
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	values := Values{"Values": Values{"enabled": true, "name": "foo"}}
+
+	ok, err := EvaluateCondition(".Values.enabled", values)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = EvaluateCondition(`{{ eq .Values.name "bar" }}`, values)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = EvaluateCondition("{{ .Values.name }}", values)
+	assert.EqualError(t, err, `expression must evaluate to true or false, got "foo"`)
+}
@@ -2,6 +2,7 @@ package template
 
 import (
 	"os"
+	"strings"
 
 	"github.com/ghodss/yaml"
 	"github.com/imdario/mergo"
@@ -31,6 +32,64 @@ func MergeValues(values ...Values) (Values, error) {
 	return vals, nil
 }
 
+// Lookup returns the value at the dot-separated key path, e.g.
+// `database.port`. The second return value is false if the path does not
+// exist.
+func (v Values) Lookup(path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+
+	var m map[string]interface{} = v
+
+	for _, key := range keys[:len(keys)-1] {
+		var ok bool
+		if m, ok = asMap(m[key]); !ok {
+			return nil, false
+		}
+	}
+
+	value, ok := m[keys[len(keys)-1]]
+
+	return value, ok
+}
+
+// Set sets the value at the dot-separated key path, e.g. `database.port`.
+// Missing intermediate maps are created and non-map values along the path
+// are replaced. Intermediate maps are copied, so maps that v shares with
+// other values are not altered.
+func (v Values) Set(path string, value interface{}) {
+	setValue(v, strings.Split(path, "."), value)
+}
+
+func setValue(m map[string]interface{}, keys []string, value interface{}) {
+	if len(keys) == 1 {
+		m[keys[0]] = value
+		return
+	}
+
+	next := make(map[string]interface{})
+
+	if existing, ok := asMap(m[keys[0]]); ok {
+		for k, v := range existing {
+			next[k] = v
+		}
+	}
+
+	setValue(next, keys[1:], value)
+
+	m[keys[0]] = next
+}
+
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case Values:
+		return m, true
+	default:
+		return nil, false
+	}
+}
+
 // LoadValues loads values from a file.
 func LoadValues(path string) (Values, error) {
 	var values Values
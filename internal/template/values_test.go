@@ -42,6 +42,58 @@ func TestMergeValues(t *testing.T) {
 	assert.Equal(t, true, a["somebool"])
 }
 
+func TestValues_Lookup(t *testing.T) {
+	values := Values{
+		"foo": "bar",
+		"nested": map[string]interface{}{
+			"bar": Values{"baz": 42},
+		},
+	}
+
+	value, ok := values.Lookup("foo")
+	assert.True(t, ok)
+	assert.Equal(t, "bar", value)
+
+	value, ok = values.Lookup("nested.bar.baz")
+	assert.True(t, ok)
+	assert.Equal(t, 42, value)
+
+	_, ok = values.Lookup("nested.qux")
+	assert.False(t, ok)
+
+	_, ok = values.Lookup("foo.bar")
+	assert.False(t, ok)
+}
+
+func TestValues_Set(t *testing.T) {
+	nested := map[string]interface{}{
+		"bar": "baz",
+	}
+
+	values := Values{
+		"foo":    "bar",
+		"nested": nested,
+	}
+
+	values.Set("nested.qux", 42)
+	values.Set("foo.bar", true)
+	values.Set("new.key", "value")
+
+	expected := Values{
+		"foo": map[string]interface{}{"bar": true},
+		"nested": map[string]interface{}{
+			"bar": "baz",
+			"qux": 42,
+		},
+		"new": map[string]interface{}{"key": "value"},
+	}
+
+	assert.Equal(t, expected, values)
+
+	// immutability
+	assert.Equal(t, map[string]interface{}{"bar": "baz"}, nested)
+}
+
 func TestLoadValues(t *testing.T) {
 	values, err := LoadValues("../testdata/values/values.yaml")
 	require.NoError(t, err)
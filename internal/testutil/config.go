@@ -26,6 +26,23 @@ func (b *ConfigFileBuilder) WithProjectOwner(owner string) *ConfigFileBuilder {
 	return b
 }
 
+// WithProjectGit sets the project.git config field.
+func (b *ConfigFileBuilder) WithProjectGit(git kickoff.GitConfig) *ConfigFileBuilder {
+	b.Project.Git = git
+	return b
+}
+
+// WithForge adds the forge config for host to the config.
+func (b *ConfigFileBuilder) WithForge(host string, forge kickoff.ForgeConfig) *ConfigFileBuilder {
+	if b.Forges == nil {
+		b.Forges = make(map[string]kickoff.ForgeConfig)
+	}
+
+	b.Forges[host] = forge
+
+	return b
+}
+
 // WithRepository adds a repository with name and url to the config.
 func (b *ConfigFileBuilder) WithRepository(name, url string) *ConfigFileBuilder {
 	if b.Repositories == nil {
@@ -0,0 +1,46 @@
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// InitGitRepo initializes a git repository in a temp dir that gets cleaned up
+// after the current test. Returns the path to the repository dir and the
+// repository.
+func InitGitRepo(t *testing.T) (string, *git.Repository) {
+	dir := t.TempDir()
+
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	return dir, repo
+}
+
+// CommitFile writes content to path relative to the worktree of repo at dir
+// and commits it. Returns the hash of the new commit.
+func CommitFile(t *testing.T, repo *git.Repository, dir, path, content string) string {
+	absPath := filepath.Join(dir, path)
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(absPath), 0755))
+	require.NoError(t, os.WriteFile(absPath, []byte(content), 0644))
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	_, err = worktree.Add(path)
+	require.NoError(t, err)
+
+	hash, err := worktree.Commit("update "+path, &git.CommitOptions{
+		Author: &object.Signature{Name: "John Doe", Email: "john@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	return hash.String()
+}